@@ -42,6 +42,11 @@
 		].join (", "))) {
 			_register (_target, 5);
 		}
+		for (let _target of document.querySelectorAll ([
+				"main.document-format-snippets pre.snippet",
+		].join (", "))) {
+			_registerButton (_target);
+		}
 	}
 	
 	
@@ -127,11 +132,125 @@
 			});
 	}
 	
+	function _registerButton (_target) {
+		let _source = _target.querySelector ("code");
+		if (_source === null) {
+			_source = _target;
+		}
+		let _button = document.createElement ("button");
+		_button.type = "button";
+		_button.className = "clipboard-button";
+		_button.textContent = "copy";
+		let _timeout = null;
+		let _copied = () => {
+				_button.classList.add ("clipboard-copied");
+				if (_timeout !== null) {
+					window.clearTimeout (_timeout);
+				}
+				_timeout = window.setTimeout (() => {
+						_button.classList.remove ("clipboard-copied");
+						_timeout = null;
+					}, 6000);
+			};
+		let _form = null;
+		_button.addEventListener ("click", (_event) => {
+				_event.stopPropagation ();
+				_event.preventDefault ();
+				if (_form !== null) {
+					_form.remove ();
+					_form = null;
+					return;
+				}
+				let _text = _copyText (_source);
+				let _placeholders = _placeholdersParse (_text);
+				if (_placeholders.length == 0) {
+					_copyStore (_text);
+					_copied ();
+					return;
+				}
+				_form = _placeholdersForm (_placeholders, (_values) => {
+						_copyStore (_placeholdersFill (_text, _values));
+						_copied ();
+						_form.remove ();
+						_form = null;
+					});
+				_target.after (_form);
+				_form.querySelector ("input") .focus ();
+			});
+		_target.insertBefore (_button, _target.firstChild);
+	}
+	
+	
+	
+	
+	// NOTE:  Keep in sync with `SnippetPlaceholderRegex`.
+	const _placeholderRegex = /\{\{[ \t]*([A-Za-z_][A-Za-z0-9_.-]*)[ \t]*(?::([^{}]*))?\}\}/g;
+	
+	function _placeholdersParse (_text) {
+		let _placeholders = [];
+		let _placeholdersByName = {};
+		for (let _match of _text.matchAll (_placeholderRegex)) {
+			let _name = _match[1];
+			let _default = _match[2] || "";
+			if (_name in _placeholdersByName) {
+				if (_placeholdersByName[_name].default == "") {
+					_placeholdersByName[_name].default = _default;
+				}
+				continue;
+			}
+			let _placeholder = { name : _name, default : _default };
+			_placeholders.push (_placeholder);
+			_placeholdersByName[_name] = _placeholder;
+		}
+		return _placeholders;
+	}
+	
+	function _placeholdersFill (_text, _values) {
+		return _text.replace (_placeholderRegex, (_match, _name, _default) => {
+				if (_name in _values) {
+					return _values[_name];
+				}
+				return _default || "";
+			});
+	}
+	
+	function _placeholdersForm (_placeholders, _callback) {
+		let _form = document.createElement ("form");
+		_form.className = "snippet-placeholders";
+		for (let _placeholder of _placeholders) {
+			let _label = document.createElement ("label");
+			let _input = document.createElement ("input");
+			_input.type = "text";
+			_input.name = _placeholder.name;
+			_input.value = _placeholder.default;
+			_input.placeholder = _placeholder.default;
+			_label.append (_placeholder.name, _input);
+			_form.append (_label);
+		}
+		let _submit = document.createElement ("button");
+		_submit.type = "submit";
+		_submit.textContent = "copy";
+		_form.append (_submit);
+		_form.addEventListener ("submit", (_event) => {
+				_event.preventDefault ();
+				let _values = {};
+				for (let _input of _form.querySelectorAll ("input")) {
+					_values[_input.name] = _input.value;
+				}
+				_callback (_values);
+			});
+		return _form;
+	}
+	
 	var _currentTarget = null;
 	var _currentClicks = 0;
 	var _currentTimeout = null;
 	
 	function _copy (_source) {
+		_copyStore (_copyText (_source));
+	}
+	
+	function _copyText (_source) {
 		
 		var _selection = window.getSelection ();
 		_selection.removeAllRanges ();
@@ -143,6 +262,11 @@
 		var _selectionText = _selection.toString ();
 		_selection.removeAllRanges ();
 		
+		return _selectionText;
+	}
+	
+	function _copyStore (_selectionText) {
+		
 		if (true) {
 			var _selectionEncoded = _selectionText;
 			_selectionEncoded = encodeURIComponent (_selectionEncoded);
@@ -45,6 +45,13 @@ var LibraryViewHtml string
 var LibraryViewText string
 
 
+//go:embed templates/tags-index.html
+var TagsIndexHtml string
+
+//go:embed templates/tag-view.html
+var TagViewHtml string
+
+
 //go:embed templates/document-view.html
 var DocumentViewHtml string
 
@@ -72,6 +79,18 @@ var UrlOpenHtml string
 var UrlErrorHtml string
 
 
+//go:embed templates/wiki-view.html
+var WikiViewHtml string
+
+
+//go:embed templates/graph-view.html
+var GraphViewHtml string
+
+
+//go:embed templates/search.html
+var SearchHtml string
+
+
 //go:embed templates/global-partials.html
 var GlobalPartialsHtml string
 
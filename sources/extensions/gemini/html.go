@@ -12,11 +12,28 @@ import "html"
 type HtmlState struct {
 	buffer *bytes.Buffer
 	state string
+	preformatted HtmlPreformattedRenderer
+	preformattedLines []string
+	preformattedAlternative string
 }
 
+type HtmlPreformattedRenderer func (_buffer *bytes.Buffer, _lines []string, _alternative string) ()
 
-func NewHtmlState (_buffer *bytes.Buffer) (*HtmlState) {
-	return & HtmlState { buffer : _buffer }
+
+func NewHtmlState (_buffer *bytes.Buffer, _preformatted HtmlPreformattedRenderer) (*HtmlState) {
+	if _preformatted == nil {
+		_preformatted = htmlPreformattedRender
+	}
+	return & HtmlState { buffer : _buffer, preformatted : _preformatted }
+}
+
+
+func htmlPreformattedRender (_buffer *bytes.Buffer, _lines []string, _alternative string) () {
+	fmt.Fprintf (_buffer, "<pre><code>")
+	for _, _line := range _lines {
+		fmt.Fprintf (_buffer, "%s\n", html.EscapeString (_line))
+	}
+	fmt.Fprintf (_buffer, "</code></pre>\n")
 }
 
 
@@ -29,7 +46,9 @@ func (_state *HtmlState) Flush () {
 		case "links" :
 			fmt.Fprintf (_state.buffer, "</ul>\n")
 		case "code" :
-			fmt.Fprintf (_state.buffer, "</code></pre>\n")
+			_state.preformatted (_state.buffer, _state.preformattedLines, _state.preformattedAlternative)
+			_state.preformattedLines = nil
+			_state.preformattedAlternative = ""
 	}
 	_state.state = ""
 }
@@ -48,7 +67,7 @@ func (_state *HtmlState) push (_new string) () {
 		case "links" :
 			fmt.Fprintf (_state.buffer, "<ul class=\"links\">\n")
 		case "code" :
-			fmt.Fprintf (_state.buffer, "<pre><code>")
+			_state.preformattedLines = make ([]string, 0, 64)
 	}
 	_state.state = _new
 }
@@ -68,11 +87,12 @@ func (l LineLink) Html(_state *HtmlState) {
 func (l LinePreformattingToggle) Html(_state *HtmlState) {
 	_state.Flush ()
 	_state.push ("code")
+	_state.preformattedAlternative = string(l)
 }
 
 func (l LinePreformattedText) Html(_state *HtmlState) {
 	_state.push ("code")
-	fmt.Fprintf(_state.buffer, "%s\n", html.EscapeString(string(l)))
+	_state.preformattedLines = append (_state.preformattedLines, string(l))
 }
 
 func (l LineHeading1) Html(_state *HtmlState) {
@@ -0,0 +1,117 @@
+package zscratchpad
+
+
+import "sort"
+import "strings"
+
+
+
+
+type Diagnostic struct {
+	Library string
+	Path string
+	Error *Error
+}
+
+
+
+
+func DiagnosticNew (_library *Library, _path string, _error *Error) (*Diagnostic) {
+	_diagnostic := & Diagnostic {
+			Path : _path,
+			Error : _error,
+		}
+	if _library != nil {
+		_diagnostic.Library = _library.Identifier
+	}
+	return _diagnostic
+}
+
+
+
+
+func DiagnosticMessage (_diagnostic *Diagnostic) (string) {
+	_messages := make ([]string, 0, 2)
+	if _diagnostic.Error.Message != "" {
+		_messages = append (_messages, _diagnostic.Error.Message)
+	}
+	if _diagnostic.Error.Error != nil {
+		if _message := _diagnostic.Error.Error.Error (); _message != "" {
+			_messages = append (_messages, _message)
+		}
+	}
+	return strings.Join (_messages, "  //  ")
+}
+
+
+func DiagnosticExplanation (_diagnostic *Diagnostic) (string) {
+	switch _diagnostic.Error.Code {
+		
+		case 0xb00f4f21 :
+			return "the entry can't be accessed (perhaps a broken symlink, or missing permissions);"
+		case 0xb0cc4319 :
+			return "the entry is neither a regular file nor a folder (perhaps a socket, pipe or device);"
+		case 0x28422546 :
+			return "the folder can't be listed (perhaps missing permissions);"
+		case 0xcf0e846d :
+			return "the sub-folder can't be listed (perhaps missing permissions), thus its documents are skipped;"
+		case 0xc1e080d9, 0xe18c3be5, 0x483c6b27 :
+			return "the file can't be read (perhaps missing permissions);"
+		case 0xa24965ce :
+			return "the file is not valid UTF-8 text (perhaps a binary file that should be excluded);"
+		case 0x959d1dfa, 0x8fbf590b :
+			return "the file can't be decrypted (perhaps it was encrypted for another identity);"
+		case 0xa55b4a4e, 0x6bce700d, 0x71afeb62 :
+			return "the encryption identity can't be loaded (perhaps missing or invalid);"
+		
+		case 0x811970f0 :
+			return "the header is not terminated by its closing marker line;"
+		case 0xac5961cb, 0x8d4a068d :
+			return "the header contains an empty line;"
+		case 0x9dde97ab, 0xf78a34f6 :
+			return "the TOML header is not valid;"
+		case 0x3c886fe6, 0xbd1db3e0 :
+			return "the YAML header is not valid;"
+		case 0x2a6e422e, 0x389182e2, 0xa6abdc7d, 0x6bcc74f2, 0xbf739f86, 0xf8774ec5 :
+			return "the header specifies the same key more than once;"
+		case 0x31e50aa1 :
+			return "the header specifies an invalid identifier;"
+		case 0x2a5add05 :
+			return "the header specifies an invalid slug;"
+		case 0x32158fbf :
+			return "the header specifies an unsupported format;"
+		case 0x47d22258 :
+			return "the header specifies an invalid tag;"
+		case 0xbe73c4ed, 0x11ac8951 :
+			return "the header specifies an invalid alias;"
+		case 0xfb32c6f0, 0xe5403561, 0x49abcf43, 0x9d458979 :
+			return "the header specifies an invalid timestamp;"
+		case 0xc5ccdc9e, 0xad3e4415, 0xdb0d884b :
+			return "the header specifies an invalid metadata key or value;"
+		
+		case 0xe5e1dd0f :
+			return "the format can't be determined neither from the header nor from the file extension;"
+		case 0x1c58da80 :
+			return "the identifier can't be determined neither from the header nor from the path;"
+		case 0x9c9f9c42 :
+			return "another document already uses the same identifier;"
+		
+		default :
+			return "the document can't be loaded;"
+	}
+}
+
+
+
+
+func DiagnosticsSort (_diagnostics []*Diagnostic) () {
+	sort.SliceStable (_diagnostics, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _diagnostics[_leftIndex]
+			_right := _diagnostics[_rightIndex]
+			if _left.Library != _right.Library {
+				return _left.Library < _right.Library
+			}
+			return _left.Path < _right.Path
+		})
+}
+
@@ -0,0 +1,110 @@
+package zscratchpad
+
+
+import "regexp"
+
+
+
+
+type DocumentGrepLine struct {
+	Document string
+	Line uint
+	Column uint
+	Text string
+	Context bool
+}
+
+
+
+
+// NOTE:  The lines and columns are those of the source file (i.e. taking the header into account), and columns are counted in bytes (as `vim` does).
+// NOTE:  Each line is reported only once, with the column of its first match, followed or preceded by up to `_context` non-matching lines.
+func DocumentGrepLines (_document *Document, _terms []string, _folded bool, _matchAny bool, _context uint) ([]*DocumentGrepLine) {
+	
+	_patterns := make ([]*regexp.Regexp, 0, len (_terms))
+	for _, _term := range _terms {
+		_pattern := regexp.QuoteMeta (_term)
+		if _folded {
+			_pattern = "(?i)" + _pattern
+		}
+		_patterns = append (_patterns, regexp.MustCompile (_pattern))
+	}
+	
+	// NOTE:  A line is reported if it contains any of the terms, however (unless `_matchAny`) the document as a whole must contain all of them.
+	_columns := make ([]uint, len (_document.BodyLines))
+	_found := make ([]bool, len (_patterns))
+	for _index, _line := range _document.BodyLines {
+		_columns[_index] = documentGrepLineColumn (_line, _patterns, _found)
+	}
+	_matched := false
+	for _, _found_0 := range _found {
+		if _found_0 {
+			_matched = true
+		} else if !_matchAny {
+			return nil
+		}
+	}
+	if !_matched {
+		return nil
+	}
+	
+	_lines := make ([]*DocumentGrepLine, 0, 16)
+	_next := 0
+	for _index, _column := range _columns {
+		if _column == 0 {
+			continue
+		}
+		_first := _index - int (_context)
+		if _first < _next {
+			_first = _next
+		}
+		_last := _index + int (_context)
+		if _last >= len (_columns) {
+			_last = len (_columns) - 1
+		}
+		for _contextIndex := _first; _contextIndex <= _last; _contextIndex += 1 {
+			if (_contextIndex > _index) && (_columns[_contextIndex] != 0) {
+				break
+			}
+			_lines = append (_lines, & DocumentGrepLine {
+					Document : _document.Identifier,
+					Line : uint (_document.BodyOffset) + uint (_contextIndex) + 1,
+					Column : _columns[_contextIndex],
+					Text : _document.BodyLines[_contextIndex],
+					Context : _columns[_contextIndex] == 0,
+				})
+			_next = _contextIndex + 1
+		}
+	}
+	
+	return _lines
+}
+
+
+// NOTE:  Returns the line of the first match (or zero if none), to be used when opening the document in an editor.
+func DocumentGrepLineFirst (_document *Document, _terms []string, _folded bool, _matchAny bool) (uint) {
+	for _, _line := range DocumentGrepLines (_document, _terms, _folded, _matchAny, 0) {
+		return _line.Line
+	}
+	return 0
+}
+
+
+// NOTE:  Returns the column of the first term found on the line (or zero if none), and marks the found terms.
+func documentGrepLineColumn (_line string, _patterns []*regexp.Regexp, _found []bool) (uint) {
+	_column := -1
+	for _index, _pattern := range _patterns {
+		_location := _pattern.FindStringIndex (_line)
+		if _location == nil {
+			continue
+		}
+		_found[_index] = true
+		if (_column == -1) || (_location[0] < _column) {
+			_column = _location[0]
+		}
+	}
+	if _column == -1 {
+		return 0
+	}
+	return uint (_column) + 1
+}
@@ -0,0 +1,576 @@
+package zscratchpad
+
+
+import "encoding/json"
+import "strings"
+import "time"
+
+
+import "gopkg.in/yaml.v2"
+
+
+
+
+type documentHeader struct {
+	syntax string
+	prefix string
+	marker string
+	newline string
+	before string
+	lines []string
+	after string
+}
+
+
+
+
+// NOTE:  Rewrites only the header of the given source, replacing all the values of the given key;
+//        if there are no values, the key is removed from the header;
+//        the header syntax (`###`, `## --`, `---`, `+++` or `#+`) is preserved, and the body is left untouched.
+func DocumentHeaderUpdate (_source string, _key string, _values []string, _formatHint string) (string, *Error) {
+	
+	_key = stringTrimSpaces (_key)
+	_key = strings.ToLower (_key)
+	if ! DocumentMetadataKeyRegex.MatchString (_key) {
+		return "", errorf (0x278cb7ef, "header key invalid `%s`", _key)
+	}
+	switch _key {
+		case "timestamp" :
+			return "", errorf (0x3934000d, "header key ignored `%s`", _key)
+	}
+	
+	_values = append ([]string (nil), _values ...)
+	for _index, _value := range _values {
+		_value = stringTrimSpaces (_value)
+		if _value == "" {
+			return "", errorf (0x07cc4e01, "header value empty for `%s`", _key)
+		}
+		if strings.ContainsAny (_value, "\r\n") {
+			return "", errorf (0x49fc2299, "header value multi-line for `%s`", _key)
+		}
+		_values[_index] = _value
+	}
+	if len (_values) > 1 {
+		switch _key {
+			case "identifier", "library", "slug", "format", "created", "updated" :
+				return "", errorf (0x115995d5, "header value multiple for `%s`", _key)
+		}
+	}
+	
+	_header, _error := documentHeaderSplit (_source, _formatHint)
+	if _error != nil {
+		return "", _error
+	}
+	
+	if _header.syntax == "" {
+		if len (_values) == 0 {
+			return _source, nil
+		}
+		_header.syntax = "zzz"
+		_header.prefix = "## "
+		if (_source != "") && ! strings.HasPrefix (_source, "\n") && ! strings.HasPrefix (_source, "\r\n") {
+			_header.after = _header.newline + _source
+		}
+	}
+	
+	switch _header.syntax {
+		case "zzz" :
+			_header.lines = documentHeaderUpdateZzz (_header, _key, _values)
+		case "org" :
+			_header.lines = documentHeaderUpdateOrg (_header, _key, _values)
+		case "yaml", "toml" :
+			if _lines, _error := documentHeaderUpdateYamlOrToml (_header, _key, _values); _error == nil {
+				_header.lines = _lines
+			} else {
+				return "", _error
+			}
+		default :
+			panic (abortUnreachable (0x8c22d3b6))
+	}
+	
+	if (_header.prefix != "") && (len (_header.lines) == 0) {
+		// NOTE:  Without any header lines left, the separator empty line is not needed anymore.
+		if strings.HasPrefix (_header.after, "\r\n") {
+			_header.after = _header.after[2:]
+		} else if strings.HasPrefix (_header.after, "\n") {
+			_header.after = _header.after[1:]
+		}
+	}
+	
+	_buffer := BytesBufferNewSize (len (_source) + 4 * 1024)
+	defer BytesBufferRelease (_buffer)
+	_buffer.WriteString (_header.before)
+	for _, _line := range _header.lines {
+		_buffer.WriteString (_line)
+		_buffer.WriteString (_header.newline)
+	}
+	_buffer.WriteString (_header.after)
+	
+	_sourceNew := _buffer.String ()
+	
+	if _, _error := DocumentLoadFromBuffer (_sourceNew, _formatHint); _error != nil {
+		return "", _error
+	}
+	
+	return _sourceNew, nil
+}
+
+
+
+
+// NOTE:  Replaces the body of the given source, leaving the header untouched.
+func DocumentBodyReplace (_source string, _bodyLines []string, _formatHint string) (string, *Error) {
+	
+	_header, _error := documentHeaderSplit (_source, _formatHint)
+	if _error != nil {
+		return "", _error
+	}
+	
+	_buffer := BytesBufferNewSize (len (_source) + 4 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	if _header.syntax != "" {
+		_buffer.WriteString (_header.before)
+		for _, _line := range _header.lines {
+			_buffer.WriteString (_line)
+			_buffer.WriteString (_header.newline)
+		}
+		if _header.marker != "" {
+			_buffer.WriteString (_header.marker)
+			_buffer.WriteString (_header.newline)
+		}
+		if len (_bodyLines) > 0 {
+			_buffer.WriteString (_header.newline)
+		}
+	}
+	for _, _line := range _bodyLines {
+		_buffer.WriteString (_line)
+		_buffer.WriteString (_header.newline)
+	}
+	
+	_sourceNew := _buffer.String ()
+	
+	if _, _error := DocumentLoadFromBuffer (_sourceNew, _formatHint); _error != nil {
+		return "", _error
+	}
+	
+	return _sourceNew, nil
+}
+
+
+
+
+func documentHeaderSplit (_source string, _formatHint string) (*documentHeader, *Error) {
+	
+	_header := & documentHeader {
+			newline : "\n",
+		}
+	
+	_start := 0
+	_offset := 0
+	for {
+		
+		if _offset == len (_source) {
+			if _header.marker != "" {
+				return nil, errorw (0xab69e92f, nil)
+			}
+			break
+		}
+		
+		_splitIndex := strings.IndexByte (_source[_offset:], '\n')
+		if _splitIndex == -1 {
+			if _header.marker != "" {
+				return nil, errorw (0xb389c375, nil)
+			}
+			break
+		}
+		_line := _source[_offset : _offset + _splitIndex]
+		_next := _offset + _splitIndex + 1
+		if strings.HasSuffix (_line, "\r") {
+			_line = _line[: len (_line) - 1]
+			if _offset == 0 {
+				_header.newline = "\r\n"
+			}
+		}
+		
+		if _line == "" {
+			if _header.marker != "" {
+				return nil, errorw (0x47c9da16, nil)
+			}
+			break
+		}
+		
+		if _header.marker != "" {
+			if _line == _header.marker {
+				break
+			}
+		} else if _header.prefix == "" {
+			switch _line {
+				case "###" :
+					_header.syntax = "zzz"
+					_header.marker = _line
+				case "---" :
+					_header.syntax = "yaml"
+					_header.marker = _line
+				case "+++" :
+					_header.syntax = "toml"
+					_header.marker = _line
+			}
+			if _header.marker != "" {
+				_start = _next
+				_offset = _next
+				continue
+			}
+			if strings.HasPrefix (_line, "## ") {
+				_header.syntax = "zzz"
+				_header.prefix = "## "
+			} else if strings.HasPrefix (_line, "# ") {
+				_header.syntax = "zzz"
+				_header.prefix = "# "
+			} else if _, _, _ok := documentOrgKeywordParse (_line); _ok && (_formatHint == "org") {
+				_header.syntax = "org"
+				_header.prefix = "#+"
+			} else {
+				break
+			}
+		} else {
+			if ! strings.HasPrefix (_line, _header.prefix) {
+				break
+			}
+			if _header.syntax == "org" {
+				if _, _, _ok := documentOrgKeywordParse (_line); !_ok {
+					break
+				}
+			}
+		}
+		
+		_header.lines = append (_header.lines, _line)
+		_offset = _next
+	}
+	
+	_header.before = _source[:_start]
+	_header.after = _source[_offset:]
+	
+	return _header, nil
+}
+
+
+
+
+func documentHeaderUpdateZzz (_header *documentHeader, _key string, _values []string) ([]string) {
+	
+	_isTitle := (_key == "title") || (_key == "titles")
+	
+	_template := ""
+	_position := -1
+	_lines := make ([]string, 0, len (_header.lines) + len (_values))
+	for _, _line := range _header.lines {
+		_entry := _line[len (_header.prefix):]
+		_entry = stringTrimSpaces (_entry)
+		_matches := false
+		if strings.HasPrefix (_entry, "-- ") {
+			if !_isTitle {
+				_entry = stringTrimSpaces (_entry[3:])
+				if _splitIndex := strings.IndexByte (_entry, ':'); _splitIndex > 0 {
+					_matches = strings.ToLower (stringTrimSpaces (_entry[:_splitIndex])) == _key
+				}
+			}
+		} else {
+			_matches = _isTitle
+		}
+		if !_matches {
+			_lines = append (_lines, _line)
+			continue
+		}
+		if _position == -1 {
+			_position = len (_lines)
+			if !_isTitle {
+				_splitIndex := strings.IndexByte (_line, ':') + 1
+				for (_splitIndex < len (_line)) && ((_line[_splitIndex] == ' ') || (_line[_splitIndex] == '\t')) {
+					_splitIndex += 1
+				}
+				_template = _line[:_splitIndex]
+			}
+		}
+	}
+	
+	if _template == "" {
+		if _isTitle {
+			_template = _header.prefix
+		} else {
+			_template = _header.prefix + "-- " + _key + ": "
+		}
+	} else if ! strings.HasSuffix (_template, " ") && ! strings.HasSuffix (_template, "\t") {
+		_template += " "
+	}
+	if _position == -1 {
+		if _isTitle {
+			_position = 0
+		} else {
+			_position = len (_lines)
+		}
+	}
+	
+	_linesNew := make ([]string, 0, len (_values))
+	switch _key {
+		case "tags", "aliases" :
+			if len (_values) > 0 {
+				_linesNew = append (_linesNew, _template + strings.Join (_values, ", "))
+			}
+		default :
+			for _, _value := range _values {
+				_linesNew = append (_linesNew, _template + _value)
+			}
+	}
+	
+	_lines = append (_lines[:_position], append (_linesNew, _lines[_position:] ...) ...)
+	
+	return _lines
+}
+
+
+
+
+func documentHeaderUpdateOrg (_header *documentHeader, _key string, _values []string) ([]string) {
+	
+	_keyOrg := ""
+	_keysMatching := []string (nil)
+	switch _key {
+		case "title", "titles" :
+			_keyOrg = "TITLE"
+			_keysMatching = []string { "title" }
+		case "tags" :
+			_keyOrg = "FILETAGS"
+			_keysMatching = []string { "filetags", "tags" }
+		default :
+			_keyOrg = strings.ToUpper (_key)
+			_keysMatching = []string { _key }
+	}
+	
+	_position := -1
+	_lines := make ([]string, 0, len (_header.lines) + len (_values))
+	for _, _line := range _header.lines {
+		_matches := false
+		if _splitIndex := strings.IndexByte (_line, ':'); _splitIndex > len (_header.prefix) {
+			_lineKey := strings.ToLower (_line[len (_header.prefix) : _splitIndex])
+			for _, _keyMatching := range _keysMatching {
+				if _lineKey == _keyMatching {
+					_matches = true
+				}
+			}
+			if _matches && (_position == -1) {
+				// NOTE:  Keep the original spelling of the keyword (usually upper-case).
+				_keyOrg = _line[len (_header.prefix) : _splitIndex]
+			}
+		}
+		if !_matches {
+			_lines = append (_lines, _line)
+			continue
+		}
+		if _position == -1 {
+			_position = len (_lines)
+		}
+	}
+	
+	if _position == -1 {
+		if _keyOrg == "TITLE" {
+			_position = 0
+		} else {
+			_position = len (_lines)
+		}
+	}
+	
+	_template := _header.prefix + _keyOrg + ": "
+	
+	_linesNew := make ([]string, 0, len (_values))
+	switch _key {
+		case "tags" :
+			if len (_values) > 0 {
+				_linesNew = append (_linesNew, _template + ":" + strings.Join (_values, ":") + ":")
+			}
+		case "aliases" :
+			if len (_values) > 0 {
+				_linesNew = append (_linesNew, _template + strings.Join (_values, ", "))
+			}
+		default :
+			for _, _value := range _values {
+				_linesNew = append (_linesNew, _template + _value)
+			}
+	}
+	
+	_lines = append (_lines[:_position], append (_linesNew, _lines[_position:] ...) ...)
+	
+	return _lines
+}
+
+
+
+
+func documentHeaderUpdateYamlOrToml (_header *documentHeader, _key string, _values []string) ([]string, *Error) {
+	
+	_separator := ""
+	switch _header.syntax {
+		case "yaml" :
+			_separator = ":"
+		case "toml" :
+			_separator = "="
+		default :
+			panic (abortUnreachable (0xb421a5a4))
+	}
+	
+	_position := -1
+	_insert := -1
+	_tables := false
+	_matching := false
+	_lines := make ([]string, 0, len (_header.lines) + 1)
+	for _, _line := range _header.lines {
+		_continuation := (_line == "") || (_line[0] == ' ') || (_line[0] == '\t')
+		if !_continuation {
+			switch _header.syntax {
+				case "yaml" :
+					_continuation = strings.HasPrefix (_line, "- ") || (_line == "-")
+				case "toml" :
+					if strings.HasPrefix (_line, "[") && !_tables {
+						// NOTE:  Only the keys before the first table are header keys.
+						_tables = true
+						_insert = len (_lines)
+						_matching = false
+					}
+					_continuation = strings.IndexByte (_line, '=') == -1
+			}
+		}
+		if !_continuation {
+			_matching = false
+			if !_tables {
+				if _splitIndex := strings.Index (_line, _separator); _splitIndex > 0 {
+					_lineKey := stringTrimSpaces (_line[:_splitIndex])
+					_lineKey = strings.Trim (_lineKey, "\"'")
+					_lineKey = strings.ToLower (_lineKey)
+					_matching = _lineKey == _key
+				}
+			}
+			if _matching && (_position == -1) {
+				_position = len (_lines)
+			}
+		}
+		if !_matching {
+			_lines = append (_lines, _line)
+		}
+	}
+	
+	if len (_values) == 0 {
+		return _lines, nil
+	}
+	
+	_list := false
+	switch _key {
+		case "tags", "aliases", "titles" :
+			_list = true
+		default :
+			_list = len (_values) > 1
+	}
+	
+	_valuesEncoded := make ([]string, 0, len (_values))
+	for _, _value := range _values {
+		if _value_0, _error := documentHeaderEncodeValue (_header.syntax, _value, _list); _error == nil {
+			_valuesEncoded = append (_valuesEncoded, _value_0)
+		} else {
+			return nil, _error
+		}
+	}
+	
+	_lineNew := ""
+	_valueNew := ""
+	if _list {
+		_valueNew = "[" + strings.Join (_valuesEncoded, ", ") + "]"
+	} else {
+		_valueNew = _valuesEncoded[0]
+	}
+	switch _header.syntax {
+		case "yaml" :
+			_lineNew = _key + ": " + _valueNew
+		case "toml" :
+			_lineNew = _key + " = " + _valueNew
+	}
+	
+	if _position == -1 {
+		if _insert != -1 {
+			_position = _insert
+		} else {
+			_position = len (_lines)
+		}
+	}
+	
+	_lines = append (_lines[:_position], append ([]string { _lineNew }, _lines[_position:] ...) ...)
+	
+	return _lines, nil
+}
+
+
+func documentHeaderEncodeValue (_syntax string, _value string, _list bool) (string, *Error) {
+	if _syntax == "yaml" {
+		if _data, _error := yaml.Marshal (_value); _error == nil {
+			_plain := strings.TrimSuffix (string (_data), "\n")
+			if (_plain == _value) && ! strings.ContainsAny (_value, ",[]{}#\"'") {
+				return _plain, nil
+			}
+		} else {
+			return "", errorw (0x9c7e5dcd, _error)
+		}
+	}
+	if _data, _error := json.Marshal (_value); _error == nil {
+		return string (_data), nil
+	} else {
+		return "", errorw (0x1ceae967, _error)
+	}
+}
+
+
+
+
+func DocumentHeaderValues (_document *Document, _key string) ([]string, *Error) {
+	
+	_key = stringTrimSpaces (_key)
+	_key = strings.ToLower (_key)
+	if ! DocumentMetadataKeyRegex.MatchString (_key) {
+		return nil, errorf (0x6732f50d, "header key invalid `%s`", _key)
+	}
+	
+	_values := []string (nil)
+	switch _key {
+		case "identifier" :
+			_values = []string { _document.Identifier }
+		case "library" :
+			_values = []string { _document.Library }
+		case "title" :
+			_values = []string { _document.TitleOriginal }
+		case "titles" :
+			_values = _document.TitleOriginalAlternatives
+		case "format" :
+			_values = []string { _document.Format }
+		case "tags" :
+			_values = _document.Tags
+		case "aliases" :
+			_values = _document.Aliases
+		case "created" :
+			if ! _document.Created.IsZero () {
+				_values = []string { _document.Created.Format (time.RFC3339) }
+			}
+		case "updated" :
+			if ! _document.Updated.IsZero () {
+				_values = []string { _document.Updated.Format (time.RFC3339) }
+			}
+		default :
+			_values = _document.Metadata[_key]
+	}
+	
+	_valuesNonEmpty := make ([]string, 0, len (_values))
+	for _, _value := range _values {
+		if _value != "" {
+			_valuesNonEmpty = append (_valuesNonEmpty, _value)
+		}
+	}
+	
+	return _valuesNonEmpty, nil
+}
+
@@ -0,0 +1,249 @@
+package zscratchpad
+
+
+import "net/url"
+import "os"
+import "path/filepath"
+import "strings"
+
+import html_parser "golang.org/x/net/html"
+import html_atom "golang.org/x/net/html/atom"
+
+
+
+
+type DocumentLinkBroken struct {
+	Document string
+	Path string
+	Line uint
+	Label string
+	Url string
+	Reason string
+}
+
+
+
+
+// NOTE:  The links are taken from the rendered HTML, as written in the source (i.e. before being mangled), thus any format is supported.
+// NOTE:  The anchors of the targets are cached (by document identifier), thus the same map should be used when checking multiple documents.
+func DocumentLinksCheck (_document *Document, _index *Index, _anchorsCache map[string]map[string]bool) ([]*DocumentLinkBroken, *Error) {
+	
+	_broken := make ([]*DocumentLinkBroken, 0, 16)
+	_report := func (_label string, _url string, _line uint, _reason string) () {
+		_broken = append (_broken, & DocumentLinkBroken {
+				Document : _document.Identifier,
+				Path : _document.Path,
+				Line : _line,
+				Label : _label,
+				Url : _url,
+				Reason : _reason,
+			})
+	}
+	
+	_nodes, _error := documentLinksParse (_document, _index)
+	if _error != nil {
+		_report ("", "", 0, "failed rendering:  " + _error.ToError () .Error ())
+		return _broken, nil
+	}
+	
+	_anchorsCache[_document.Identifier] = documentLinksAnchorsCollect (_nodes)
+	
+	// NOTE:  The same URL might be used multiple times, thus each occurrence is searched after the previous one.
+	_linesNext := make (map[string]int, 16)
+	
+	var _walk func (*html_parser.Node) (*Error)
+	_walk = func (_node *html_parser.Node) (*Error) {
+		if _node.Type == html_parser.ElementNode {
+			for _, _attribute := range _node.Attr {
+				if !strings.HasPrefix (_attribute.Key, "data-zs-url-original-") {
+					continue
+				}
+				_urlString := _attribute.Val
+				_reason, _error := documentLinkCheck (_document, _index, _anchorsCache, _urlString)
+				if _error != nil {
+					return _error
+				}
+				if _reason == "" {
+					continue
+				}
+				_line := documentLinkLine (_document, _urlString, _linesNext)
+				_report (documentLinkLabel (_node), _urlString, _line, _reason)
+			}
+		}
+		for _child := _node.FirstChild; _child != nil; _child = _child.NextSibling {
+			if _error := _walk (_child); _error != nil {
+				return _error
+			}
+		}
+		return nil
+	}
+	for _, _node := range _nodes {
+		if _error := _walk (_node); _error != nil {
+			return nil, _error
+		}
+	}
+	
+	return _broken, nil
+}
+
+
+
+
+// NOTE:  Returns an empty reason if the link is valid, or if it can't be checked (e.g. external links).
+func documentLinkCheck (_document *Document, _index *Index, _anchorsCache map[string]map[string]bool, _urlString string) (string, *Error) {
+	
+	if (_urlString == "") || (_urlString == "/ue/") {
+		return "invalid URL", nil
+	}
+	
+	_url := (*url.URL) (nil)
+	if _url_0, _error := url.Parse (_urlString); _error == nil {
+		_url = _url_0
+	} else {
+		return "invalid URL", nil
+	}
+	
+	_scheme := strings.ToLower (_url.Scheme)
+	_target := ""
+	_type := ""
+	switch {
+		case (_scheme == "sd") || (_scheme == "sl") :
+			_target = _url.Opaque
+			_type = _scheme[1:]
+		case (_scheme != "") || (_url.Host != "") :
+			// NOTE:  External links, and wiki links (which are allowed to dangle), are not checked.
+			return "", nil
+		case strings.HasPrefix (_url.Path, "/d/") :
+			_target = _url.Path[3:]
+			_type = "d"
+		case strings.HasPrefix (_url.Path, "/l/") :
+			_target = _url.Path[3:]
+			_type = "l"
+		case strings.HasPrefix (_url.Path, "/") :
+			return "", nil
+		case _url.Path == "" :
+			if _url.Fragment == "" {
+				return "", nil
+			}
+			if !_anchorsCache[_document.Identifier][_url.Fragment] {
+				return "anchor not found", nil
+			}
+			return "", nil
+		default :
+			if _document.Path == "" {
+				return "", nil
+			}
+			_path := filepath.Join (filepath.Dir (_document.Path), filepath.FromSlash (_url.Path))
+			if _, _error := os.Stat (_path); _error != nil {
+				if os.IsNotExist (_error) {
+					return "file not found", nil
+				}
+				return "", errorw (0x98a636c5, _error)
+			}
+			return "", nil
+	}
+	
+	if _target == "" {
+		return "invalid URL", nil
+	}
+	
+	if _type == "l" {
+		if _library, _error := IndexLibraryResolve (_index, _target); _error == nil {
+			if _library == nil {
+				return "library not found", nil
+			}
+		} else {
+			return "", _error
+		}
+		return "", nil
+	}
+	
+	_targetDocument, _error := IndexDocumentResolve (_index, _target)
+	if _error != nil {
+		return "", _error
+	}
+	if _targetDocument == nil {
+		return "document not found", nil
+	}
+	
+	if _url.Fragment == "" {
+		return "", nil
+	}
+	
+	_anchors, _cached := _anchorsCache[_targetDocument.Identifier]
+	if !_cached {
+		if _nodes, _error := documentLinksParse (_targetDocument, _index); _error == nil {
+			_anchors = documentLinksAnchorsCollect (_nodes)
+		} else {
+			return "target failed rendering", nil
+		}
+		_anchorsCache[_targetDocument.Identifier] = _anchors
+	}
+	if !_anchors[_url.Fragment] {
+		return "anchor not found", nil
+	}
+	
+	return "", nil
+}
+
+
+
+
+func documentLinksParse (_document *Document, _index *Index) ([]*html_parser.Node, *Error) {
+	
+	if _, _error := DocumentRenderToHtml (_document, false, _index); _error != nil {
+		return nil, _error
+	}
+	
+	_context := & html_parser.Node {
+			Type : html_parser.ElementNode,
+			Data : "body",
+			DataAtom : html_atom.Body,
+		}
+	_nodes, _error := html_parser.ParseFragment (strings.NewReader (_document.RenderHtml), _context)
+	if _error != nil {
+		return nil, errorw (0x66bf140a, _error)
+	}
+	
+	return _nodes, nil
+}
+
+
+func documentLinksAnchorsCollect (_nodes []*html_parser.Node) (map[string]bool) {
+	_anchors := make (map[string]bool, 64)
+	for _, _node := range _nodes {
+		collectAnchors (_node, _anchors)
+	}
+	return _anchors
+}
+
+
+
+
+// NOTE:  The line is unknown (i.e. zero) when the URL doesn't appear verbatim in the source (e.g. it comes from an include).
+func documentLinkLine (_document *Document, _urlString string, _linesNext map[string]int) (uint) {
+	for _index := _linesNext[_urlString]; _index < len (_document.BodyLines); _index += 1 {
+		if strings.Contains (_document.BodyLines[_index], _urlString) {
+			_linesNext[_urlString] = _index + 1
+			return uint (_document.BodyOffset) + uint (_index) + 1
+		}
+	}
+	return 0
+}
+
+
+func documentLinkLabel (_node *html_parser.Node) (string) {
+	if _node.DataAtom == html_atom.A {
+		if _label := strings.Join (strings.Fields (extractText (_node)), " "); _label != "" {
+			return _label
+		}
+	}
+	for _, _attribute := range _node.Attr {
+		if (_attribute.Key == "alt") || (_attribute.Key == "title") {
+			if _label := strings.TrimSpace (_attribute.Val); _label != "" {
+				return _label
+			}
+		}
+	}
+	return ""
+}
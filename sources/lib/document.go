@@ -8,8 +8,10 @@ import "io"
 import "os"
 import "path"
 import "regexp"
+import "sort"
 import "strings"
 import "time"
+import "unicode"
 import "unicode/utf8"
 
 
@@ -27,6 +29,8 @@ type Document struct {
 	Path string
 	PathInLibrary string
 	
+	Aliases []string
+	
 	Title string
 	TitleAlternatives []string
 	
@@ -37,20 +41,35 @@ type Document struct {
 	
 	Format string
 	
+	Tags []string
+	Metadata map[string][]string
+	
 	BodyLines []string
+	BodyOffset uint64
 	BodyEmpty bool
 	BodyFingerprint string
 	
 	EditEnabled bool
+	Encrypted bool
 	Timestamp time.Time
 	
+	Created time.Time
+	Updated time.Time
+	
+	// NOTE:  Otherwise the timestamps above are just the file modification timestamp.
+	CreatedFromHeader bool
+	UpdatedFromHeader bool
+	
 	// NOTE:  These are not stored in database!
 	
 	RenderHtml string
 	RenderHtmlExport string
 	RenderText string
 	
+	RenderIncludes map[string]string
+	
 	HtmlLinks map[string][]string
+	HtmlOutline []*DocumentOutlineEntry
 }
 
 
@@ -89,7 +108,7 @@ func DocumentInitializeIdentifier_0 (_document *Document, _useLibraryPrefix bool
 	}
 	
 	if (_document.PathInLibrary != "") && _usePathInLibrary {
-		_folderPath, _fileName := path.Split (_document.PathInLibrary)
+		_folderPath, _fileName := path.Split (EncryptionPathTrim (_document.PathInLibrary))
 		if _documentName_0, _, _error := pathSplitFileNameAndExtension (_fileName); _error == nil {
 			if _folderPath != "" {
 				_folderPath = _folderPath[: len(_folderPath) - 1]
@@ -106,7 +125,7 @@ func DocumentInitializeIdentifier_0 (_document *Document, _useLibraryPrefix bool
 	}
 	
 	if (_document.Path != "") && _useFileName {
-		if _documentName_0, _, _error := pathSplitFileNameAndExtension (_document.Path); _error == nil {
+		if _documentName_0, _, _error := pathSplitFileNameAndExtension (EncryptionPathTrim (_document.Path)); _error == nil {
 			_documentName = _documentName_0
 			goto _resolve
 		} else {
@@ -128,10 +147,22 @@ func DocumentInitializeIdentifier_0 (_document *Document, _useLibraryPrefix bool
 	
 	if _identifier, _error := DocumentFormatIdentifier (_libraryIdentifier, _documentName); _error == nil {
 		_document.Identifier = _identifier
-		return nil
 	} else {
 		return _error
 	}
+	
+	for _aliasIndex, _alias := range _document.Aliases {
+		if strings.IndexByte (_alias, ':') != -1 {
+			continue
+		}
+		if _alias_0, _error := DocumentFormatIdentifier (_libraryIdentifier, _alias); _error == nil {
+			_document.Aliases[_aliasIndex] = _alias_0
+		} else {
+			return _error
+		}
+	}
+	
+	return nil
 }
 
 
@@ -156,16 +187,7 @@ func DocumentInitializeFormat_0 (_document *Document, _useFileExtension bool) (*
 	}
 	
 	if (_document.Path != "") && _useFileExtension {
-		if _, _extension, _error := pathSplitFileNameAndExtension (_document.Path); _error == nil {
-			_format := ""
-			switch _extension {
-				case "md", "markdown" :
-					_format = "commonmark"
-				case "gmi", "gemini" :
-					_format = "gemini"
-				case "txt", "text" :
-					_format = "text"
-			}
+		if _format, _error := documentFormatFromExtension (_document.Path); _error == nil {
 			if _format != "" {
 				_document.Format = _format
 				return nil
@@ -181,6 +203,26 @@ func DocumentInitializeFormat_0 (_document *Document, _useFileExtension bool) (*
 }
 
 
+func documentFormatFromExtension (_path string) (string, *Error) {
+	_, _extension, _error := pathSplitFileNameAndExtension (EncryptionPathTrim (_path))
+	if _error != nil {
+		return "", _error
+	}
+	switch _extension {
+		case "md", "markdown" :
+			return "commonmark", nil
+		case "gmi", "gemini" :
+			return "gemini", nil
+		case "org" :
+			return "org", nil
+		case "txt", "text" :
+			return "text", nil
+		default :
+			return "", nil
+	}
+}
+
+
 
 
 func DocumentValidateIdentifier (_identifier string) (*Error) {
@@ -228,6 +270,218 @@ var DocumentIdentifierRegex *regexp.Regexp = regexp.MustCompile (`^` + DocumentI
 
 
 
+func DocumentValidateTag (_tag string) (*Error) {
+	if ! DocumentTagRegex.MatchString (_tag) {
+		return errorf (0x47d22258, "tag invalid `%s`", _tag)
+	}
+	return nil
+}
+
+func DocumentParseTag (_tag string) (string, *Error) {
+	_tag = stringTrimSpaces (_tag)
+	_tag = strings.TrimPrefix (_tag, "#")
+	_tag = strings.ToLower (_tag)
+	if _error := DocumentValidateTag (_tag); _error != nil {
+		return "", _error
+	}
+	return _tag, nil
+}
+
+func DocumentHasTags (_document *Document, _tags []string) (bool) {
+	for _, _tag := range _tags {
+		_found := false
+		for _, _documentTag := range _document.Tags {
+			if _documentTag == _tag {
+				_found = true
+				break
+			}
+		}
+		if !_found {
+			return false
+		}
+	}
+	return true
+}
+
+
+func documentTagsNormalize (_tags []string) ([]string, *Error) {
+	if len (_tags) == 0 {
+		return nil, nil
+	}
+	_tagsSet := make (map[string]bool, len (_tags))
+	_tagsNormalized := make ([]string, 0, len (_tags))
+	for _, _tag := range _tags {
+		if stringTrimSpaces (_tag) == "" {
+			continue
+		}
+		if _tag_0, _error := DocumentParseTag (_tag); _error == nil {
+			_tag = _tag_0
+		} else {
+			return nil, _error
+		}
+		if _, _exists := _tagsSet[_tag]; _exists {
+			continue
+		}
+		_tagsSet[_tag] = true
+		_tagsNormalized = append (_tagsNormalized, _tag)
+	}
+	sort.Strings (_tagsNormalized)
+	return _tagsNormalized, nil
+}
+
+func documentAliasesNormalize (_aliases []string) ([]string, *Error) {
+	if len (_aliases) == 0 {
+		return nil, nil
+	}
+	_aliasesSet := make (map[string]bool, len (_aliases))
+	_aliasesNormalized := make ([]string, 0, len (_aliases))
+	for _, _alias := range _aliases {
+		_alias = stringTrimSpaces (_alias)
+		if _alias == "" {
+			continue
+		}
+		if strings.IndexByte (_alias, ':') != -1 {
+			if _error := DocumentValidateIdentifier (_alias); _error != nil {
+				return nil, errorf (0xbe73c4ed, "alias invalid `%s`", _alias)
+			}
+		} else {
+			if ! DocumentIdentifierWithoutLibraryRegex.MatchString (_alias) {
+				return nil, errorf (0x11ac8951, "alias invalid `%s`", _alias)
+			}
+		}
+		if _, _exists := _aliasesSet[_alias]; _exists {
+			continue
+		}
+		_aliasesSet[_alias] = true
+		_aliasesNormalized = append (_aliasesNormalized, _alias)
+	}
+	sort.Strings (_aliasesNormalized)
+	return _aliasesNormalized, nil
+}
+
+
+func documentTagsSplit (_rune rune) (bool) {
+	return (_rune == ',') || unicode.IsSpace (_rune)
+}
+
+
+func DocumentMetadataKeys (_document *Document) ([]string) {
+	_keys := make ([]string, 0, len (_document.Metadata))
+	for _key, _ := range _document.Metadata {
+		_keys = append (_keys, _key)
+	}
+	sort.Strings (_keys)
+	return _keys
+}
+
+
+func documentMetadataValues (_value interface{}) ([]string, bool) {
+	switch _value := _value.(type) {
+		case nil :
+			return nil, true
+		case string :
+			return []string { _value }, true
+		case bool, int, int64, uint64, float64 :
+			return []string { fmt.Sprint (_value) }, true
+		case time.Time :
+			return []string { _value.Format (time.RFC3339) }, true
+		case fmt.Stringer :
+			return []string { _value.String () }, true
+		case []interface{} :
+			_values := make ([]string, 0, len (_value))
+			for _, _value := range _value {
+				switch _value.(type) {
+					case []interface{} :
+						return nil, false
+				}
+				if _values_0, _ok := documentMetadataValues (_value); _ok {
+					_values = append (_values, _values_0 ...)
+				} else {
+					return nil, false
+				}
+			}
+			return _values, true
+		default :
+			return nil, false
+	}
+}
+
+
+func documentTimestampParse (_value string) (time.Time, *Error) {
+	_value = stringTrimSpaces (_value)
+	if _value == "" {
+		return time.Time {}, errorf (0xfb32c6f0, "timestamp empty")
+	}
+	if _timestamp, _error := time.Parse (time.RFC3339, _value); _error == nil {
+		return _timestamp, nil
+	}
+	for _, _layout := range documentTimestampLayouts {
+		if _timestamp, _error := time.ParseInLocation (_layout, _value, time.Local); _error == nil {
+			return _timestamp, nil
+		}
+	}
+	return time.Time {}, errorf (0xe5403561, "timestamp invalid `%s`", _value)
+}
+
+var documentTimestampLayouts = []string {
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+
+
+var DocumentMetadataKeyRegex *regexp.Regexp = regexp.MustCompile (`^(?:[a-z0-9]+)(?:[_-][a-z0-9]+)*$`)
+
+
+var DocumentTagRegexToken string = `(?:(?:[a-z0-9]+)(?:[._/-][a-z0-9]+)*)`
+var DocumentTagRegex *regexp.Regexp = regexp.MustCompile (`^` + DocumentTagRegexToken + `$`)
+
+
+var DocumentOrgKeywordRegex *regexp.Regexp = regexp.MustCompile (`^#\+([A-Za-z0-9]+(?:[_-][A-Za-z0-9]+)*):(?:[ \t]+(.*))?$`)
+
+
+// NOTE:  Only the Org-mode in-buffer keywords (like `#+TITLE:`) are header lines;
+//        the affiliated keywords (like `#+CAPTION:` or `#+ATTR_HTML:`) belong to the body.
+func documentOrgKeywordParse (_line string) (string, string, bool) {
+	_matches := DocumentOrgKeywordRegex.FindStringSubmatch (_line)
+	if _matches == nil {
+		return "", "", false
+	}
+	_key := strings.ToLower (_matches[1])
+	_value := stringTrimSpaces (_matches[2])
+	switch _key {
+		case "caption", "name", "results", "header", "plot", "begin", "end" :
+			return "", "", false
+	}
+	if strings.HasPrefix (_key, "attr_") {
+		return "", "", false
+	}
+	return _key, _value, true
+}
+
+func documentOrgTagsSplit (_rune rune) (bool) {
+	return (_rune == ':') || documentTagsSplit (_rune)
+}
+
+// NOTE:  Org-mode timestamps (like `[2024-01-01 Mon]` or `<2024-01-01 Mon 10:00>`) are reduced to the date and time, dropping the weekday.
+func documentOrgTimestampTrim (_value string) (string) {
+	_matches := documentOrgTimestampRegex.FindStringSubmatch (_value)
+	if _matches == nil {
+		return _value
+	}
+	if _matches[2] != "" {
+		return _matches[1] + " " + _matches[2]
+	}
+	return _matches[1]
+}
+
+var documentOrgTimestampRegex *regexp.Regexp = regexp.MustCompile (`^[\[<]([0-9]{4}-[0-9]{2}-[0-9]{2})(?:[ \t]+[^ \t\]>0-9][^ \t\]>]*)?(?:[ \t]+([0-9]{1,2}:[0-9]{2}(?::[0-9]{2})?))?[ \t]*[\]>]$`)
+
+
+
+
 func DocumentInitializeTitle (_document *Document, _library *Library) (*Error) {
 	if (_library != nil) && (_document.Library != _library.Identifier) {
 		return errorw (0x6966f128, nil)
@@ -252,7 +506,7 @@ func DocumentInitializeTitle (_document *Document, _library *Library) (*Error) {
 
 
 
-func DocumentLoadFromPath (_path string) (*Document, *Error) {
+func DocumentLoadFromPath (_path string, _library *Library) (*Document, *Error) {
 	
 	var _file *os.File
 	if _file_0, _error := os.OpenFile (_path, os.O_RDONLY, 0); _error == nil {
@@ -278,14 +532,32 @@ func DocumentLoadFromPath (_path string) (*Document, *Error) {
 	}
 	
 	_sourceBytes := _sourceBuffer.Bytes ()
+	_encrypted := EncryptionPathIs (_path)
+	if _encrypted {
+		if _sourceBytes_0, _error := EncryptionDecrypt (_sourceBytes, _library); _error == nil {
+			_sourceBytes = _sourceBytes_0
+		} else {
+			return nil, _error
+		}
+	}
 	if ! utf8.Valid (_sourceBytes) {
 //		logf ('d', 0x742720c2, "%s", _path)
 		return nil, errorf (0xa24965ce, "invalid UTF-8 source")
 	}
 	_source := string (_sourceBytes)
 	
+	// NOTE:  The format is needed to recognize some header syntaxes (e.g. Org-mode keywords), thus guess it from the extension.
+	_formatHint := ""
+	if (_library == nil) || _library.UseFileExtensionAsFormat {
+		if _format, _error := documentFormatFromExtension (_path); _error == nil {
+			_formatHint = _format
+		} else {
+			return nil, _error
+		}
+	}
+	
 	var _document *Document
-	if _document_0, _error := DocumentLoadFromBuffer (_source); _error == nil {
+	if _document_0, _error := DocumentLoadFromBuffer (_source, _formatHint); _error == nil {
 		_document = _document_0
 	} else {
 		return nil, _error
@@ -293,7 +565,14 @@ func DocumentLoadFromPath (_path string) (*Document, *Error) {
 	
 	if _document != nil {
 		_document.Path = _path
+		_document.Encrypted = _encrypted
 		_document.Timestamp = _timestamp
+		if _document.Created.IsZero () {
+			_document.Created = _timestamp
+		}
+		if _document.Updated.IsZero () {
+			_document.Updated = _timestamp
+		}
 	}
 	
 	return _document, nil
@@ -301,7 +580,8 @@ func DocumentLoadFromPath (_path string) (*Document, *Error) {
 
 
 
-func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
+// NOTE:  The format hint is the format the document is expected to have (if known), as the Org-mode header syntax is recognized only for `org` documents.
+func DocumentLoadFromBuffer (_source string, _formatHint string) (*Document, *Error) {
 	
 	var _identifier string
 	var _library string
@@ -309,6 +589,11 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 	var _format string
 	var _title string
 	var _titles []string
+	var _tags []string
+	var _aliases []string
+	var _metadata map[string][]string
+	var _created time.Time
+	var _updated time.Time
 	
 	_body := _source
 	_headerSyntax := ""
@@ -364,6 +649,11 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			if ! strings.HasPrefix (_header, _headerPrefix) {
 				break
 			}
+			if _headerSyntax == "org" {
+				if _, _, _ok := documentOrgKeywordParse (_header); !_ok {
+					break
+				}
+			}
 		} else if _headerMarker == "" {
 			if strings.HasPrefix (_header, "## ") {
 				_headerSyntax = "zzz"
@@ -371,6 +661,9 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			} else if strings.HasPrefix (_header, "# ") {
 				_headerSyntax = "zzz"
 				_headerPrefix = "# "
+			} else if _, _, _ok := documentOrgKeywordParse (_header); _ok && (_formatHint == "org") {
+				_headerSyntax = "org"
+				_headerPrefix = "#+"
 			}
 		}
 		
@@ -382,7 +675,7 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			}
 		}
 		
-		if _headerPrefix != "" {
+		if (_headerPrefix != "") && (_headerSyntax != "org") {
 			_header = _header[len (_headerPrefix):]
 			_header = stringTrimSpaces (_header)
 		}
@@ -395,6 +688,35 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 		_body = _rest
 	}
 	
+	if _headerSyntax == "org" {
+		
+		// NOTE:  The Org-mode keywords are translated into the equivalent `zzz` header lines.
+		_headerLinesOrg := _headerLines
+		_headerLines = make ([]string, 0, len (_headerLinesOrg))
+		for _, _header := range _headerLinesOrg {
+			_key, _value, _ok := documentOrgKeywordParse (_header)
+			if !_ok {
+				panic (abortUnreachable (0xe779e831))
+			}
+			if _value == "" {
+				continue
+			}
+			switch _key {
+				case "title" :
+					_header = _value
+				case "filetags", "tags" :
+					_header = "-- tags: " + strings.Join (strings.FieldsFunc (_value, documentOrgTagsSplit), ", ")
+				case "created", "updated" :
+					_header = "-- " + _key + ": " + documentOrgTimestampTrim (_value)
+				default :
+					_header = "-- " + _key + ": " + _value
+			}
+			_headerLines = append (_headerLines, _header)
+		}
+		
+		_headerSyntax = "zzz"
+	}
+	
 	if _headerSyntax == "zzz" {
 		
 		for _, _header := range _headerLines {
@@ -446,10 +768,53 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 					if _title == "" {
 						_title = _title_0
 					}
+				} else if strings.HasPrefix (_header, "tags:") {
+					_tags_0 := _header[5:]
+					_tags = append (_tags, strings.FieldsFunc (_tags_0, documentTagsSplit) ...)
+				} else if strings.HasPrefix (_header, "aliases:") {
+					_aliases_0 := _header[8:]
+					_aliases = append (_aliases, strings.FieldsFunc (_aliases_0, documentTagsSplit) ...)
+				} else if strings.HasPrefix (_header, "created:") {
+					_created_0 := _header[8:]
+					if _created_0, _error := documentTimestampParse (_created_0); _error == nil {
+						if ! _created.IsZero () {
+							return nil, errorw (0xbf739f86, nil)
+						}
+						_created = _created_0
+					} else {
+						return nil, _error
+					}
+				} else if strings.HasPrefix (_header, "updated:") {
+					_updated_0 := _header[8:]
+					if _updated_0, _error := documentTimestampParse (_updated_0); _error == nil {
+						if ! _updated.IsZero () {
+							return nil, errorw (0xf8774ec5, nil)
+						}
+						_updated = _updated_0
+					} else {
+						return nil, _error
+					}
 				} else if strings.HasPrefix (_header, "timestamp:") {
 					// NOTE:  Ignore timestamps from file.
 				} else {
-					return nil, errorf (0xc5ccdc9e, "metadata invalid `%s`", _header)
+					_key := ""
+					_value := ""
+					if _splitIndex := strings.IndexByte (_header, ':'); _splitIndex > 0 {
+						_key = _header[:_splitIndex]
+						_key = stringTrimSpaces (_key)
+						_key = strings.ToLower (_key)
+						_value = _header[_splitIndex + 1:]
+						_value = stringTrimSpaces (_value)
+					}
+					if ! DocumentMetadataKeyRegex.MatchString (_key) {
+						return nil, errorf (0xc5ccdc9e, "metadata invalid `%s`", _header)
+					}
+					if _metadata == nil {
+						_metadata = make (map[string][]string, 16)
+					}
+					if _value != "" {
+						_metadata[_key] = append (_metadata[_key], _value)
+					}
 				}
 				
 			} else {
@@ -470,6 +835,10 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			Title string
 			Titles []string
 			Format string
+			Tags interface{}
+			Aliases interface{}
+			Created interface{}
+			Updated interface{}
 			Timestamp string
 		}
 		
@@ -481,19 +850,51 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			_headerBuffer.WriteString ("\n")
 		}
 		
+		_headerAll := make (map[string]interface{}, 16)
+		
 		switch _headerSyntax {
 			case "toml" :
 				if _error := toml.Unmarshal (_headerBuffer.Bytes (), &_header); _error != nil {
 					return nil, errorw (0x9dde97ab, _error)
 				}
+				if _error := toml.Unmarshal (_headerBuffer.Bytes (), &_headerAll); _error != nil {
+					return nil, errorw (0xf78a34f6, _error)
+				}
 			case "yaml" :
 				if _error := yaml.Unmarshal (_headerBuffer.Bytes (), &_header); _error != nil {
 					return nil, errorw (0x3c886fe6, _error)
 				}
+				if _error := yaml.Unmarshal (_headerBuffer.Bytes (), &_headerAll); _error != nil {
+					return nil, errorw (0xbd1db3e0, _error)
+				}
 			default :
 				panic (abortUnreachable (0x93b101bf))
 		}
 		
+		for _key, _value := range _headerAll {
+			_key = strings.ToLower (_key)
+			switch _key {
+				case "identifier", "library", "slug", "title", "titles", "format", "tags", "aliases", "created", "updated", "timestamp" :
+					continue
+			}
+			if ! DocumentMetadataKeyRegex.MatchString (_key) {
+				return nil, errorf (0xad3e4415, "metadata invalid `%s`", _key)
+			}
+			_values, _ok := documentMetadataValues (_value)
+			if !_ok {
+				return nil, errorf (0xdb0d884b, "metadata invalid `%s`", _key)
+			}
+			if _metadata == nil {
+				_metadata = make (map[string][]string, 16)
+			}
+			for _, _value := range _values {
+				_value = stringTrimSpaces (_value)
+				if _value != "" {
+					_metadata[_key] = append (_metadata[_key], _value)
+				}
+			}
+		}
+		
 		_header.Identifier = stringTrimSpaces (_header.Identifier)
 		if _header.Identifier != "" {
 			_identifier = _header.Identifier
@@ -528,11 +929,52 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			}
 			_titles = append (_titles, _title)
 		}
+		// NOTE:  Tags can be either a string or a list, and (as with `zzz` headers) each entry is further split.
+		if _values, _ok := documentMetadataValues (_header.Tags); _ok {
+			for _, _value := range _values {
+				_tags = append (_tags, strings.FieldsFunc (_value, documentTagsSplit) ...)
+			}
+		} else {
+			return nil, errorw (0x3441b9cc, nil)
+		}
+		// NOTE:  Aliases, like tags, can be either a string or a list.
+		if _values, _ok := documentMetadataValues (_header.Aliases); _ok {
+			for _, _value := range _values {
+				_aliases = append (_aliases, strings.FieldsFunc (_value, documentTagsSplit) ...)
+			}
+		} else {
+			return nil, errorw (0x9c00cf5b, nil)
+		}
+		if _header.Created != nil {
+			if _values, _ok := documentMetadataValues (_header.Created); _ok && (len (_values) == 1) {
+				if _created_0, _error := documentTimestampParse (_values[0]); _error == nil {
+					_created = _created_0
+				} else {
+					return nil, _error
+				}
+			} else {
+				return nil, errorw (0x49abcf43, nil)
+			}
+		}
+		if _header.Updated != nil {
+			if _values, _ok := documentMetadataValues (_header.Updated); _ok && (len (_values) == 1) {
+				if _updated_0, _error := documentTimestampParse (_values[0]); _error == nil {
+					_updated = _updated_0
+				} else {
+					return nil, _error
+				}
+			} else {
+				return nil, errorw (0x9d458979, nil)
+			}
+		}
 		
 	} else if _headerSyntax != "" {
 		panic (abortUnreachable (0x514cd03a))
 	}
 	
+	// NOTE:  The offset (in lines) of the body within the source, used to position the editor.
+	_bodyOffset := uint64 (strings.Count (_source[: len (_source) - len (_body)], "\n"))
+	
 	_bodyLines_0, _ := stringSplitLines (_body)
 	_bodyLines := make ([]string, 0, len (_bodyLines_0))
 	_bodyLinesEmpty := 0
@@ -544,6 +986,7 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			_bodyLinesEmpty = 0
 		} else {
 			if len (_bodyLines) == 0 {
+				_bodyOffset += 1
 				continue
 			} else {
 				_bodyLinesEmpty += 1
@@ -576,7 +1019,7 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 	}
 	if _format != "" {
 		switch _format {
-			case "commonmark", "gemini", "snippets", "text" :
+			case "commonmark", "gemini", "org", "snippets", "text" :
 				// NOP
 			case "markdown" :
 				_format = "commonmark"
@@ -585,6 +1028,26 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 		}
 	}
 	
+	if _tags_0, _error := documentTagsNormalize (_tags); _error == nil {
+		_tags = _tags_0
+	} else {
+		return nil, _error
+	}
+	if _aliases_0, _error := documentAliasesNormalize (_aliases); _error == nil {
+		_aliases = _aliases_0
+	} else {
+		return nil, _error
+	}
+	
+	for _key, _values := range _metadata {
+		if len (_values) == 0 {
+			delete (_metadata, _key)
+		}
+	}
+	if len (_metadata) == 0 {
+		_metadata = nil
+	}
+	
 	sortfold.Strings (_titles)
 	
 	_sourceFingerprint := fingerprintString (_source)
@@ -601,9 +1064,17 @@ func DocumentLoadFromBuffer (_source string) (*Document, *Error) {
 			TitleOriginalAlternatives : _titles,
 			Identifier : _identifier,
 			Library : _library,
+			Aliases : _aliases,
 			Format : _format,
+			Tags : _tags,
+			Metadata : _metadata,
+			Created : _created,
+			Updated : _updated,
+			CreatedFromHeader : ! _created.IsZero (),
+			UpdatedFromHeader : ! _updated.IsZero (),
 			SourceFingerprint : _sourceFingerprint,
 			BodyLines : _bodyLines,
+			BodyOffset : _bodyOffset,
 			BodyEmpty : _bodyEmpty,
 			BodyFingerprint : _bodyFingerprint,
 		}
@@ -629,6 +1100,16 @@ func DocumentDump (_stream io.Writer, _document *Document, _includeIdentifiers b
 		fmt.Fprintf (_buffer, "-- title (alternative): `%s`\n", _title)
 	}
 	
+	for _, _tag := range _document.Tags {
+		fmt.Fprintf (_buffer, "-- tag: `%s`\n", _tag)
+	}
+	
+	for _, _key := range DocumentMetadataKeys (_document) {
+		for _, _value := range _document.Metadata[_key] {
+			fmt.Fprintf (_buffer, "-- metadata `%s`: `%s`\n", _key, _value)
+		}
+	}
+	
 	if _includeIdentifiers {
 		if _document.Identifier != "" {
 			fmt.Fprintf (_buffer, "-- identifier: `%s`\n", _document.Identifier)
@@ -636,15 +1117,27 @@ func DocumentDump (_stream io.Writer, _document *Document, _includeIdentifiers b
 		if _document.Library != "" {
 			fmt.Fprintf (_buffer, "-- library: `%s`\n", _document.Library)
 		}
+		for _, _alias := range _document.Aliases {
+			fmt.Fprintf (_buffer, "-- alias: `%s`\n", _alias)
+		}
 		if _document.Format != "" {
 			fmt.Fprintf (_buffer, "-- format: `%s`\n", _document.Format)
 		}
 		if _document.Path != "" {
 			fmt.Fprintf (_buffer, "-- path: `%s`\n", _document.Path)
 		}
+		if _document.Encrypted {
+			fmt.Fprintf (_buffer, "-- encrypted: `true`\n")
+		}
 		if ! _document.Timestamp.IsZero () {
 			fmt.Fprintf (_buffer, "-- timestamp: `%s`\n", _document.Timestamp.Format ("2006-01-02 15:04:05"))
 		}
+		if ! _document.Created.IsZero () {
+			fmt.Fprintf (_buffer, "-- created: `%s`\n", _document.Created.Format ("2006-01-02 15:04:05"))
+		}
+		if ! _document.Updated.IsZero () {
+			fmt.Fprintf (_buffer, "-- updated: `%s`\n", _document.Updated.Format ("2006-01-02 15:04:05"))
+		}
 		if _document.SourceFingerprint != "" {
 			fmt.Fprintf (_buffer, "-- source fingerprint: `%s`\n", _document.SourceFingerprint)
 		}
@@ -5,12 +5,14 @@ package zscratchpad
 
 import "bufio"
 import "bytes"
+import "fmt"
 import "io"
 import "os"
 import "os/exec"
 import "path"
 import "strings"
 import "sync"
+import "time"
 
 
 
@@ -39,7 +41,12 @@ type editSession struct {
 	documentNew *Document
 	path string
 	pathInLibrary string
+	line uint
 	file *os.File
+	encryptedPath string
+	encryptedArmored bool
+	encryptedFingerprint string
+	plainFolder string
 	command *exec.Cmd
 	synchronous bool
 	terminal bool
@@ -61,6 +68,12 @@ func EditorNew (_globals *Globals, _index *Index) (*Editor, *Error) {
 
 
 func EditorDocumentEdit (_editor *Editor, _library *Library, _document *Document, _synchronous bool) (*Error) {
+	return EditorDocumentEditAt (_editor, _library, _document, 0, _synchronous)
+}
+
+
+// NOTE:  The line is 1-based;  zero means the editor's default position.
+func EditorDocumentEditAt (_editor *Editor, _library *Library, _document *Document, _line uint, _synchronous bool) (*Error) {
 	
 	_globals := _editor.globals
 	
@@ -124,10 +137,17 @@ func EditorDocumentEdit (_editor *Editor, _library *Library, _document *Document
 			library : _library,
 			documentOld : _document,
 			path : _path,
+			line : _line,
 			file : _file,
 			synchronous : _synchronous,
 		}
 	
+	if EncryptionPathIs (_path) {
+		if _error := editSessionDecrypt (_session); _error != nil {
+			return editSessionClose (_session)
+		}
+	}
+	
 	return editSessionStart (_session)
 }
 
@@ -135,6 +155,12 @@ func EditorDocumentEdit (_editor *Editor, _library *Library, _document *Document
 
 
 func EditorDocumentCreate (_editor *Editor, _library *Library, _documentName string, _synchronous bool) (*Error) {
+	return EditorDocumentCreateWithSource (_editor, _library, _documentName, "", _synchronous)
+}
+
+
+// NOTE:  The source is the initial contents of the document (e.g. a header).
+func EditorDocumentCreateWithSource (_editor *Editor, _library *Library, _documentName string, _source string, _synchronous bool) (*Error) {
 	
 	_globals := _editor.globals
 	
@@ -150,6 +176,9 @@ func EditorDocumentCreate (_editor *Editor, _library *Library, _documentName str
 	if _library.CreateExtension != "" {
 		_pathInLibrary = _pathInLibrary + "." + _library.CreateExtension
 	}
+	if _library.EncryptionEnabled {
+		_pathInLibrary = _pathInLibrary + EncryptionPathSuffix
+	}
 	_path := path.Join (_library.CreatePath, _pathInLibrary)
 	
 //	logf ('d', 0x6292b948, "[editor-session]  creating file for `%s`...", _path)
@@ -169,6 +198,19 @@ func EditorDocumentCreate (_editor *Editor, _library *Library, _documentName str
 			synchronous : _synchronous,
 		}
 	
+	if EncryptionPathIs (_path) {
+		if _error := editSessionDecrypt (_session); _error != nil {
+			return editSessionClose (_session)
+		}
+	}
+	
+	if _source != "" {
+		if _error := os.WriteFile (_session.path, []byte (_source), 0o600); _error != nil {
+			_session.error = errorw (0x9f7d5d58, _error)
+			return editSessionClose (_session)
+		}
+	}
+	
 	return editSessionStart (_session)
 }
 
@@ -179,31 +221,48 @@ func editSessionStart (_session *editSession) (*Error) {
 	
 	_globals := _session.globals
 	
+	// NOTE:  All failures go through `editSessionClose`, so that the plain-text of encrypted documents is removed.
+	
 	_command, _terminal, _error := EditorResolveEditCommand (_session.editor)
 	if _error != nil {
-		return _error
+		_session.error = _error
+		return editSessionClose (_session)
+	}
+	
+	_line := _session.line
+	if _line == 0 {
+		_line = 1
 	}
+	_lineToken := fmt.Sprintf ("%d", _line)
 	
 	_argumentPathReplaced := false
 	for _argumentIndex, _argument := range _command.Args {
+		if strings.Contains (_argument, "{{line}}") {
+			_argument = strings.ReplaceAll (_argument, "{{line}}", _lineToken)
+			_command.Args[_argumentIndex] = _argument
+		}
 		if _argument == "{{path}}" {
 			_command.Args[_argumentIndex] = _session.path
 			_argumentPathReplaced = true
 		} else if strings.Contains (_argument, "{{path}}") {
 			_command.Args[_argumentIndex] = strings.ReplaceAll (_argument, "{{path}}", _session.path)
+			_argumentPathReplaced = true
 		}
 	}
 	if !_argumentPathReplaced {
-		return errorw (0xf15a16c4, nil)
+		_session.error = errorw (0xf15a16c4, nil)
+		return editSessionClose (_session)
 	}
 	
 	_session.command = _command
-	_session.terminal = _terminal
 	
-	if _session.terminal {
+	if _terminal {
 		if ! _globals.TerminalMutexTryLock () {
-			return errorw (0x5fcbecde, nil)
+			_session.error = errorw (0x5fcbecde, nil)
+			return editSessionClose (_session)
 		}
+		// NOTE:  Only now, as `editSessionClose` unlocks the mutex for terminal sessions.
+		_session.terminal = true
 	}
 	
 	if !_session.synchronous {
@@ -239,7 +298,17 @@ func editSessionFinalize (_session *editSession) (*Error) {
 	
 //	logf ('d', 0x48f7d5f5, "[editor-session]  reloading document for `%s`...", _session.path)
 	
-	if _document_0, _error := DocumentLoadFromPath (_session.path); _error == nil {
+	if _error := editSessionEncrypt (_session); _error != nil {
+		_session.error = _error
+		return editSessionClose (_session)
+	}
+	
+	_path := _session.path
+	if _session.encryptedPath != "" {
+		_path = _session.encryptedPath
+	}
+	
+	if _document_0, _error := DocumentLoadFromPath (_path, _session.library); _error == nil {
 		_session.documentNew = _document_0
 	} else {
 		_session.error = _error
@@ -308,6 +377,7 @@ func editSessionFinalize (_session *editSession) (*Error) {
 			_session.error = _error
 			return editSessionClose (_session)
 		}
+		IndexDocumentMovesRefresh (_session.editor.index)
 	}
 	
 	return editSessionClose (_session)
@@ -330,6 +400,29 @@ func editSessionClose (_session *editSession) (*Error) {
 		_session.file = nil
 	}
 	
+	if _session.plainFolder != "" {
+		if _error := editSessionEncrypt (_session); _error == nil {
+			if _error := EncryptionRemoveSecurely (_session.path); _error != nil {
+				if _session.error == nil {
+					_session.error = _error
+				} else {
+					logError ('e', _error)
+				}
+			}
+			if _error := os.Remove (_session.plainFolder); _error != nil {
+				logError ('w', errorw (0x1fb7285c, _error))
+			}
+		} else {
+			// NOTE:  The plain-text is the only copy of the edits, thus it is kept (and reported) so that the user can recover it.
+			logError ('e', _error)
+			if _session.error != nil {
+				logError ('e', _session.error)
+			}
+			_session.error = errorf (0x84dff45d, "encrypting the edited document failed;  the plain-text was kept at `%s`!", _session.path)
+		}
+		_session.plainFolder = ""
+	}
+	
 	if _session.terminal {
 		defer _globals.TerminalMutexUnlock ()
 	}
@@ -349,7 +442,133 @@ func editSessionClose (_session *editSession) (*Error) {
 
 
 
+func editSessionDecrypt (_session *editSession) (*Error) {
+	
+	_data, _error := os.ReadFile (_session.path)
+	if _error != nil {
+		_session.error = errorw (0xec2ca0ce, _error)
+		return _session.error
+	}
+	
+	_source := []byte (nil)
+	if len (_data) > 0 {
+		if _source_0, _error := EncryptionDecrypt (_data, _session.library); _error == nil {
+			_source = _source_0
+		} else {
+			_session.error = _error
+			return _session.error
+		}
+	}
+	
+	_folder, _error := os.MkdirTemp (_session.globals.TemporaryDirectory, "z-scratchpad-")
+	if _error != nil {
+		_session.error = errorw (0xb6fc550e, _error)
+		return _session.error
+	}
+	if _error := os.Chmod (_folder, 0o700); _error != nil {
+		os.Remove (_folder)
+		_session.error = errorw (0xf4c5d86b, _error)
+		return _session.error
+	}
+	
+	_path := path.Join (_folder, path.Base (EncryptionPathTrim (_session.path)))
+	
+	_session.encryptedPath = _session.path
+	_session.encryptedArmored = EncryptionArmored (_data)
+	_session.encryptedFingerprint = fingerprintBytes (_source)
+	_session.plainFolder = _folder
+	_session.path = _path
+	
+	if _error := os.WriteFile (_path, _source, 0o600); _error != nil {
+		_session.error = errorw (0xaf15a048, _error)
+		return _session.error
+	}
+	
+	return nil
+}
+
+
+func editSessionEncrypt (_session *editSession) (*Error) {
+	
+	if _session.encryptedPath == "" {
+		return nil
+	}
+	
+	_source := []byte (nil)
+	if _source_0, _error := os.ReadFile (_session.path); _error == nil {
+		_source = _source_0
+	} else {
+		return errorw (0x304fb82d, _error)
+	}
+	
+	_fingerprint := fingerprintBytes (_source)
+	if _fingerprint == _session.encryptedFingerprint {
+		return nil
+	}
+	
+	_data, _error := EncryptionEncrypt (_source, _session.encryptedArmored, _session.library)
+	if _error != nil {
+		return _error
+	}
+	
+	_mode := os.FileMode (0o640)
+	if _stat, _error := os.Stat (_session.encryptedPath); _error == nil {
+		_mode = _stat.Mode () .Perm ()
+	} else if ! os.IsNotExist (_error) {
+		return errorw (0x2c5a4f45, _error)
+	}
+	
+	_pathTemp := fmt.Sprintf ("%s.%d.tmp", _session.encryptedPath, time.Now () .UnixMilli ())
+	if _error := os.WriteFile (_pathTemp, _data, _mode); _error != nil {
+		return errorw (0xd29a3a59, _error)
+	}
+	if _error := os.Rename (_pathTemp, _session.encryptedPath); _error != nil {
+		os.Remove (_pathTemp)
+		return errorw (0x3b73d6b9, _error)
+	}
+	
+	_session.encryptedFingerprint = _fingerprint
+	
+	return nil
+}
+
+
+
+
 func EditorSelect (_editor *Editor, _options []string) ([]string, *Error) {
+	return editorSelect (_editor, _options, "", "", false, nil)
+}
+
+
+// NOTE:  The preview is shown only by the built-in selector (i.e. when no external select tool is available).
+func EditorSelectWithPreview (_editor *Editor, _options []string, _preview func (string) (string)) ([]string, *Error) {
+	return editorSelect (_editor, _options, "", "", false, _preview)
+}
+
+
+// NOTE:  Uses the select command to ask for a free-form value, offering the default as the only option.
+func EditorInput (_editor *Editor, _prompt string, _default string) (string, bool, *Error) {
+	
+	_options := []string (nil)
+	if _default != "" {
+		_options = []string { _default }
+	}
+	
+	_selection, _error := editorSelect (_editor, _options, _prompt, _default, true, nil)
+	if _error != nil {
+		return "", false, _error
+	}
+	
+	// NOTE:  With `fzf` the first line is the query, followed by the (optional) selected option.
+	if len (_selection) == 0 {
+		return "", false, nil
+	}
+	
+	return _selection[0], true, nil
+}
+
+
+func editorSelect (_editor *Editor, _options []string, _prompt string, _default string, _input bool, _preview func (string) (string)) ([]string, *Error) {
 	
 	_globals := _editor.globals
 	
@@ -360,7 +579,7 @@ func EditorSelect (_editor *Editor, _options []string) ([]string, *Error) {
 	_command := (*exec.Cmd) (nil)
 	_okExitCodes := []int (nil)
 	_terminal := false
-	if _command_0, _okExitCodes_0, _terminal_0, _error := EditorResolveSelectCommand (_editor); _error == nil {
+	if _command_0, _okExitCodes_0, _terminal_0, _error := editorResolveSelectCommand (_editor, _prompt, _default, _input); _error == nil {
 		_command = _command_0
 		_okExitCodes = _okExitCodes_0
 		_terminal = _terminal_0
@@ -368,6 +587,14 @@ func EditorSelect (_editor *Editor, _options []string) ([]string, *Error) {
 		return nil, _error
 	}
 	
+	if _command == nil {
+		if ! _globals.TerminalMutexTryLock () {
+			return nil, errorw (0x2d9f164a, nil)
+		}
+		defer _globals.TerminalMutexUnlock ()
+		return terminalSelect (_globals, _options, _prompt, _default, _input, _preview)
+	}
+	
 	if _terminal {
 		if _tmuxConnection, _ := _globals.Environment["TMUX"]; _tmuxConnection != "" {
 			if _tmuxPopup, _error := exec.LookPath ("tmux-popup"); _error == nil {
@@ -613,7 +840,7 @@ func EditorResolveEditCommand (_editor *Editor) (*exec.Cmd, bool, *Error) {
 				case "z-scratchpad--edit", "x-edit" :
 					_arguments = append (_arguments, "{{path}}")
 				case "nano", "vim", "emacs" :
-					_arguments = append (_arguments, "--", "{{path}}")
+					_arguments = append (_arguments, "+{{line}}", "--", "{{path}}")
 				default :
 					_arguments = append (_arguments, "{{path}}")
 			}
@@ -674,10 +901,12 @@ func EditorResolveEditCommand (_editor *Editor) (*exec.Cmd, bool, *Error) {
 			switch _executableName {
 				case "z-scratchpad--edit", "x-edit" :
 					_arguments = append (_arguments, "{{path}}")
-				case "howl", "gvim", "emacs-gtk", "emacs-x11" :
+				case "howl" :
 					_arguments = append (_arguments, "--", "{{path}}")
+				case "gvim", "emacs-gtk", "emacs-x11" :
+					_arguments = append (_arguments, "+{{line}}", "--", "{{path}}")
 				case "sublime_text" :
-					_arguments = append (_arguments, "--new-window", "--wait", "--", "{{path}}")
+					_arguments = append (_arguments, "--new-window", "--wait", "--", "{{path}}:{{line}}")
 				default :
 					_arguments = append (_arguments, "{{path}}")
 			}
@@ -703,7 +932,13 @@ func EditorResolveEditCommand (_editor *Editor) (*exec.Cmd, bool, *Error) {
 
 
 
+// NOTE:  In a terminal, if no select tool is available, no command is returned, meaning the built-in selector should be used.
 func EditorResolveSelectCommand (_editor *Editor) (*exec.Cmd, []int, bool, *Error) {
+	return editorResolveSelectCommand (_editor, "", "", false)
+}
+
+
+func editorResolveSelectCommand (_editor *Editor, _prompt string, _default string, _input bool) (*exec.Cmd, []int, bool, *Error) {
 	
 	_globals := _editor.globals
 	
@@ -723,7 +958,8 @@ func EditorResolveSelectCommand (_editor *Editor) (*exec.Cmd, []int, bool, *Erro
 					_executableName = _executableName_0
 					_argumentsUseCommand = true
 				} else {
-					return nil, nil, false, errorw (0x7aa9de14, _error)
+					// NOTE:  An explicitly configured tool that is missing is an error, only the defaults fall back to the built-in selector.
+					return nil, nil, false, errorf (0xfd0a84fb, "select tool not found `%s`", _executableName_0)
 				}
 			}
 		}
@@ -737,7 +973,8 @@ func EditorResolveSelectCommand (_editor *Editor) (*exec.Cmd, []int, bool, *Erro
 			}
 		}
 		if _executable == "" {
-			return nil, nil, false, errorw (0x10e4bef3, nil)
+			// NOTE:  The built-in selector is used.
+			return nil, nil, true, nil
 		}
 		
 		_arguments := make ([]string, 0, 32)
@@ -750,12 +987,15 @@ func EditorResolveSelectCommand (_editor *Editor) (*exec.Cmd, []int, bool, *Erro
 					// NOP
 				case "fzf" :
 					_arguments = append (_arguments,
-							"--prompt", ": ",
+							"--prompt", _prompt + ": ",
 							"-e", "-x", "-i",
 							"--tiebreak", "begin,length,index",
 							"--no-mouse", "--no-color", "--no-bold", "--no-unicode",
 							"--no-info", "--no-separator",
 						)
+					if _input {
+						_arguments = append (_arguments, "--print-query", "--query", _default)
+					}
 				default :
 					// NOP
 			}
@@ -822,9 +1062,13 @@ func EditorResolveSelectCommand (_editor *Editor) (*exec.Cmd, []int, bool, *Erro
 				case "z-scratchpad--select", "x-select" :
 					// NOP
 				case "rofi" :
-					_arguments = append (_arguments, "-dmenu", "-p", "", "-i", "-no-custom", "-matching-negate-char", "\\x0")
+					if _input {
+						_arguments = append (_arguments, "-dmenu", "-p", _prompt, "-i")
+					} else {
+						_arguments = append (_arguments, "-dmenu", "-p", _prompt, "-i", "-no-custom", "-matching-negate-char", "\\x0")
+					}
 				case "dmenu" :
-					_arguments = append (_arguments, "-p", "", "-l", "16", "-i")
+					_arguments = append (_arguments, "-p", _prompt, "-l", "16", "-i")
 				default :
 					// NOP
 			}
@@ -0,0 +1,222 @@
+package zscratchpad
+
+
+import "bytes"
+import "io"
+import "os"
+import "path/filepath"
+import "strings"
+import "sync"
+
+
+import "filippo.io/age"
+import "filippo.io/age/armor"
+
+
+
+
+const EncryptionPathSuffix = ".age"
+
+
+type encryptionKeys struct {
+	identities []age.Identity
+	recipients []age.Recipient
+}
+
+
+
+
+// NOTE:  Only files with the `.age` extension are encrypted;  `encryption_enabled` just makes new documents encrypted.
+func EncryptionPathIs (_path string) (bool) {
+	return strings.HasSuffix (_path, EncryptionPathSuffix)
+}
+
+func EncryptionPathTrim (_path string) (string) {
+	return strings.TrimSuffix (_path, EncryptionPathSuffix)
+}
+
+
+
+
+func EncryptionDecrypt (_data []byte, _library *Library) ([]byte, *Error) {
+	
+	_keys, _error := encryptionKeysLoad (_library)
+	if _error != nil {
+		return nil, _error
+	}
+	
+	_input := io.Reader (bytes.NewReader (_data))
+	if EncryptionArmored (_data) {
+		_input = armor.NewReader (_input)
+	}
+	
+	_decrypter := io.Reader (nil)
+	if _decrypter_0, _error := age.Decrypt (_input, _keys.identities ...); _error == nil {
+		_decrypter = _decrypter_0
+	} else {
+		return nil, errorw (0x959d1dfa, _error)
+	}
+	
+	_buffer := bytes.NewBuffer (make ([]byte, 0, len (_data)))
+	if _, _error := _buffer.ReadFrom (_decrypter); _error != nil {
+		return nil, errorw (0x8fbf590b, _error)
+	}
+	
+	return _buffer.Bytes (), nil
+}
+
+
+func EncryptionEncrypt (_data []byte, _armored bool, _library *Library) ([]byte, *Error) {
+	
+	_keys, _error := encryptionKeysLoad (_library)
+	if _error != nil {
+		return nil, _error
+	}
+	if len (_keys.recipients) == 0 {
+		return nil, errorf (0x79762fd3, "encryption identity without recipients")
+	}
+	
+	_buffer := bytes.NewBuffer (make ([]byte, 0, len (_data) + 1024))
+	
+	_output := io.Writer (_buffer)
+	_armorer := io.WriteCloser (nil)
+	if _armored {
+		_armorer = armor.NewWriter (_buffer)
+		_output = _armorer
+	}
+	
+	_encrypter := io.WriteCloser (nil)
+	if _encrypter_0, _error := age.Encrypt (_output, _keys.recipients ...); _error == nil {
+		_encrypter = _encrypter_0
+	} else {
+		return nil, errorw (0xe9b2e5bd, _error)
+	}
+	
+	if _, _error := _encrypter.Write (_data); _error != nil {
+		return nil, errorw (0xe0cc90a8, _error)
+	}
+	if _error := _encrypter.Close (); _error != nil {
+		return nil, errorw (0xb2495664, _error)
+	}
+	if _armorer != nil {
+		if _error := _armorer.Close (); _error != nil {
+			return nil, errorw (0x61da5661, _error)
+		}
+	}
+	
+	return _buffer.Bytes (), nil
+}
+
+
+func EncryptionArmored (_data []byte) (bool) {
+	return bytes.HasPrefix (_data, []byte (armor.Header))
+}
+
+
+
+
+// NOTE:  Overwrites the plain-text file with zeros before removing it.
+func EncryptionRemoveSecurely (_path string) (*Error) {
+	
+	if _stat, _error := os.Stat (_path); _error == nil {
+		if _file, _error := os.OpenFile (_path, os.O_WRONLY, 0); _error == nil {
+			_zeros := make ([]byte, 4 * 1024)
+			for _size := _stat.Size (); _size > 0; {
+				_chunk := int64 (len (_zeros))
+				if _chunk > _size {
+					_chunk = _size
+				}
+				if _, _error := _file.Write (_zeros[:_chunk]); _error != nil {
+					_file.Close ()
+					return errorw (0xc99f2908, _error)
+				}
+				_size -= _chunk
+			}
+			if _error := _file.Sync (); _error != nil {
+				_file.Close ()
+				return errorw (0x17984166, _error)
+			}
+			if _error := _file.Close (); _error != nil {
+				return errorw (0xb2d7cfdb, _error)
+			}
+		} else {
+			return errorw (0x90c46409, _error)
+		}
+	} else if os.IsNotExist (_error) {
+		return nil
+	} else {
+		return errorw (0x056f9d9c, _error)
+	}
+	
+	if _error := os.Remove (_path); _error != nil {
+		return errorw (0x3a6a5ca4, _error)
+	}
+	
+	return nil
+}
+
+
+
+
+func encryptionKeysLoad (_library *Library) (*encryptionKeys, *Error) {
+	
+	if (_library == nil) || (_library.EncryptionIdentityPath == "") {
+		return nil, errorf (0xa55b4a4e, "encryption identity not configured")
+	}
+	_path := _library.EncryptionIdentityPath
+	
+	encryptionKeysMutex.Lock ()
+	defer encryptionKeysMutex.Unlock ()
+	
+	if _keys, _exists := encryptionKeysCache[_path]; _exists {
+		return _keys, nil
+	}
+	
+	_identities := []age.Identity (nil)
+	if _data, _error := os.ReadFile (_path); _error == nil {
+		if _identities_0, _error := age.ParseIdentities (bytes.NewReader (_data)); _error == nil {
+			_identities = _identities_0
+		} else {
+			return nil, errorw (0x6bce700d, _error)
+		}
+	} else {
+		return nil, errorw (0x71afeb62, _error)
+	}
+	
+	_recipients := make ([]age.Recipient, 0, len (_identities))
+	for _, _identity := range _identities {
+		if _identity, _ok := _identity.(*age.X25519Identity); _ok {
+			_recipients = append (_recipients, _identity.Recipient ())
+		}
+	}
+	
+	_keys := & encryptionKeys {
+			identities : _identities,
+			recipients : _recipients,
+		}
+	encryptionKeysCache[_path] = _keys
+	
+	return _keys, nil
+}
+
+
+func encryptionIdentityPathResolve (_path string) (string, *Error) {
+	if _path_0, _error := filepath.Abs (_path); _error == nil {
+		_path = _path_0
+	} else {
+		return "", errorw (0x5ebb7625, _error)
+	}
+	if _stat, _error := os.Stat (_path); _error == nil {
+		if ! _stat.Mode () .IsRegular () {
+			return "", errorw (0xa2971280, nil)
+		}
+	} else {
+		return "", errorw (0xad5415dc, _error)
+	}
+	return _path, nil
+}
+
+
+var encryptionKeysCache map[string]*encryptionKeys = make (map[string]*encryptionKeys, 4)
+var encryptionKeysMutex sync.Mutex
+
@@ -20,17 +20,26 @@ type Document struct {
 	Library                   string
 	Path                      string
 	PathInLibrary             string
+	Aliases                   []string
 	Title                     string
 	TitleAlternatives         []string
 	TitleOriginal             string
 	TitleOriginalAlternatives []string
 	SourceFingerprint         string
 	Format                    string
+	Tags                      []string
+	Metadata                  map[string][]string
 	BodyLines                 []string
+	BodyOffset                uint64
 	BodyEmpty                 bool
 	BodyFingerprint           string
 	EditEnabled               bool
+	Encrypted                 bool
 	Timestamp                 time.Time
+	Created                   time.Time
+	Updated                   time.Time
+	CreatedFromHeader         bool
+	UpdatedFromHeader         bool
 }
 */
 
@@ -96,6 +105,41 @@ func (d *Document) Size() (s uint64) {
 		}
 		s += l
 	}
+	{
+		l := uint64(len(d.Aliases))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.Aliases {
+
+			{
+				l := uint64(len(d.Aliases[k0]))
+
+				{
+
+					t := l
+					for t >= 0x80 {
+						t >>= 7
+						s++
+					}
+					s++
+
+				}
+				s += l
+			}
+
+		}
+
+	}
 	{
 		l := uint64(len(d.Title))
 
@@ -226,6 +270,113 @@ func (d *Document) Size() (s uint64) {
 		}
 		s += l
 	}
+	{
+		l := uint64(len(d.Tags))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.Tags {
+
+			{
+				l := uint64(len(d.Tags[k0]))
+
+				{
+
+					t := l
+					for t >= 0x80 {
+						t >>= 7
+						s++
+					}
+					s++
+
+				}
+				s += l
+			}
+
+		}
+
+	}
+	{
+		l := uint64(len(d.Metadata))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0, v0 := range d.Metadata {
+			_ = k0
+			_ = v0
+
+			{
+				l := uint64(len(k0))
+
+				{
+
+					t := l
+					for t >= 0x80 {
+						t >>= 7
+						s++
+					}
+					s++
+
+				}
+				s += l
+			}
+			{
+				l := uint64(len(v0))
+
+				{
+
+					t := l
+					for t >= 0x80 {
+						t >>= 7
+						s++
+					}
+					s++
+
+				}
+
+				for k1 := range v0 {
+
+					{
+						l := uint64(len(v0[k1]))
+
+						{
+
+							t := l
+							for t >= 0x80 {
+								t >>= 7
+								s++
+							}
+							s++
+
+						}
+						s += l
+					}
+
+				}
+
+			}
+
+		}
+
+	}
 	{
 		l := uint64(len(d.BodyLines))
 
@@ -260,6 +411,16 @@ func (d *Document) Size() (s uint64) {
 
 		}
 
+	}
+	{
+
+		t := d.BodyOffset
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
 	}
 	{
 		l := uint64(len(d.BodyFingerprint))
@@ -276,7 +437,7 @@ func (d *Document) Size() (s uint64) {
 		}
 		s += l
 	}
-	s += 17
+	s += 50
 	return
 }
 func (d *Document) Marshal(buf []byte) ([]byte, error) {
@@ -366,6 +527,46 @@ func (d *Document) Marshal(buf []byte) ([]byte, error) {
 		copy(buf[i+0:], d.PathInLibrary)
 		i += l
 	}
+	{
+		l := uint64(len(d.Aliases))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.Aliases {
+
+			{
+				l := uint64(len(d.Aliases[k0]))
+
+				{
+
+					t := uint64(l)
+
+					for t >= 0x80 {
+						buf[i+0] = byte(t) | 0x80
+						t >>= 7
+						i++
+					}
+					buf[i+0] = byte(t)
+					i++
+
+				}
+				copy(buf[i+0:], d.Aliases[k0])
+				i += l
+			}
+
+		}
+	}
 	{
 		l := uint64(len(d.Title))
 
@@ -523,7 +724,7 @@ func (d *Document) Marshal(buf []byte) ([]byte, error) {
 		i += l
 	}
 	{
-		l := uint64(len(d.BodyLines))
+		l := uint64(len(d.Tags))
 
 		{
 
@@ -538,10 +739,10 @@ func (d *Document) Marshal(buf []byte) ([]byte, error) {
 			i++
 
 		}
-		for k0 := range d.BodyLines {
+		for k0 := range d.Tags {
 
 			{
-				l := uint64(len(d.BodyLines[k0]))
+				l := uint64(len(d.Tags[k0]))
 
 				{
 
@@ -556,102 +757,270 @@ func (d *Document) Marshal(buf []byte) ([]byte, error) {
 					i++
 
 				}
-				copy(buf[i+0:], d.BodyLines[k0])
+				copy(buf[i+0:], d.Tags[k0])
 				i += l
 			}
 
 		}
 	}
 	{
-		if d.BodyEmpty {
-			buf[i+0] = 1
-		} else {
-			buf[i+0] = 0
-		}
-	}
-	{
-		l := uint64(len(d.BodyFingerprint))
+		l := uint64(len(d.Metadata))
 
 		{
 
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+1] = byte(t) | 0x80
+				buf[i+0] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+1] = byte(t)
+			buf[i+0] = byte(t)
 			i++
 
 		}
-		copy(buf[i+1:], d.BodyFingerprint)
-		i += l
-	}
-	{
-		if d.EditEnabled {
-			buf[i+1] = 1
-		} else {
-			buf[i+1] = 0
-		}
-	}
-	{
-		b, err := d.Timestamp.MarshalBinary()
-		if err != nil {
-			return nil, err
-		}
-		copy(buf[i+2:], b)
-	}
-	return buf[:i+17], nil
-}
+		for k0, v0 := range d.Metadata {
 
-func (d *Document) Unmarshal(buf []byte) (uint64, error) {
-	i := uint64(0)
+			{
+				l := uint64(len(k0))
 
-	{
-		l := uint64(0)
+				{
 
-		{
+					t := uint64(l)
 
-			bs := uint8(7)
-			t := uint64(buf[i+0] & 0x7F)
-			for buf[i+0]&0x80 == 0x80 {
-				i++
-				t |= uint64(buf[i+0]&0x7F) << bs
-				bs += 7
+					for t >= 0x80 {
+						buf[i+0] = byte(t) | 0x80
+						t >>= 7
+						i++
+					}
+					buf[i+0] = byte(t)
+					i++
+
+				}
+				copy(buf[i+0:], k0)
+				i += l
 			}
-			i++
+			{
+				l := uint64(len(v0))
 
-			l = t
+				{
 
-		}
-		d.Identifier = string(buf[i+0 : i+0+l])
-		i += l
-	}
-	{
-		l := uint64(0)
+					t := uint64(l)
 
-		{
+					for t >= 0x80 {
+						buf[i+0] = byte(t) | 0x80
+						t >>= 7
+						i++
+					}
+					buf[i+0] = byte(t)
+					i++
 
-			bs := uint8(7)
-			t := uint64(buf[i+0] & 0x7F)
-			for buf[i+0]&0x80 == 0x80 {
-				i++
-				t |= uint64(buf[i+0]&0x7F) << bs
-				bs += 7
-			}
-			i++
+				}
+				for k1 := range v0 {
 
-			l = t
+					{
+						l := uint64(len(v0[k1]))
 
-		}
-		d.Library = string(buf[i+0 : i+0+l])
-		i += l
-	}
-	{
-		l := uint64(0)
+						{
 
-		{
+							t := uint64(l)
+
+							for t >= 0x80 {
+								buf[i+0] = byte(t) | 0x80
+								t >>= 7
+								i++
+							}
+							buf[i+0] = byte(t)
+							i++
+
+						}
+						copy(buf[i+0:], v0[k1])
+						i += l
+					}
+
+				}
+			}
+
+		}
+	}
+	{
+		l := uint64(len(d.BodyLines))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.BodyLines {
+
+			{
+				l := uint64(len(d.BodyLines[k0]))
+
+				{
+
+					t := uint64(l)
+
+					for t >= 0x80 {
+						buf[i+0] = byte(t) | 0x80
+						t >>= 7
+						i++
+					}
+					buf[i+0] = byte(t)
+					i++
+
+				}
+				copy(buf[i+0:], d.BodyLines[k0])
+				i += l
+			}
+
+		}
+	}
+	{
+
+		t := uint64(d.BodyOffset)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	{
+		if d.BodyEmpty {
+			buf[i+0] = 1
+		} else {
+			buf[i+0] = 0
+		}
+	}
+	{
+		l := uint64(len(d.BodyFingerprint))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+1] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+1] = byte(t)
+			i++
+
+		}
+		copy(buf[i+1:], d.BodyFingerprint)
+		i += l
+	}
+	{
+		if d.EditEnabled {
+			buf[i+1] = 1
+		} else {
+			buf[i+1] = 0
+		}
+	}
+	{
+		if d.Encrypted {
+			buf[i+2] = 1
+		} else {
+			buf[i+2] = 0
+		}
+	}
+	{
+		b, err := d.Timestamp.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		copy(buf[i+3:], b)
+	}
+	{
+		b, err := d.Created.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		copy(buf[i+18:], b)
+	}
+	{
+		b, err := d.Updated.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		copy(buf[i+33:], b)
+	}
+	{
+		if d.CreatedFromHeader {
+			buf[i+48] = 1
+		} else {
+			buf[i+48] = 0
+		}
+	}
+	{
+		if d.UpdatedFromHeader {
+			buf[i+49] = 1
+		} else {
+			buf[i+49] = 0
+		}
+	}
+	return buf[:i+50], nil
+}
+
+func (d *Document) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Identifier = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Library = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
 
 			bs := uint8(7)
 			t := uint64(buf[i+0] & 0x7F)
@@ -691,6 +1060,53 @@ func (d *Document) Unmarshal(buf []byte) (uint64, error) {
 	{
 		l := uint64(0)
 
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.Aliases)) >= l {
+			d.Aliases = d.Aliases[:l]
+		} else {
+			d.Aliases = make([]string, l)
+		}
+		for k0 := range d.Aliases {
+
+			{
+				l := uint64(0)
+
+				{
+
+					bs := uint8(7)
+					t := uint64(buf[i+0] & 0x7F)
+					for buf[i+0]&0x80 == 0x80 {
+						i++
+						t |= uint64(buf[i+0]&0x7F) << bs
+						bs += 7
+					}
+					i++
+
+					l = t
+
+				}
+				d.Aliases[k0] = string(buf[i+0 : i+0+l])
+				i += l
+			}
+
+		}
+	}
+	{
+		l := uint64(0)
+
 		{
 
 			bs := uint8(7)
@@ -879,12 +1295,12 @@ func (d *Document) Unmarshal(buf []byte) (uint64, error) {
 			l = t
 
 		}
-		if uint64(cap(d.BodyLines)) >= l {
-			d.BodyLines = d.BodyLines[:l]
+		if uint64(cap(d.Tags)) >= l {
+			d.Tags = d.Tags[:l]
 		} else {
-			d.BodyLines = make([]string, l)
+			d.Tags = make([]string, l)
 		}
-		for k0 := range d.BodyLines {
+		for k0 := range d.Tags {
 
 			{
 				l := uint64(0)
@@ -903,25 +1319,22 @@ func (d *Document) Unmarshal(buf []byte) (uint64, error) {
 					l = t
 
 				}
-				d.BodyLines[k0] = string(buf[i+0 : i+0+l])
+				d.Tags[k0] = string(buf[i+0 : i+0+l])
 				i += l
 			}
 
 		}
 	}
-	{
-		d.BodyEmpty = buf[i+0] == 1
-	}
 	{
 		l := uint64(0)
 
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+1] & 0x7F)
-			for buf[i+1]&0x80 == 0x80 {
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+1]&0x7F) << bs
+				t |= uint64(buf[i+0]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -929,40 +1342,215 @@ func (d *Document) Unmarshal(buf []byte) (uint64, error) {
 			l = t
 
 		}
-		d.BodyFingerprint = string(buf[i+1 : i+1+l])
-		i += l
-	}
-	{
-		d.EditEnabled = buf[i+1] == 1
-	}
-	{
-		d.Timestamp.UnmarshalBinary(buf[i+2 : i+2+15])
-	}
-	return i + 17, nil
-}
+		d.Metadata = make(map[string][]string, l)
+		for n0 := uint64(0); n0 < l; n0++ {
+			var k0 string
+			var v0 []string
 
-/*
-type Library struct {
-	Identifier                     string
-	Name                           string
-	Paths                          []string
-	Disabled                       bool
-	EditEnabled                    bool
-	CreateEnabled                  bool
-	CreatePath                     string
-	CreateNameTimestampLength      uint8
-	CreateNameRandomLength         uint8
-	CreateExtension                string
-	SnapshotEnabled                bool
-	SnapshotExtension              string
-	IncludeGlobPatterns            []string
-	ExcludeGlobPatterns            []string
-	IncludeRegexPatterns           []string
-	ExcludeRegexPatterns           []string
-	UseTitlePrefix                 string
-	UseLibraryAsIdentifierPrefix   bool
-	UsePathInLibraryAsIdentifier   bool
-	UseFileNameAsIdentifier        bool
+			{
+				l := uint64(0)
+
+				{
+
+					bs := uint8(7)
+					t := uint64(buf[i+0] & 0x7F)
+					for buf[i+0]&0x80 == 0x80 {
+						i++
+						t |= uint64(buf[i+0]&0x7F) << bs
+						bs += 7
+					}
+					i++
+
+					l = t
+
+				}
+				k0 = string(buf[i+0 : i+0+l])
+				i += l
+			}
+			{
+				l := uint64(0)
+
+				{
+
+					bs := uint8(7)
+					t := uint64(buf[i+0] & 0x7F)
+					for buf[i+0]&0x80 == 0x80 {
+						i++
+						t |= uint64(buf[i+0]&0x7F) << bs
+						bs += 7
+					}
+					i++
+
+					l = t
+
+				}
+				if uint64(cap(v0)) >= l {
+					v0 = v0[:l]
+				} else {
+					v0 = make([]string, l)
+				}
+				for k1 := range v0 {
+
+					{
+						l := uint64(0)
+
+						{
+
+							bs := uint8(7)
+							t := uint64(buf[i+0] & 0x7F)
+							for buf[i+0]&0x80 == 0x80 {
+								i++
+								t |= uint64(buf[i+0]&0x7F) << bs
+								bs += 7
+							}
+							i++
+
+							l = t
+
+						}
+						v0[k1] = string(buf[i+0 : i+0+l])
+						i += l
+					}
+
+				}
+			}
+			d.Metadata[k0] = v0
+
+		}
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.BodyLines)) >= l {
+			d.BodyLines = d.BodyLines[:l]
+		} else {
+			d.BodyLines = make([]string, l)
+		}
+		for k0 := range d.BodyLines {
+
+			{
+				l := uint64(0)
+
+				{
+
+					bs := uint8(7)
+					t := uint64(buf[i+0] & 0x7F)
+					for buf[i+0]&0x80 == 0x80 {
+						i++
+						t |= uint64(buf[i+0]&0x7F) << bs
+						bs += 7
+					}
+					i++
+
+					l = t
+
+				}
+				d.BodyLines[k0] = string(buf[i+0 : i+0+l])
+				i += l
+			}
+
+		}
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.BodyOffset = t
+
+	}
+	{
+		d.BodyEmpty = buf[i+0] == 1
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+1] & 0x7F)
+			for buf[i+1]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+1]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.BodyFingerprint = string(buf[i+1 : i+1+l])
+		i += l
+	}
+	{
+		d.EditEnabled = buf[i+1] == 1
+	}
+	{
+		d.Encrypted = buf[i+2] == 1
+	}
+	{
+		d.Timestamp.UnmarshalBinary(buf[i+3 : i+3+15])
+	}
+	{
+		d.Created.UnmarshalBinary(buf[i+18 : i+18+15])
+	}
+	{
+		d.Updated.UnmarshalBinary(buf[i+33 : i+33+15])
+	}
+	{
+		d.CreatedFromHeader = buf[i+48] == 1
+	}
+	{
+		d.UpdatedFromHeader = buf[i+49] == 1
+	}
+	return i + 50, nil
+}
+
+/*
+type Library struct {
+	Identifier                     string
+	Name                           string
+	Paths                          []string
+	Disabled                       bool
+	EditEnabled                    bool
+	CreateEnabled                  bool
+	CreatePath                     string
+	CreateNameTimestampLength      uint8
+	CreateNameRandomLength         uint8
+	CreateExtension                string
+	SnapshotEnabled                bool
+	SnapshotExtension              string
+	EncryptionEnabled              bool
+	EncryptionIdentityPath         string
+	EncryptionIndexEnabled         bool
+	IncludeGlobPatterns            []string
+	ExcludeGlobPatterns            []string
+	IncludeRegexPatterns           []string
+	ExcludeRegexPatterns           []string
+	UseTitlePrefix                 string
+	UseLibraryAsIdentifierPrefix   bool
+	UsePathInLibraryAsIdentifier   bool
+	UseFileNameAsIdentifier        bool
 	UsePathFingerprintAsIdentifier bool
 	UseFileExtensionAsFormat       bool
 }
@@ -1080,6 +1668,21 @@ func (d *Library) Size() (s uint64) {
 		}
 		s += l
 	}
+	{
+		l := uint64(len(d.EncryptionIdentityPath))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
 	{
 		l := uint64(len(d.IncludeGlobPatterns))
 
@@ -1235,7 +1838,7 @@ func (d *Library) Size() (s uint64) {
 		}
 		s += l
 	}
-	s += 11
+	s += 13
 	return
 }
 func (d *Library) Marshal(buf []byte) ([]byte, error) {
@@ -1422,6 +2025,39 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 		copy(buf[i+6:], d.SnapshotExtension)
 		i += l
 	}
+	{
+		if d.EncryptionEnabled {
+			buf[i+6] = 1
+		} else {
+			buf[i+6] = 0
+		}
+	}
+	{
+		l := uint64(len(d.EncryptionIdentityPath))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+7] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+7] = byte(t)
+			i++
+
+		}
+		copy(buf[i+7:], d.EncryptionIdentityPath)
+		i += l
+	}
+	{
+		if d.EncryptionIndexEnabled {
+			buf[i+7] = 1
+		} else {
+			buf[i+7] = 0
+		}
+	}
 	{
 		l := uint64(len(d.IncludeGlobPatterns))
 
@@ -1430,11 +2066,11 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+6] = byte(t) | 0x80
+				buf[i+8] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+6] = byte(t)
+			buf[i+8] = byte(t)
 			i++
 
 		}
@@ -1448,15 +2084,15 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 					t := uint64(l)
 
 					for t >= 0x80 {
-						buf[i+6] = byte(t) | 0x80
+						buf[i+8] = byte(t) | 0x80
 						t >>= 7
 						i++
 					}
-					buf[i+6] = byte(t)
+					buf[i+8] = byte(t)
 					i++
 
 				}
-				copy(buf[i+6:], d.IncludeGlobPatterns[k0])
+				copy(buf[i+8:], d.IncludeGlobPatterns[k0])
 				i += l
 			}
 
@@ -1470,11 +2106,11 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+6] = byte(t) | 0x80
+				buf[i+8] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+6] = byte(t)
+			buf[i+8] = byte(t)
 			i++
 
 		}
@@ -1488,15 +2124,15 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 					t := uint64(l)
 
 					for t >= 0x80 {
-						buf[i+6] = byte(t) | 0x80
+						buf[i+8] = byte(t) | 0x80
 						t >>= 7
 						i++
 					}
-					buf[i+6] = byte(t)
+					buf[i+8] = byte(t)
 					i++
 
 				}
-				copy(buf[i+6:], d.ExcludeGlobPatterns[k0])
+				copy(buf[i+8:], d.ExcludeGlobPatterns[k0])
 				i += l
 			}
 
@@ -1510,11 +2146,11 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+6] = byte(t) | 0x80
+				buf[i+8] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+6] = byte(t)
+			buf[i+8] = byte(t)
 			i++
 
 		}
@@ -1528,15 +2164,15 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 					t := uint64(l)
 
 					for t >= 0x80 {
-						buf[i+6] = byte(t) | 0x80
+						buf[i+8] = byte(t) | 0x80
 						t >>= 7
 						i++
 					}
-					buf[i+6] = byte(t)
+					buf[i+8] = byte(t)
 					i++
 
 				}
-				copy(buf[i+6:], d.IncludeRegexPatterns[k0])
+				copy(buf[i+8:], d.IncludeRegexPatterns[k0])
 				i += l
 			}
 
@@ -1550,11 +2186,11 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+6] = byte(t) | 0x80
+				buf[i+8] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+6] = byte(t)
+			buf[i+8] = byte(t)
 			i++
 
 		}
@@ -1568,15 +2204,15 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 					t := uint64(l)
 
 					for t >= 0x80 {
-						buf[i+6] = byte(t) | 0x80
+						buf[i+8] = byte(t) | 0x80
 						t >>= 7
 						i++
 					}
-					buf[i+6] = byte(t)
+					buf[i+8] = byte(t)
 					i++
 
 				}
-				copy(buf[i+6:], d.ExcludeRegexPatterns[k0])
+				copy(buf[i+8:], d.ExcludeRegexPatterns[k0])
 				i += l
 			}
 
@@ -1590,53 +2226,53 @@ func (d *Library) Marshal(buf []byte) ([]byte, error) {
 			t := uint64(l)
 
 			for t >= 0x80 {
-				buf[i+6] = byte(t) | 0x80
+				buf[i+8] = byte(t) | 0x80
 				t >>= 7
 				i++
 			}
-			buf[i+6] = byte(t)
+			buf[i+8] = byte(t)
 			i++
 
 		}
-		copy(buf[i+6:], d.UseTitlePrefix)
+		copy(buf[i+8:], d.UseTitlePrefix)
 		i += l
 	}
 	{
 		if d.UseLibraryAsIdentifierPrefix {
-			buf[i+6] = 1
+			buf[i+8] = 1
 		} else {
-			buf[i+6] = 0
+			buf[i+8] = 0
 		}
 	}
 	{
 		if d.UsePathInLibraryAsIdentifier {
-			buf[i+7] = 1
+			buf[i+9] = 1
 		} else {
-			buf[i+7] = 0
+			buf[i+9] = 0
 		}
 	}
 	{
 		if d.UseFileNameAsIdentifier {
-			buf[i+8] = 1
+			buf[i+10] = 1
 		} else {
-			buf[i+8] = 0
+			buf[i+10] = 0
 		}
 	}
 	{
 		if d.UsePathFingerprintAsIdentifier {
-			buf[i+9] = 1
+			buf[i+11] = 1
 		} else {
-			buf[i+9] = 0
+			buf[i+11] = 0
 		}
 	}
 	{
 		if d.UseFileExtensionAsFormat {
-			buf[i+10] = 1
+			buf[i+12] = 1
 		} else {
-			buf[i+10] = 0
+			buf[i+12] = 0
 		}
 	}
-	return buf[:i+11], nil
+	return buf[:i+13], nil
 }
 
 func (d *Library) Unmarshal(buf []byte) (uint64, error) {
@@ -1811,16 +2447,19 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 		d.SnapshotExtension = string(buf[i+6 : i+6+l])
 		i += l
 	}
+	{
+		d.EncryptionEnabled = buf[i+6] == 1
+	}
 	{
 		l := uint64(0)
 
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+6] & 0x7F)
-			for buf[i+6]&0x80 == 0x80 {
+			t := uint64(buf[i+7] & 0x7F)
+			for buf[i+7]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+6]&0x7F) << bs
+				t |= uint64(buf[i+7]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -1828,23 +2467,46 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 			l = t
 
 		}
-		if uint64(cap(d.IncludeGlobPatterns)) >= l {
-			d.IncludeGlobPatterns = d.IncludeGlobPatterns[:l]
-		} else {
-			d.IncludeGlobPatterns = make([]string, l)
-		}
-		for k0 := range d.IncludeGlobPatterns {
+		d.EncryptionIdentityPath = string(buf[i+7 : i+7+l])
+		i += l
+	}
+	{
+		d.EncryptionIndexEnabled = buf[i+7] == 1
+	}
+	{
+		l := uint64(0)
 
-			{
-				l := uint64(0)
+		{
 
-				{
+			bs := uint8(7)
+			t := uint64(buf[i+8] & 0x7F)
+			for buf[i+8]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+8]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.IncludeGlobPatterns)) >= l {
+			d.IncludeGlobPatterns = d.IncludeGlobPatterns[:l]
+		} else {
+			d.IncludeGlobPatterns = make([]string, l)
+		}
+		for k0 := range d.IncludeGlobPatterns {
+
+			{
+				l := uint64(0)
+
+				{
 
 					bs := uint8(7)
-					t := uint64(buf[i+6] & 0x7F)
-					for buf[i+6]&0x80 == 0x80 {
+					t := uint64(buf[i+8] & 0x7F)
+					for buf[i+8]&0x80 == 0x80 {
 						i++
-						t |= uint64(buf[i+6]&0x7F) << bs
+						t |= uint64(buf[i+8]&0x7F) << bs
 						bs += 7
 					}
 					i++
@@ -1852,7 +2514,7 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 					l = t
 
 				}
-				d.IncludeGlobPatterns[k0] = string(buf[i+6 : i+6+l])
+				d.IncludeGlobPatterns[k0] = string(buf[i+8 : i+8+l])
 				i += l
 			}
 
@@ -1864,10 +2526,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+6] & 0x7F)
-			for buf[i+6]&0x80 == 0x80 {
+			t := uint64(buf[i+8] & 0x7F)
+			for buf[i+8]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+6]&0x7F) << bs
+				t |= uint64(buf[i+8]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -1888,10 +2550,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 				{
 
 					bs := uint8(7)
-					t := uint64(buf[i+6] & 0x7F)
-					for buf[i+6]&0x80 == 0x80 {
+					t := uint64(buf[i+8] & 0x7F)
+					for buf[i+8]&0x80 == 0x80 {
 						i++
-						t |= uint64(buf[i+6]&0x7F) << bs
+						t |= uint64(buf[i+8]&0x7F) << bs
 						bs += 7
 					}
 					i++
@@ -1899,7 +2561,7 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 					l = t
 
 				}
-				d.ExcludeGlobPatterns[k0] = string(buf[i+6 : i+6+l])
+				d.ExcludeGlobPatterns[k0] = string(buf[i+8 : i+8+l])
 				i += l
 			}
 
@@ -1911,10 +2573,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+6] & 0x7F)
-			for buf[i+6]&0x80 == 0x80 {
+			t := uint64(buf[i+8] & 0x7F)
+			for buf[i+8]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+6]&0x7F) << bs
+				t |= uint64(buf[i+8]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -1935,10 +2597,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 				{
 
 					bs := uint8(7)
-					t := uint64(buf[i+6] & 0x7F)
-					for buf[i+6]&0x80 == 0x80 {
+					t := uint64(buf[i+8] & 0x7F)
+					for buf[i+8]&0x80 == 0x80 {
 						i++
-						t |= uint64(buf[i+6]&0x7F) << bs
+						t |= uint64(buf[i+8]&0x7F) << bs
 						bs += 7
 					}
 					i++
@@ -1946,7 +2608,7 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 					l = t
 
 				}
-				d.IncludeRegexPatterns[k0] = string(buf[i+6 : i+6+l])
+				d.IncludeRegexPatterns[k0] = string(buf[i+8 : i+8+l])
 				i += l
 			}
 
@@ -1958,10 +2620,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+6] & 0x7F)
-			for buf[i+6]&0x80 == 0x80 {
+			t := uint64(buf[i+8] & 0x7F)
+			for buf[i+8]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+6]&0x7F) << bs
+				t |= uint64(buf[i+8]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -1982,10 +2644,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 				{
 
 					bs := uint8(7)
-					t := uint64(buf[i+6] & 0x7F)
-					for buf[i+6]&0x80 == 0x80 {
+					t := uint64(buf[i+8] & 0x7F)
+					for buf[i+8]&0x80 == 0x80 {
 						i++
-						t |= uint64(buf[i+6]&0x7F) << bs
+						t |= uint64(buf[i+8]&0x7F) << bs
 						bs += 7
 					}
 					i++
@@ -1993,7 +2655,7 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 					l = t
 
 				}
-				d.ExcludeRegexPatterns[k0] = string(buf[i+6 : i+6+l])
+				d.ExcludeRegexPatterns[k0] = string(buf[i+8 : i+8+l])
 				i += l
 			}
 
@@ -2005,10 +2667,10 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 		{
 
 			bs := uint8(7)
-			t := uint64(buf[i+6] & 0x7F)
-			for buf[i+6]&0x80 == 0x80 {
+			t := uint64(buf[i+8] & 0x7F)
+			for buf[i+8]&0x80 == 0x80 {
 				i++
-				t |= uint64(buf[i+6]&0x7F) << bs
+				t |= uint64(buf[i+8]&0x7F) << bs
 				bs += 7
 			}
 			i++
@@ -2016,32 +2678,36 @@ func (d *Library) Unmarshal(buf []byte) (uint64, error) {
 			l = t
 
 		}
-		d.UseTitlePrefix = string(buf[i+6 : i+6+l])
+		d.UseTitlePrefix = string(buf[i+8 : i+8+l])
 		i += l
 	}
 	{
-		d.UseLibraryAsIdentifierPrefix = buf[i+6] == 1
+		d.UseLibraryAsIdentifierPrefix = buf[i+8] == 1
 	}
 	{
-		d.UsePathInLibraryAsIdentifier = buf[i+7] == 1
+		d.UsePathInLibraryAsIdentifier = buf[i+9] == 1
 	}
 	{
-		d.UseFileNameAsIdentifier = buf[i+8] == 1
+		d.UseFileNameAsIdentifier = buf[i+10] == 1
 	}
 	{
-		d.UsePathFingerprintAsIdentifier = buf[i+9] == 1
+		d.UsePathFingerprintAsIdentifier = buf[i+11] == 1
 	}
 	{
-		d.UseFileExtensionAsFormat = buf[i+10] == 1
+		d.UseFileExtensionAsFormat = buf[i+12] == 1
 	}
-	return i + 11, nil
+	return i + 13, nil
 }
 
 /*
 type IndexGob struct {
-	Documents        []*Document
-	Libraries        []*Library
-	LibraryDocuments []IndexLibraryDocumentsGob
+	Documents            []*Document
+	Libraries            []*Library
+	LibraryDocuments     []IndexLibraryDocumentsGob
+	DocumentMoves        []IndexDocumentMoveGob
+	DocumentFingerprints []IndexDocumentFingerprintGob
+	SearchDocuments      []IndexSearchDocumentGob
+	SearchTerms          []IndexSearchTermGob
 }
 */
 
@@ -2131,6 +2797,98 @@ func (d *IndexGob) Size() (s uint64) {
 
 		}
 
+	}
+	{
+		l := uint64(len(d.DocumentMoves))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.DocumentMoves {
+
+			{
+				s += d.DocumentMoves[k0].Size()
+			}
+
+		}
+
+	}
+	{
+		l := uint64(len(d.DocumentFingerprints))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.DocumentFingerprints {
+
+			{
+				s += d.DocumentFingerprints[k0].Size()
+			}
+
+		}
+
+	}
+	{
+		l := uint64(len(d.SearchDocuments))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.SearchDocuments {
+
+			{
+				s += d.SearchDocuments[k0].Size()
+			}
+
+		}
+
+	}
+	{
+		l := uint64(len(d.SearchTerms))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.SearchTerms {
+
+			{
+				s += d.SearchTerms[k0].Size()
+			}
+
+		}
+
 	}
 	return
 }
@@ -2251,6 +3009,118 @@ func (d *IndexGob) Marshal(buf []byte) ([]byte, error) {
 
 		}
 	}
+	{
+		l := uint64(len(d.DocumentMoves))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.DocumentMoves {
+
+			{
+				nbuf, err := d.DocumentMoves[k0].Marshal(buf[i+0:])
+				if err != nil {
+					return nil, err
+				}
+				i += uint64(len(nbuf))
+			}
+
+		}
+	}
+	{
+		l := uint64(len(d.DocumentFingerprints))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.DocumentFingerprints {
+
+			{
+				nbuf, err := d.DocumentFingerprints[k0].Marshal(buf[i+0:])
+				if err != nil {
+					return nil, err
+				}
+				i += uint64(len(nbuf))
+			}
+
+		}
+	}
+	{
+		l := uint64(len(d.SearchDocuments))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.SearchDocuments {
+
+			{
+				nbuf, err := d.SearchDocuments[k0].Marshal(buf[i+0:])
+				if err != nil {
+					return nil, err
+				}
+				i += uint64(len(nbuf))
+			}
+
+		}
+	}
+	{
+		l := uint64(len(d.SearchTerms))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.SearchTerms {
+
+			{
+				nbuf, err := d.SearchTerms[k0].Marshal(buf[i+0:])
+				if err != nil {
+					return nil, err
+				}
+				i += uint64(len(nbuf))
+			}
+
+		}
+	}
 	return buf[:i+0], nil
 }
 
@@ -2385,42 +3255,178 @@ func (d *IndexGob) Unmarshal(buf []byte) (uint64, error) {
 
 		}
 	}
-	return i + 0, nil
-}
+	{
+		l := uint64(0)
 
-/*
-type IndexLibraryDocumentsGob struct {
-	Library   string
-	Documents []string
-}
-*/
+		{
 
-func (d *IndexLibraryDocumentsGob) Size() (s uint64) {
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
 
-	{
-		l := uint64(len(d.Library))
+			l = t
 
-		{
+		}
+		if uint64(cap(d.DocumentMoves)) >= l {
+			d.DocumentMoves = d.DocumentMoves[:l]
+		} else {
+			d.DocumentMoves = make([]IndexDocumentMoveGob, l)
+		}
+		for k0 := range d.DocumentMoves {
 
-			t := l
-			for t >= 0x80 {
-				t >>= 7
-				s++
+			{
+				ni, err := d.DocumentMoves[k0].Unmarshal(buf[i+0:])
+				if err != nil {
+					return 0, err
+				}
+				i += ni
 			}
-			s++
 
 		}
-		s += l
 	}
 	{
-		l := uint64(len(d.Documents))
+		l := uint64(0)
 
 		{
 
-			t := l
-			for t >= 0x80 {
-				t >>= 7
-				s++
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.DocumentFingerprints)) >= l {
+			d.DocumentFingerprints = d.DocumentFingerprints[:l]
+		} else {
+			d.DocumentFingerprints = make([]IndexDocumentFingerprintGob, l)
+		}
+		for k0 := range d.DocumentFingerprints {
+
+			{
+				ni, err := d.DocumentFingerprints[k0].Unmarshal(buf[i+0:])
+				if err != nil {
+					return 0, err
+				}
+				i += ni
+			}
+
+		}
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.SearchDocuments)) >= l {
+			d.SearchDocuments = d.SearchDocuments[:l]
+		} else {
+			d.SearchDocuments = make([]IndexSearchDocumentGob, l)
+		}
+		for k0 := range d.SearchDocuments {
+
+			{
+				ni, err := d.SearchDocuments[k0].Unmarshal(buf[i+0:])
+				if err != nil {
+					return 0, err
+				}
+				i += ni
+			}
+
+		}
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.SearchTerms)) >= l {
+			d.SearchTerms = d.SearchTerms[:l]
+		} else {
+			d.SearchTerms = make([]IndexSearchTermGob, l)
+		}
+		for k0 := range d.SearchTerms {
+
+			{
+				ni, err := d.SearchTerms[k0].Unmarshal(buf[i+0:])
+				if err != nil {
+					return 0, err
+				}
+				i += ni
+			}
+
+		}
+	}
+	return i + 0, nil
+}
+
+/*
+type IndexLibraryDocumentsGob struct {
+	Library   string
+	Documents []string
+}
+*/
+
+func (d *IndexLibraryDocumentsGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Library))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.Documents))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
 			}
 			s++
 
@@ -2594,3 +3600,907 @@ func (d *IndexLibraryDocumentsGob) Unmarshal(buf []byte) (uint64, error) {
 	}
 	return i + 0, nil
 }
+
+/*
+type IndexDocumentMoveGob struct {
+	Alias      string
+	Identifier string
+}
+*/
+
+func (d *IndexDocumentMoveGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Alias))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	return
+}
+func (d *IndexDocumentMoveGob) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Alias))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Alias)
+		i += l
+	}
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Identifier)
+		i += l
+	}
+	return buf[:i+0], nil
+}
+
+func (d *IndexDocumentMoveGob) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Alias = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Identifier = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	return i + 0, nil
+}
+
+/*
+type IndexDocumentFingerprintGob struct {
+	Fingerprint string
+	Identifier  string
+}
+*/
+
+func (d *IndexDocumentFingerprintGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Fingerprint))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	return
+}
+func (d *IndexDocumentFingerprintGob) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Fingerprint))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Fingerprint)
+		i += l
+	}
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Identifier)
+		i += l
+	}
+	return buf[:i+0], nil
+}
+
+func (d *IndexDocumentFingerprintGob) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Fingerprint = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Identifier = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	return i + 0, nil
+}
+
+/*
+type IndexSearchDocumentGob struct {
+	Identifier  string
+	TitleLength uint32
+	BodyLength  uint32
+}
+*/
+
+func (d *IndexSearchDocumentGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+
+		t := d.TitleLength
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	{
+
+		t := d.BodyLength
+		for t >= 0x80 {
+			t >>= 7
+			s++
+		}
+		s++
+
+	}
+	return
+}
+func (d *IndexSearchDocumentGob) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Identifier))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Identifier)
+		i += l
+	}
+	{
+
+		t := uint64(d.TitleLength)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	{
+
+		t := uint64(d.BodyLength)
+
+		for t >= 0x80 {
+			buf[i+0] = byte(t) | 0x80
+			t >>= 7
+			i++
+		}
+		buf[i+0] = byte(t)
+		i++
+
+	}
+	return buf[:i+0], nil
+}
+
+func (d *IndexSearchDocumentGob) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Identifier = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.TitleLength = uint32(t)
+
+	}
+	{
+
+		bs := uint8(7)
+		t := uint64(buf[i+0] & 0x7F)
+		for buf[i+0]&0x80 == 0x80 {
+			i++
+			t |= uint64(buf[i+0]&0x7F) << bs
+			bs += 7
+		}
+		i++
+
+		d.BodyLength = uint32(t)
+
+	}
+	return i + 0, nil
+}
+
+/*
+type IndexSearchTermGob struct {
+	Term     string
+	Postings []IndexSearchPostingGob
+}
+*/
+
+func (d *IndexSearchTermGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Term))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.Postings))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.Postings {
+
+			{
+				s += d.Postings[k0].Size()
+			}
+
+		}
+
+	}
+	return
+}
+func (d *IndexSearchTermGob) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Term))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Term)
+		i += l
+	}
+	{
+		l := uint64(len(d.Postings))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.Postings {
+
+			{
+				nbuf, err := d.Postings[k0].Marshal(buf[i+0:])
+				if err != nil {
+					return nil, err
+				}
+				i += uint64(len(nbuf))
+			}
+
+		}
+	}
+	return buf[:i+0], nil
+}
+
+func (d *IndexSearchTermGob) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Term = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.Postings)) >= l {
+			d.Postings = d.Postings[:l]
+		} else {
+			d.Postings = make([]IndexSearchPostingGob, l)
+		}
+		for k0 := range d.Postings {
+
+			{
+				ni, err := d.Postings[k0].Unmarshal(buf[i+0:])
+				if err != nil {
+					return 0, err
+				}
+				i += ni
+			}
+
+		}
+	}
+	return i + 0, nil
+}
+
+/*
+type IndexSearchPostingGob struct {
+	Document       string
+	TitlePositions []uint32
+	BodyPositions  []uint32
+}
+*/
+
+func (d *IndexSearchPostingGob) Size() (s uint64) {
+
+	{
+		l := uint64(len(d.Document))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+		s += l
+	}
+	{
+		l := uint64(len(d.TitlePositions))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.TitlePositions {
+
+			{
+
+				t := d.TitlePositions[k0]
+				for t >= 0x80 {
+					t >>= 7
+					s++
+				}
+				s++
+
+			}
+
+		}
+
+	}
+	{
+		l := uint64(len(d.BodyPositions))
+
+		{
+
+			t := l
+			for t >= 0x80 {
+				t >>= 7
+				s++
+			}
+			s++
+
+		}
+
+		for k0 := range d.BodyPositions {
+
+			{
+
+				t := d.BodyPositions[k0]
+				for t >= 0x80 {
+					t >>= 7
+					s++
+				}
+				s++
+
+			}
+
+		}
+
+	}
+	return
+}
+func (d *IndexSearchPostingGob) Marshal(buf []byte) ([]byte, error) {
+	size := d.Size()
+	{
+		if uint64(cap(buf)) >= size {
+			buf = buf[:size]
+		} else {
+			buf = make([]byte, size)
+		}
+	}
+	i := uint64(0)
+
+	{
+		l := uint64(len(d.Document))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		copy(buf[i+0:], d.Document)
+		i += l
+	}
+	{
+		l := uint64(len(d.TitlePositions))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.TitlePositions {
+
+			{
+
+				t := uint64(d.TitlePositions[k0])
+
+				for t >= 0x80 {
+					buf[i+0] = byte(t) | 0x80
+					t >>= 7
+					i++
+				}
+				buf[i+0] = byte(t)
+				i++
+
+			}
+
+		}
+	}
+	{
+		l := uint64(len(d.BodyPositions))
+
+		{
+
+			t := uint64(l)
+
+			for t >= 0x80 {
+				buf[i+0] = byte(t) | 0x80
+				t >>= 7
+				i++
+			}
+			buf[i+0] = byte(t)
+			i++
+
+		}
+		for k0 := range d.BodyPositions {
+
+			{
+
+				t := uint64(d.BodyPositions[k0])
+
+				for t >= 0x80 {
+					buf[i+0] = byte(t) | 0x80
+					t >>= 7
+					i++
+				}
+				buf[i+0] = byte(t)
+				i++
+
+			}
+
+		}
+	}
+	return buf[:i+0], nil
+}
+
+func (d *IndexSearchPostingGob) Unmarshal(buf []byte) (uint64, error) {
+	i := uint64(0)
+
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		d.Document = string(buf[i+0 : i+0+l])
+		i += l
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.TitlePositions)) >= l {
+			d.TitlePositions = d.TitlePositions[:l]
+		} else {
+			d.TitlePositions = make([]uint32, l)
+		}
+		for k0 := range d.TitlePositions {
+
+			{
+
+				bs := uint8(7)
+				t := uint64(buf[i+0] & 0x7F)
+				for buf[i+0]&0x80 == 0x80 {
+					i++
+					t |= uint64(buf[i+0]&0x7F) << bs
+					bs += 7
+				}
+				i++
+
+				d.TitlePositions[k0] = uint32(t)
+
+			}
+
+		}
+	}
+	{
+		l := uint64(0)
+
+		{
+
+			bs := uint8(7)
+			t := uint64(buf[i+0] & 0x7F)
+			for buf[i+0]&0x80 == 0x80 {
+				i++
+				t |= uint64(buf[i+0]&0x7F) << bs
+				bs += 7
+			}
+			i++
+
+			l = t
+
+		}
+		if uint64(cap(d.BodyPositions)) >= l {
+			d.BodyPositions = d.BodyPositions[:l]
+		} else {
+			d.BodyPositions = make([]uint32, l)
+		}
+		for k0 := range d.BodyPositions {
+
+			{
+
+				bs := uint8(7)
+				t := uint64(buf[i+0] & 0x7F)
+				for buf[i+0]&0x80 == 0x80 {
+					i++
+					t |= uint64(buf[i+0]&0x7F) << bs
+					bs += 7
+				}
+				i++
+
+				d.BodyPositions[k0] = uint32(t)
+
+			}
+
+		}
+	}
+	return i + 0, nil
+}
@@ -0,0 +1,309 @@
+package zscratchpad
+
+
+import "bytes"
+import "fmt"
+import "html"
+import "math"
+import "sort"
+import "strings"
+
+
+import "github.com/akutz/sortfold"
+
+
+
+
+type DocumentGraph struct {
+	Center string
+	Nodes []*DocumentGraphNode
+	Edges []*DocumentGraphEdge
+}
+
+type DocumentGraphNode struct {
+	Document *Document
+	Depth uint
+	LinksIn uint
+	LinksOut uint
+}
+
+type DocumentGraphEdge struct {
+	Source string
+	Target string
+	Labels []string
+}
+
+
+
+
+// NOTE:  Only links between the given documents are considered, thus links to missing documents (see `check-links`) are ignored.
+// NOTE:  If there is a center, only the documents within the given depth (following links in both directions) are kept.
+func DocumentGraphBuild (_index *Index, _documents []*Document, _center *Document, _depth uint) (*DocumentGraph, *Error) {
+	
+	_allowed := make (map[string]*Document, len (_documents) + 1)
+	for _, _document := range _documents {
+		_allowed[_document.Identifier] = _document
+	}
+	if _center != nil {
+		_allowed[_center.Identifier] = _center
+	}
+	
+	_edges := make ([]*DocumentGraphEdge, 0, 1024)
+	_neighbours := make (map[string][]string, len (_allowed))
+	for _source, _ := range _allowed {
+		_links, _error := IndexDocumentLinksSelect (_index, _source)
+		if _error != nil {
+			return nil, _error
+		}
+		for _target, _labels := range _links.Documents {
+			if _target == _source {
+				continue
+			}
+			if _, _exists := _allowed[_target]; !_exists {
+				continue
+			}
+			_edges = append (_edges, & DocumentGraphEdge {
+					Source : _source,
+					Target : _target,
+					Labels : _labels,
+				})
+			_neighbours[_source] = append (_neighbours[_source], _target)
+			_neighbours[_target] = append (_neighbours[_target], _source)
+		}
+	}
+	
+	_depths := make (map[string]uint, len (_allowed))
+	if _center != nil {
+		_depths[_center.Identifier] = 0
+		_queue := []string { _center.Identifier }
+		for len (_queue) > 0 {
+			_current := _queue[0]
+			_queue = _queue[1:]
+			if _depths[_current] >= _depth {
+				continue
+			}
+			for _, _neighbour := range _neighbours[_current] {
+				if _, _visited := _depths[_neighbour]; _visited {
+					continue
+				}
+				_depths[_neighbour] = _depths[_current] + 1
+				_queue = append (_queue, _neighbour)
+			}
+		}
+	} else {
+		for _identifier, _ := range _allowed {
+			_depths[_identifier] = 0
+		}
+	}
+	
+	_graph := & DocumentGraph {
+			Nodes : make ([]*DocumentGraphNode, 0, len (_depths)),
+			Edges : make ([]*DocumentGraphEdge, 0, len (_edges)),
+		}
+	if _center != nil {
+		_graph.Center = _center.Identifier
+	}
+	
+	_nodes := make (map[string]*DocumentGraphNode, len (_depths))
+	for _identifier, _depth := range _depths {
+		_node := & DocumentGraphNode {
+				Document : _allowed[_identifier],
+				Depth : _depth,
+			}
+		_nodes[_identifier] = _node
+		_graph.Nodes = append (_graph.Nodes, _node)
+	}
+	for _, _edge := range _edges {
+		_source, _sourceExists := _nodes[_edge.Source]
+		_target, _targetExists := _nodes[_edge.Target]
+		if !_sourceExists || !_targetExists {
+			continue
+		}
+		_source.LinksOut += 1
+		_target.LinksIn += 1
+		_graph.Edges = append (_graph.Edges, _edge)
+	}
+	
+	sort.Slice (_graph.Nodes, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _graph.Nodes[_leftIndex]
+			_right := _graph.Nodes[_rightIndex]
+			if _left.Depth != _right.Depth {
+				return _left.Depth < _right.Depth
+			}
+			return sortfold.CompareFold (_left.Document.Identifier, _right.Document.Identifier) < 0
+		})
+	sort.Slice (_graph.Edges, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _graph.Edges[_leftIndex]
+			_right := _graph.Edges[_rightIndex]
+			if _left.Source != _right.Source {
+				return sortfold.CompareFold (_left.Source, _right.Source) < 0
+			}
+			return sortfold.CompareFold (_left.Target, _right.Target) < 0
+		})
+	
+	return _graph, nil
+}
+
+
+
+
+func DocumentGraphRenderDot (_graph *DocumentGraph, _buffer *bytes.Buffer) () {
+	
+	_quote := func (_value string) (string) {
+		_value = strings.ReplaceAll (_value, "\\", "\\\\")
+		_value = strings.ReplaceAll (_value, "\"", "\\\"")
+		_value = strings.ReplaceAll (_value, "\n", " ")
+		return "\"" + _value + "\""
+	}
+	
+	_buffer.WriteString ("digraph \"z-scratchpad\" {\n")
+	for _, _node := range _graph.Nodes {
+		_style := ""
+		if _node.Document.Identifier == _graph.Center {
+			_style = ", style=bold"
+		}
+		fmt.Fprintf (_buffer, "\t%s [label=%s%s];\n", _quote (_node.Document.Identifier), _quote (documentGraphLabel (_node.Document)), _style)
+	}
+	for _, _edge := range _graph.Edges {
+		fmt.Fprintf (_buffer, "\t%s -> %s;\n", _quote (_edge.Source), _quote (_edge.Target))
+	}
+	_buffer.WriteString ("}\n")
+}
+
+
+func DocumentGraphRenderGraphml (_graph *DocumentGraph, _buffer *bytes.Buffer) () {
+	
+	_buffer.WriteString ("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	_buffer.WriteString ("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	_buffer.WriteString ("\t<key id=\"title\" for=\"node\" attr.name=\"title\" attr.type=\"string\"/>\n")
+	_buffer.WriteString ("\t<key id=\"library\" for=\"node\" attr.name=\"library\" attr.type=\"string\"/>\n")
+	_buffer.WriteString ("\t<key id=\"depth\" for=\"node\" attr.name=\"depth\" attr.type=\"int\"/>\n")
+	_buffer.WriteString ("\t<key id=\"labels\" for=\"edge\" attr.name=\"labels\" attr.type=\"string\"/>\n")
+	_buffer.WriteString ("\t<graph id=\"z-scratchpad\" edgedefault=\"directed\">\n")
+	for _, _node := range _graph.Nodes {
+		fmt.Fprintf (_buffer, "\t\t<node id=\"%s\">\n", html.EscapeString (_node.Document.Identifier))
+		fmt.Fprintf (_buffer, "\t\t\t<data key=\"title\">%s</data>\n", html.EscapeString (documentGraphLabel (_node.Document)))
+		fmt.Fprintf (_buffer, "\t\t\t<data key=\"library\">%s</data>\n", html.EscapeString (_node.Document.Library))
+		if _graph.Center != "" {
+			fmt.Fprintf (_buffer, "\t\t\t<data key=\"depth\">%d</data>\n", _node.Depth)
+		}
+		_buffer.WriteString ("\t\t</node>\n")
+	}
+	for _, _edge := range _graph.Edges {
+		fmt.Fprintf (_buffer, "\t\t<edge source=\"%s\" target=\"%s\">\n", html.EscapeString (_edge.Source), html.EscapeString (_edge.Target))
+		fmt.Fprintf (_buffer, "\t\t\t<data key=\"labels\">%s</data>\n", html.EscapeString (strings.Join (_edge.Labels, ", ")))
+		_buffer.WriteString ("\t\t</edge>\n")
+	}
+	_buffer.WriteString ("\t</graph>\n")
+	_buffer.WriteString ("</graphml>\n")
+}
+
+
+
+
+const documentGraphSvgRing = 180.0
+const documentGraphSvgMargin = 160.0
+const documentGraphSvgNode = 7.0
+const documentGraphSvgLabel = 32
+
+
+// NOTE:  The layout is radial, each depth on its own ring around the center, thus it is meant only for neighbourhoods.
+func DocumentGraphRenderSvg (_graph *DocumentGraph) (string) {
+	
+	_rings := make (map[uint][]*DocumentGraphNode, 4)
+	_depthMaximum := uint (0)
+	for _, _node := range _graph.Nodes {
+		_rings[_node.Depth] = append (_rings[_node.Depth], _node)
+		if _node.Depth > _depthMaximum {
+			_depthMaximum = _node.Depth
+		}
+	}
+	if len (_rings[0]) > 1 {
+		_depthMaximum += 1
+	}
+	
+	_size := 2 * (float64 (_depthMaximum) * documentGraphSvgRing + documentGraphSvgMargin)
+	_middle := _size / 2
+	
+	type point struct {
+		x float64
+		y float64
+	}
+	_points := make (map[string]point, len (_graph.Nodes))
+	for _depth, _nodes := range _rings {
+		_radius := float64 (_depth) * documentGraphSvgRing
+		if (_depth == 0) && (len (_nodes) > 1) {
+			_radius = documentGraphSvgRing
+		}
+		for _index, _node := range _nodes {
+			// NOTE:  Each ring is slightly rotated, so that the labels of consecutive rings don't align.
+			_angle := 2 * math.Pi * float64 (_index) / float64 (len (_nodes)) + float64 (_depth) * 0.3 - math.Pi / 2
+			_points[_node.Document.Identifier] = point {
+					_middle + _radius * math.Cos (_angle),
+					_middle + _radius * math.Sin (_angle),
+				}
+		}
+	}
+	
+	_buffer := BytesBufferNewSize (64 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	fmt.Fprintf (_buffer, "<svg xmlns=\"http://www.w3.org/2000/svg\" class=\"document-graph\" viewBox=\"0 0 %.0f %.0f\" width=\"%.0f\" height=\"%.0f\">\n", _size, _size, _size, _size)
+	_buffer.WriteString ("<defs><marker id=\"document-graph-arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"8\" markerHeight=\"8\" orient=\"auto\"><path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"currentColor\"/></marker></defs>\n")
+	
+	_buffer.WriteString ("<g class=\"edges\" stroke=\"currentColor\" stroke-opacity=\"0.5\">\n")
+	for _, _edge := range _graph.Edges {
+		_source := _points[_edge.Source]
+		_target := _points[_edge.Target]
+		_targetRadius := documentGraphSvgNode
+		if _edge.Target == _graph.Center {
+			_targetRadius *= 1.5
+		}
+		_length := math.Hypot (_target.x - _source.x, _target.y - _source.y)
+		if _length <= (2 * _targetRadius) {
+			continue
+		}
+		// NOTE:  The line stops at the border of the target node, so that the arrow is visible.
+		_ratio := (_length - _targetRadius) / _length
+		fmt.Fprintf (_buffer, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" marker-end=\"url(#document-graph-arrow)\"/>\n",
+				_source.x, _source.y,
+				_source.x + (_target.x - _source.x) * _ratio, _source.y + (_target.y - _source.y) * _ratio,
+			)
+	}
+	_buffer.WriteString ("</g>\n")
+	
+	_buffer.WriteString ("<g class=\"nodes\" fill=\"currentColor\" font-size=\"12\" text-anchor=\"middle\">\n")
+	for _, _node := range _graph.Nodes {
+		_point := _points[_node.Document.Identifier]
+		_label := documentGraphLabel (_node.Document)
+		_labelShort := _label
+		if _runes := []rune (_labelShort); len (_runes) > documentGraphSvgLabel {
+			_labelShort = string (_runes[: documentGraphSvgLabel - 1]) + "…"
+		}
+		_class := "node"
+		_radius := documentGraphSvgNode
+		if _node.Document.Identifier == _graph.Center {
+			_class = "node center"
+			_radius *= 1.5
+		}
+		fmt.Fprintf (_buffer, "<a href=\"/g/%s\" class=\"%s\"><title>%s</title>", html.EscapeString (_node.Document.Identifier), _class, html.EscapeString (_label))
+		fmt.Fprintf (_buffer, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"%.1f\"/>", _point.x, _point.y, _radius)
+		fmt.Fprintf (_buffer, "<text x=\"%.1f\" y=\"%.1f\">%s</text>", _point.x, _point.y + _radius + 14, html.EscapeString (_labelShort))
+		_buffer.WriteString ("</a>\n")
+	}
+	_buffer.WriteString ("</g>\n")
+	
+	_buffer.WriteString ("</svg>\n")
+	
+	return string (_buffer.Bytes ())
+}
+
+
+
+
+func documentGraphLabel (_document *Document) (string) {
+	if _document.Title != "" {
+		return _document.Title
+	}
+	return "[" + _document.Identifier + "]"
+}
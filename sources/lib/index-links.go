@@ -0,0 +1,213 @@
+package zscratchpad
+
+
+import "net/url"
+import "strings"
+
+
+import "github.com/akutz/sortfold"
+
+
+
+
+// NOTE:  Both maps are keyed by the target identifier, and hold the labels used for the links.
+type IndexDocumentLinks struct {
+	Documents map[string][]string
+	Libraries map[string][]string
+}
+
+
+
+
+// NOTE:  The links are extracted by rendering the documents, thus they are computed lazily, only when first needed.
+func IndexDocumentLinksSelect (_index *Index, _identifier string) (*IndexDocumentLinks, *Error) {
+	
+	_document, _error := IndexDocumentResolve (_index, _identifier)
+	if _error != nil {
+		return nil, _error
+	}
+	if _document == nil {
+		return nil, errorw (0x136a8a5d, nil)
+	}
+	
+	if _, _exists := _index.documentLinks[_document.Identifier]; !_exists {
+		if _error := indexDocumentLinksInclude (_index, _document); _error != nil {
+			return nil, _error
+		}
+	}
+	
+	return _index.documentLinks[_document.Identifier], nil
+}
+
+
+func IndexDocumentBacklinksSelect (_index *Index, _identifier string) ([]string, *Error) {
+	
+	if _error := IndexLinksRefresh (_index); _error != nil {
+		return nil, _error
+	}
+	
+	// NOTE:  The target might not exist (i.e. a dangling link), thus aliases are resolved only if possible.
+	if _document, _error := IndexDocumentResolve (_index, _identifier); _error == nil {
+		if _document != nil {
+			_identifier = _document.Identifier
+		}
+	} else {
+		return nil, _error
+	}
+	
+	return indexLinksSourcesSorted (_index.documentBacklinks[_identifier]), nil
+}
+
+
+func IndexLibraryBacklinksSelect (_index *Index, _identifier string) ([]string, *Error) {
+	
+	if _error := IndexLinksRefresh (_index); _error != nil {
+		return nil, _error
+	}
+	
+	return indexLinksSourcesSorted (_index.libraryBacklinks[_identifier]), nil
+}
+
+
+
+
+func IndexLinksRefresh (_index *Index) (*Error) {
+	
+	_documents, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return _error
+	}
+	
+	for _, _document := range _documents {
+		if _, _exists := _index.documentLinks[_document.Identifier]; _exists {
+			continue
+		}
+		// NOTE:  Rendering might have reloaded the document, thus only current documents are considered.
+		if _index.documents[_document.Identifier] != _document {
+			continue
+		}
+		if _error := indexDocumentLinksInclude (_index, _document); _error != nil {
+			return _error
+		}
+	}
+	
+	return nil
+}
+
+
+// NOTE:  Called after a document was reloaded, so that the links are kept up-to-date once they are in use.
+func IndexDocumentLinksUpdate (_index *Index, _document *Document) (*Error) {
+	if len (_index.documentLinks) == 0 {
+		return nil
+	}
+	indexDocumentLinksExclude (_index, _document.Identifier)
+	return indexDocumentLinksInclude (_index, _document)
+}
+
+
+
+
+func indexDocumentLinksInclude (_index *Index, _document *Document) (*Error) {
+	
+	_links := & IndexDocumentLinks {
+			Documents : make (map[string][]string, 16),
+			Libraries : make (map[string][]string, 4),
+		}
+	
+	// NOTE:  Documents that fail to render have no links, but they shouldn't break the whole index.
+	if _, _error := DocumentRenderToHtml (_document, false, _index); _error != nil {
+		logErrorf ('w', 0x5218640b, _error, "[links]  failed rendering `%s`;", _document.Identifier)
+	}
+	
+	for _urlString, _labels := range _document.HtmlLinks {
+		_url, _error := url.Parse (_urlString)
+		if _error != nil {
+			continue
+		}
+		if (_url.Scheme != "") || (_url.Host != "") {
+			continue
+		}
+		_path := _url.Path
+		if strings.HasPrefix (_path, "/d/") {
+			_target := _path[3:]
+			if _target == "" {
+				continue
+			}
+			if _targetDocument, _ := _index.documents[_target]; _targetDocument != nil {
+				_target = _targetDocument.Identifier
+			} else if _targetDocument := indexDocumentResolveAlias (_index, _target); _targetDocument != nil {
+				_target = _targetDocument.Identifier
+			}
+			_links.Documents[_target] = append (_links.Documents[_target], _labels ...)
+		} else if strings.HasPrefix (_path, "/l/") {
+			_target := _path[3:]
+			if _target == "" {
+				continue
+			}
+			_links.Libraries[_target] = append (_links.Libraries[_target], _labels ...)
+		}
+	}
+	
+	_index.documentLinks[_document.Identifier] = _links
+	
+	for _target, _ := range _links.Documents {
+		indexLinksSourcesAdd (_index.documentBacklinks, _target, _document.Identifier)
+	}
+	for _target, _ := range _links.Libraries {
+		indexLinksSourcesAdd (_index.libraryBacklinks, _target, _document.Identifier)
+	}
+	
+	return nil
+}
+
+
+func indexDocumentLinksExclude (_index *Index, _identifier string) () {
+	
+	_links, _exists := _index.documentLinks[_identifier]
+	if !_exists {
+		return
+	}
+	delete (_index.documentLinks, _identifier)
+	
+	for _target, _ := range _links.Documents {
+		indexLinksSourcesRemove (_index.documentBacklinks, _target, _identifier)
+	}
+	for _target, _ := range _links.Libraries {
+		indexLinksSourcesRemove (_index.libraryBacklinks, _target, _identifier)
+	}
+}
+
+
+
+
+func indexLinksSourcesAdd (_backlinks map[string]map[string]bool, _target string, _source string) () {
+	_sources, _exists := _backlinks[_target]
+	if !_exists {
+		_sources = make (map[string]bool, 16)
+		_backlinks[_target] = _sources
+	}
+	_sources[_source] = true
+}
+
+
+func indexLinksSourcesRemove (_backlinks map[string]map[string]bool, _target string, _source string) () {
+	_sources, _exists := _backlinks[_target]
+	if !_exists {
+		return
+	}
+	delete (_sources, _source)
+	if len (_sources) == 0 {
+		delete (_backlinks, _target)
+	}
+}
+
+
+func indexLinksSourcesSorted (_sources map[string]bool) ([]string) {
+	_list := make ([]string, 0, len (_sources))
+	for _source, _ := range _sources {
+		_list = append (_list, _source)
+	}
+	sortfold.Strings (_list)
+	return _list
+}
+
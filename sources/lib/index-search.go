@@ -0,0 +1,453 @@
+package zscratchpad
+
+
+import "math"
+import "sort"
+import "strings"
+import "unicode"
+
+
+import "github.com/akutz/sortfold"
+
+
+
+
+type IndexSearchDocument struct {
+	TitleLength uint32
+	BodyLength uint32
+	Terms []string
+}
+
+type IndexSearchPosting struct {
+	TitlePositions []uint32
+	BodyPositions []uint32
+}
+
+type IndexSearchResult struct {
+	Document *Document
+	Score float64
+}
+
+
+// NOTE:  The usual BM25 parameters, with the title weighted as if each occurrence were multiple body occurrences.
+const IndexSearchBm25K1 = 1.2
+const IndexSearchBm25B = 0.75
+const IndexSearchTitleBoost = 3.0
+
+const indexSearchTokenLengthMaximum = 64
+
+
+
+
+// NOTE:  Quoted text is a phrase, and so is any unquoted word that splits into multiple tokens (e.g. `foo-bar`).
+func IndexSearchQueryParse (_query string) ([][]string) {
+	_clauses := make ([][]string, 0, 8)
+	for _index, _part := range strings.Split (_query, "\"") {
+		if (_index % 2) == 1 {
+			if _tokens := IndexSearchTokenize (_part); len (_tokens) > 0 {
+				_clauses = append (_clauses, _tokens)
+			}
+			continue
+		}
+		for _, _word := range strings.Fields (_part) {
+			if _tokens := IndexSearchTokenize (_word); len (_tokens) > 0 {
+				_clauses = append (_clauses, _tokens)
+			}
+		}
+	}
+	return _clauses
+}
+
+
+func IndexSearchTokenize (_text string) ([]string) {
+	_tokens := make ([]string, 0, len (_text) / 6 + 1)
+	for _, _token := range strings.FieldsFunc (_text, indexSearchTokenSeparator) {
+		if len (_token) > indexSearchTokenLengthMaximum {
+			continue
+		}
+		_tokens = append (_tokens, strings.ToLower (_token))
+	}
+	return _tokens
+}
+
+
+func indexSearchTokenSeparator (_rune rune) (bool) {
+	return !unicode.IsLetter (_rune) && !unicode.IsNumber (_rune) && !unicode.IsMark (_rune)
+}
+
+
+
+
+// NOTE:  A light suffix stripper for English (plurals, `-ing`, `-ed`, `-ly`), that keeps non-ASCII words unchanged.
+func IndexSearchStem (_token string) (string) {
+	
+	for _, _rune := range _token {
+		if (_rune < 'a') || (_rune > 'z') {
+			return _token
+		}
+	}
+	if len (_token) <= 3 {
+		return _token
+	}
+	
+	switch {
+		case strings.HasSuffix (_token, "sses") :
+			_token = _token[: len (_token) - 2]
+		case strings.HasSuffix (_token, "ies") && (len (_token) > 4) :
+			_token = _token[: len (_token) - 3] + "y"
+		case strings.HasSuffix (_token, "ches"), strings.HasSuffix (_token, "shes"), strings.HasSuffix (_token, "xes"), strings.HasSuffix (_token, "zes") :
+			_token = _token[: len (_token) - 2]
+		case strings.HasSuffix (_token, "ss"), strings.HasSuffix (_token, "us"), strings.HasSuffix (_token, "is") :
+			// NOP
+		case strings.HasSuffix (_token, "s") :
+			_token = _token[: len (_token) - 1]
+	}
+	
+	_stripped := false
+	switch {
+		case strings.HasSuffix (_token, "ing") && (len (_token) > 5) :
+			_token = _token[: len (_token) - 3]
+			_stripped = true
+		case strings.HasSuffix (_token, "ed") && (len (_token) > 4) && !strings.HasSuffix (_token, "eed") :
+			_token = _token[: len (_token) - 2]
+			_stripped = true
+		case strings.HasSuffix (_token, "ly") && (len (_token) > 4) :
+			_token = _token[: len (_token) - 2]
+	}
+	
+	// NOTE:  Undo the doubled consonant (e.g. `running` to `run`).
+	if _stripped && (len (_token) > 2) {
+		_last := _token[len (_token) - 1]
+		if (_last == _token[len (_token) - 2]) && !strings.ContainsRune ("aeiouylsz", rune (_last)) {
+			_token = _token[: len (_token) - 1]
+		}
+	}
+	
+	return _token
+}
+
+
+
+
+// NOTE:  The whole index is searched, thus filtering by library or tags is left to the caller.
+func IndexSearchSelect (_index *Index, _query string, _stemming bool, _matchAny bool) ([]*IndexSearchResult, *Error) {
+	
+	_clauses := IndexSearchQueryParse (_query)
+	if len (_clauses) == 0 {
+		return nil, errorf (0x8499c25f, "empty search query")
+	}
+	
+	// NOTE:  Selecting all documents reloads the changed ones, thus also updating their search terms.
+	if _, _error := IndexDocumentsSelectAll (_index); _error != nil {
+		return nil, _error
+	}
+	
+	_documentsCount := float64 (len (_index.searchDocuments))
+	if _documentsCount == 0 {
+		return []*IndexSearchResult {}, nil
+	}
+	_titleLengthAverage := math.Max (float64 (_index.searchTitleLengthTotal) / _documentsCount, 1)
+	_bodyLengthAverage := math.Max (float64 (_index.searchBodyLengthTotal) / _documentsCount, 1)
+	
+	_scores := make (map[string]float64, 1024)
+	_matches := make (map[string]int, 1024)
+	
+	for _, _clause := range _clauses {
+		
+		_frequencies := indexSearchClauseFrequencies (_index, _clause, _stemming)
+		if len (_frequencies) == 0 {
+			continue
+		}
+		
+		_idf := math.Log (1 + (_documentsCount - float64 (len (_frequencies)) + 0.5) / (float64 (len (_frequencies)) + 0.5))
+		
+		for _identifier, _frequency := range _frequencies {
+			_document := _index.searchDocuments[_identifier]
+			_titleNormalization := 1 - IndexSearchBm25B + IndexSearchBm25B * float64 (_document.TitleLength) / _titleLengthAverage
+			_bodyNormalization := 1 - IndexSearchBm25B + IndexSearchBm25B * float64 (_document.BodyLength) / _bodyLengthAverage
+			_weight := IndexSearchTitleBoost * float64 (_frequency[0]) / _titleNormalization + float64 (_frequency[1]) / _bodyNormalization
+			_scores[_identifier] += _idf * _weight * (IndexSearchBm25K1 + 1) / (_weight + IndexSearchBm25K1)
+			_matches[_identifier] += 1
+		}
+	}
+	
+	_results := make ([]*IndexSearchResult, 0, len (_scores))
+	for _identifier, _score := range _scores {
+		if !_matchAny && (_matches[_identifier] != len (_clauses)) {
+			continue
+		}
+		_document, _ := _index.documents[_identifier]
+		if _document == nil {
+			continue
+		}
+		_results = append (_results, & IndexSearchResult {
+				Document : _document,
+				Score : _score,
+			})
+	}
+	
+	sort.Slice (_results, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _results[_leftIndex]
+			_right := _results[_rightIndex]
+			if _left.Score != _right.Score {
+				return _left.Score > _right.Score
+			}
+			return sortfold.CompareFold (_left.Document.Identifier, _right.Document.Identifier) < 0
+		})
+	
+	return _results, nil
+}
+
+
+// NOTE:  Returns, for each matching document, the number of occurrences in the title and in the body.
+func indexSearchClauseFrequencies (_index *Index, _clause []string, _stemming bool) (map[string][2]uint32) {
+	
+	// NOTE:  For each token of the clause, the positions (from all its variants) in each document.
+	_tokensPostings := make ([]map[string]*IndexSearchPosting, 0, len (_clause))
+	for _, _token := range _clause {
+		_variants := []string { _token }
+		if _stemming {
+			_variants = indexSearchStemVariants (_index, _token)
+		}
+		_postings := make (map[string]*IndexSearchPosting, 16)
+		for _, _variant := range _variants {
+			for _identifier, _posting := range _index.searchTerms[_variant] {
+				if _existing, _exists := _postings[_identifier]; _exists {
+					_merged := & IndexSearchPosting {
+							TitlePositions : append (append ([]uint32 (nil), _existing.TitlePositions ...), _posting.TitlePositions ...),
+							BodyPositions : append (append ([]uint32 (nil), _existing.BodyPositions ...), _posting.BodyPositions ...),
+						}
+					_postings[_identifier] = _merged
+				} else {
+					_postings[_identifier] = _posting
+				}
+			}
+		}
+		if len (_postings) == 0 {
+			return nil
+		}
+		_tokensPostings = append (_tokensPostings, _postings)
+	}
+	
+	_frequencies := make (map[string][2]uint32, len (_tokensPostings[0]))
+	for _identifier, _posting := range _tokensPostings[0] {
+		if len (_clause) == 1 {
+			_frequencies[_identifier] = [2]uint32 { uint32 (len (_posting.TitlePositions)), uint32 (len (_posting.BodyPositions)) }
+			continue
+		}
+		_titlePositions := make ([][]uint32, 0, len (_clause))
+		_bodyPositions := make ([][]uint32, 0, len (_clause))
+		for _, _postings := range _tokensPostings {
+			_posting_0, _exists := _postings[_identifier]
+			if !_exists {
+				break
+			}
+			_titlePositions = append (_titlePositions, _posting_0.TitlePositions)
+			_bodyPositions = append (_bodyPositions, _posting_0.BodyPositions)
+		}
+		if len (_titlePositions) != len (_clause) {
+			continue
+		}
+		_frequency := [2]uint32 { indexSearchPhraseCount (_titlePositions), indexSearchPhraseCount (_bodyPositions) }
+		if (_frequency[0] + _frequency[1]) == 0 {
+			continue
+		}
+		_frequencies[_identifier] = _frequency
+	}
+	
+	return _frequencies
+}
+
+
+func indexSearchPhraseCount (_positions [][]uint32) (uint32) {
+	_sets := make ([]map[uint32]bool, len (_positions))
+	for _index, _list := range _positions {
+		_sets[_index] = make (map[uint32]bool, len (_list))
+		for _, _position := range _list {
+			_sets[_index][_position] = true
+		}
+	}
+	_count := uint32 (0)
+	for _, _start := range _positions[0] {
+		_found := true
+		for _offset := 1; _offset < len (_positions); _offset += 1 {
+			if !_sets[_offset][_start + uint32 (_offset)] {
+				_found = false
+				break
+			}
+		}
+		if _found {
+			_count += 1
+		}
+	}
+	return _count
+}
+
+
+func indexSearchStemVariants (_index *Index, _token string) ([]string) {
+	if _index.searchStems == nil {
+		_index.searchStems = make (map[string][]string, len (_index.searchTerms))
+		for _term, _ := range _index.searchTerms {
+			_stem := IndexSearchStem (_term)
+			_index.searchStems[_stem] = append (_index.searchStems[_stem], _term)
+		}
+	}
+	_variants := _index.searchStems[IndexSearchStem (_token)]
+	if len (_variants) == 0 {
+		return []string { _token }
+	}
+	return _variants
+}
+
+
+
+
+func indexSearchInclude (_index *Index, _document *Document) () {
+	
+	indexSearchExclude (_index, _document.Identifier)
+	
+	_titleTokens := IndexSearchTokenize (_document.Title)
+	_bodyTokens := []string (nil)
+	if _document.Encrypted {
+		// NOTE:  The encrypted bodies are not indexed (thus not stored in the database), unless explicitly allowed.
+		if _library := _index.libraries[_document.Library]; (_library != nil) && _library.EncryptionIndexEnabled {
+			_bodyTokens = IndexSearchTokenize (strings.Join (_document.BodyLines, "\n"))
+		}
+	} else {
+		_bodyTokens = IndexSearchTokenize (strings.Join (_document.BodyLines, "\n"))
+	}
+	
+	_postings := make (map[string]*IndexSearchPosting, len (_bodyTokens) / 2 + len (_titleTokens))
+	_posting := func (_token string) (*IndexSearchPosting) {
+		_posting, _exists := _postings[_token]
+		if !_exists {
+			_posting = & IndexSearchPosting {}
+			_postings[_token] = _posting
+		}
+		return _posting
+	}
+	for _position, _token := range _titleTokens {
+		_posting_0 := _posting (_token)
+		_posting_0.TitlePositions = append (_posting_0.TitlePositions, uint32 (_position))
+	}
+	for _position, _token := range _bodyTokens {
+		_posting_0 := _posting (_token)
+		_posting_0.BodyPositions = append (_posting_0.BodyPositions, uint32 (_position))
+	}
+	
+	_terms := make ([]string, 0, len (_postings))
+	for _term, _posting := range _postings {
+		indexSearchPostingAdd (_index, _term, _document.Identifier, _posting)
+		_terms = append (_terms, _term)
+	}
+	
+	_index.searchDocuments[_document.Identifier] = & IndexSearchDocument {
+			TitleLength : uint32 (len (_titleTokens)),
+			BodyLength : uint32 (len (_bodyTokens)),
+			Terms : _terms,
+		}
+	_index.searchTitleLengthTotal += uint64 (len (_titleTokens))
+	_index.searchBodyLengthTotal += uint64 (len (_bodyTokens))
+}
+
+
+func indexSearchExclude (_index *Index, _identifier string) () {
+	
+	_document, _exists := _index.searchDocuments[_identifier]
+	if !_exists {
+		return
+	}
+	delete (_index.searchDocuments, _identifier)
+	
+	for _, _term := range _document.Terms {
+		_postings, _exists := _index.searchTerms[_term]
+		if !_exists {
+			continue
+		}
+		delete (_postings, _identifier)
+		if len (_postings) == 0 {
+			delete (_index.searchTerms, _term)
+			_index.searchStems = nil
+		}
+	}
+	
+	_index.searchTitleLengthTotal -= uint64 (_document.TitleLength)
+	_index.searchBodyLengthTotal -= uint64 (_document.BodyLength)
+}
+
+
+func indexSearchPostingAdd (_index *Index, _term string, _identifier string, _posting *IndexSearchPosting) () {
+	_postings, _exists := _index.searchTerms[_term]
+	if !_exists {
+		_postings = make (map[string]*IndexSearchPosting, 16)
+		_index.searchTerms[_term] = _postings
+		_index.searchStems = nil
+	}
+	_postings[_identifier] = _posting
+}
+
+
+
+
+func indexSearchLoadData (_index *Index, _gob *IndexGob) () {
+	
+	_index.searchDocuments = make (map[string]*IndexSearchDocument, len (_gob.SearchDocuments))
+	_index.searchTerms = make (map[string]map[string]*IndexSearchPosting, len (_gob.SearchTerms))
+	_index.searchStems = nil
+	_index.searchTitleLengthTotal = 0
+	_index.searchBodyLengthTotal = 0
+	
+	for _, _documentGob := range _gob.SearchDocuments {
+		_index.searchDocuments[_documentGob.Identifier] = & IndexSearchDocument {
+				TitleLength : _documentGob.TitleLength,
+				BodyLength : _documentGob.BodyLength,
+				Terms : make ([]string, 0, 64),
+			}
+		_index.searchTitleLengthTotal += uint64 (_documentGob.TitleLength)
+		_index.searchBodyLengthTotal += uint64 (_documentGob.BodyLength)
+	}
+	
+	for _, _termGob := range _gob.SearchTerms {
+		for _, _postingGob := range _termGob.Postings {
+			_document, _exists := _index.searchDocuments[_postingGob.Document]
+			if !_exists {
+				continue
+			}
+			_document.Terms = append (_document.Terms, _termGob.Term)
+			indexSearchPostingAdd (_index, _termGob.Term, _postingGob.Document, & IndexSearchPosting {
+					TitlePositions : _postingGob.TitlePositions,
+					BodyPositions : _postingGob.BodyPositions,
+				})
+		}
+	}
+}
+
+
+func indexSearchStoreData (_index *Index, _gob *IndexGob) () {
+	
+	_documents := make ([]IndexSearchDocumentGob, 0, len (_index.searchDocuments))
+	for _identifier, _document := range _index.searchDocuments {
+		if _, _exists := _index.documents[_identifier]; !_exists {
+			continue
+		}
+		_documents = append (_documents, IndexSearchDocumentGob { _identifier, _document.TitleLength, _document.BodyLength })
+	}
+	
+	_terms := make ([]IndexSearchTermGob, 0, len (_index.searchTerms))
+	for _term, _postings := range _index.searchTerms {
+		_postingsGob := make ([]IndexSearchPostingGob, 0, len (_postings))
+		for _identifier, _posting := range _postings {
+			if _, _exists := _index.documents[_identifier]; !_exists {
+				continue
+			}
+			_postingsGob = append (_postingsGob, IndexSearchPostingGob { _identifier, _posting.TitlePositions, _posting.BodyPositions })
+		}
+		if len (_postingsGob) == 0 {
+			continue
+		}
+		_terms = append (_terms, IndexSearchTermGob { _term, _postingsGob })
+	}
+	
+	_gob.SearchDocuments = _documents
+	_gob.SearchTerms = _terms
+}
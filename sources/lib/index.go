@@ -8,6 +8,7 @@ import "encoding/gob"
 import "fmt"
 import "os"
 import "sort"
+import "strings"
 import "time"
 import "syscall"
 
@@ -28,6 +29,22 @@ type Index struct {
 	libraries map[string]*Library
 	libraryDocuments map[string]map[string]bool
 	
+	documentAliases map[string]string
+	documentMoves map[string]string
+	documentFingerprints map[string]string
+	
+	documentLinks map[string]*IndexDocumentLinks
+	documentBacklinks map[string]map[string]bool
+	libraryBacklinks map[string]map[string]bool
+	
+	searchDocuments map[string]*IndexSearchDocument
+	searchTerms map[string]map[string]*IndexSearchPosting
+	searchStems map[string][]string
+	searchTitleLengthTotal uint64
+	searchBodyLengthTotal uint64
+	
+	diagnostics []*Diagnostic
+	
 	librariesRefreshEnabled bool
 	librariesRefreshCallback func (*Index) (*Error)
 	documentRefreshEnabled bool
@@ -42,6 +59,10 @@ type IndexGob struct {
 	Documents []*Document
 	Libraries []*Library
 	LibraryDocuments []IndexLibraryDocumentsGob
+	DocumentMoves []IndexDocumentMoveGob
+	DocumentFingerprints []IndexDocumentFingerprintGob
+	SearchDocuments []IndexSearchDocumentGob
+	SearchTerms []IndexSearchTermGob
 }
 
 type IndexLibraryDocumentsGob struct {
@@ -49,6 +70,44 @@ type IndexLibraryDocumentsGob struct {
 	Documents []string
 }
 
+type IndexDocumentMoveGob struct {
+	Alias string
+	Identifier string
+}
+
+type IndexDocumentFingerprintGob struct {
+	Fingerprint string
+	Identifier string
+}
+
+type IndexSearchDocumentGob struct {
+	Identifier string
+	TitleLength uint32
+	BodyLength uint32
+}
+
+type IndexSearchTermGob struct {
+	Term string
+	Postings []IndexSearchPostingGob
+}
+
+type IndexSearchPostingGob struct {
+	Document string
+	TitlePositions []uint32
+	BodyPositions []uint32
+}
+
+
+type IndexStatus struct {
+	RefreshTimestamp time.Time
+	Libraries uint
+	Documents uint
+	Tags uint
+	SearchDocuments uint
+	SearchTerms uint
+	Diagnostics uint
+}
+
 
 
 
@@ -58,6 +117,14 @@ func IndexNew (_globals *Globals) (*Index, *Error) {
 			documents : make (map[string]*Document, 16 * 1024),
 			libraries : make (map[string]*Library, 128),
 			libraryDocuments : make (map[string]map[string]bool, 128),
+			documentAliases : make (map[string]string, 1024),
+			documentMoves : make (map[string]string, 1024),
+			documentFingerprints : make (map[string]string, 16 * 1024),
+			documentLinks : make (map[string]*IndexDocumentLinks, 16 * 1024),
+			documentBacklinks : make (map[string]map[string]bool, 16 * 1024),
+			libraryBacklinks : make (map[string]map[string]bool, 128),
+			searchDocuments : make (map[string]*IndexSearchDocument, 16 * 1024),
+			searchTerms : make (map[string]map[string]*IndexSearchPosting, 64 * 1024),
 			librariesRefreshEnabled : true,
 			documentRefreshEnabled : true,
 			dirtyEnabled : true,
@@ -226,8 +293,22 @@ func IndexLoadData (_index *Index, _gob *IndexGob) (*Error) {
 	}
 	
 	_documents := make (map[string]*Document, len (_gob.Documents))
+	_documentAliases := make (map[string]string, 1024)
 	for _, _document := range _gob.Documents {
 		_documents[_document.Identifier] = _document
+		for _, _alias := range _document.Aliases {
+			_documentAliases[_alias] = _document.Identifier
+		}
+	}
+	
+	_documentMoves := make (map[string]string, len (_gob.DocumentMoves))
+	for _, _move := range _gob.DocumentMoves {
+		_documentMoves[_move.Alias] = _move.Identifier
+	}
+	
+	_documentFingerprints := make (map[string]string, len (_gob.DocumentFingerprints))
+	for _, _fingerprint := range _gob.DocumentFingerprints {
+		_documentFingerprints[_fingerprint.Fingerprint] = _fingerprint.Identifier
 	}
 	
 	_libraryDocuments := make (map[string]map[string]bool, len (_gob.LibraryDocuments))
@@ -242,6 +323,11 @@ func IndexLoadData (_index *Index, _gob *IndexGob) (*Error) {
 	_index.libraries = _libraries
 	_index.documents = _documents
 	_index.libraryDocuments = _libraryDocuments
+	_index.documentAliases = _documentAliases
+	_index.documentMoves = _documentMoves
+	_index.documentFingerprints = _documentFingerprints
+	
+	indexSearchLoadData (_index, _gob)
 	
 	return nil
 }
@@ -256,6 +342,16 @@ func IndexStoreData (_index *Index, _gob *IndexGob) (*Error) {
 	
 	_documents := make ([]*Document, 0, len (_index.documents))
 	for _, _document := range _index.documents {
+		if _document.Encrypted {
+			_library := _index.libraries[_document.Library]
+			if (_library == nil) || !_library.EncryptionIndexEnabled {
+				// NOTE:  The encrypted bodies are not stored in the database, unless explicitly allowed.
+				_document_0 := & Document {}
+				*_document_0 = *_document
+				_document_0.BodyLines = nil
+				_document = _document_0
+			}
+		}
 		_documents = append (_documents, _document)
 	}
 	
@@ -269,18 +365,54 @@ func IndexStoreData (_index *Index, _gob *IndexGob) (*Error) {
 		_libraryDocuments = append (_libraryDocuments, _libraryDocumentsGob)
 	}
 	
+	_documentMoves := make ([]IndexDocumentMoveGob, 0, len (_index.documentMoves))
+	for _alias, _identifier := range _index.documentMoves {
+		if _, _exists := _index.documents[_alias]; _exists {
+			continue
+		}
+		_documentMoves = append (_documentMoves, IndexDocumentMoveGob { _alias, _identifier })
+	}
+	
+	_documentFingerprints := make ([]IndexDocumentFingerprintGob, 0, len (_index.documentFingerprints))
+	for _fingerprint, _identifier := range _index.documentFingerprints {
+		if _document, _exists := _index.documents[_identifier]; !_exists || (_document.BodyFingerprint != _fingerprint) {
+			continue
+		}
+		_documentFingerprints = append (_documentFingerprints, IndexDocumentFingerprintGob { _fingerprint, _identifier })
+	}
+	
 	_gob.Libraries = _libraries
 	_gob.Documents = _documents
 	_gob.LibraryDocuments = _libraryDocuments
+	_gob.DocumentMoves = _documentMoves
+	_gob.DocumentFingerprints = _documentFingerprints
+	
+	indexSearchStoreData (_index, _gob)
 	
 	return nil
 }
 
 
+func IndexRefreshInvalidate (_index *Index) () {
+	_index.refreshTimestamp = time.Time {}
+}
+
+
 func IndexClearData (_index *Index) () {
+	// NOTE:  The document moves and fingerprints are kept, as they must survive re-walking the libraries.
 	_index.documents = make (map[string]*Document, 16 * 1024)
 	_index.libraries = make (map[string]*Library, 128)
 	_index.libraryDocuments = make (map[string]map[string]bool, 128)
+	_index.documentAliases = make (map[string]string, 1024)
+	_index.documentLinks = make (map[string]*IndexDocumentLinks, 16 * 1024)
+	_index.documentBacklinks = make (map[string]map[string]bool, 16 * 1024)
+	_index.libraryBacklinks = make (map[string]map[string]bool, 128)
+	_index.searchDocuments = make (map[string]*IndexSearchDocument, 16 * 1024)
+	_index.searchTerms = make (map[string]map[string]*IndexSearchPosting, 64 * 1024)
+	_index.searchStems = nil
+	_index.searchTitleLengthTotal = 0
+	_index.searchBodyLengthTotal = 0
+	_index.diagnostics = nil
 }
 
 
@@ -316,12 +448,16 @@ func IndexDocumentInclude (_index *Index, _document *Document) (*Error) {
 	if _, _exists := _index.libraries[_document.Library]; !_exists {
 		return errorw (0x4c68b8a9, nil)
 	}
-	if _, _exists := _index.documents[_document.Identifier]; _exists {
+	if _documentExisting, _exists := _index.documents[_document.Identifier]; _exists {
 //		logf ('d', 0x83e5bd38, "`%s` / `%s`", _document.Identifier, _document.Path)
-		return errorw (0x9c9f9c42, nil)
+		return errorf (0x9c9f9c42, "identifier duplicate `%s` (already used by `%s`)", _document.Identifier, _documentExisting.Path)
 	}
 	_index.documents[_document.Identifier] = _document
 	_index.libraryDocuments[_document.Library][_document.Identifier] = true
+	for _, _alias := range _document.Aliases {
+		_index.documentAliases[_alias] = _document.Identifier
+	}
+	indexSearchInclude (_index, _document)
 	if _index.dirtyEnabled && (_index.dirtyCallback != nil) {
 		if _error := _index.dirtyCallback (_index); _error != nil {
 			return _error
@@ -346,6 +482,13 @@ func IndexDocumentExclude (_index *Index, _document *Document) (*Error) {
 	}
 	delete (_index.documents, _document.Identifier)
 	delete (_index.libraryDocuments[_document.Library], _document.Identifier)
+	indexDocumentLinksExclude (_index, _document.Identifier)
+	indexSearchExclude (_index, _document.Identifier)
+	for _, _alias := range _document.Aliases {
+		if _index.documentAliases[_alias] == _document.Identifier {
+			delete (_index.documentAliases, _alias)
+		}
+	}
 	if _index.dirtyEnabled && (_index.dirtyCallback != nil) {
 		if _error := _index.dirtyCallback (_index); _error != nil {
 			return _error
@@ -362,10 +505,39 @@ func IndexDocumentUpdate (_index *Index, _documentNew *Document, _documentOld *D
 	if _error := IndexDocumentInclude (_index, _documentNew); _error != nil {
 		return _error
 	}
+	IndexDocumentMovesRefresh (_index)
 	return nil
 }
 
 
+// NOTE:  Must be called after all documents were included, as a move is recorded only when the body fingerprint is unambiguous, i.e. exactly one vanished document and exactly one present document share it.
+func IndexDocumentMovesRefresh (_index *Index) () {
+	
+	_fingerprintDocuments := make (map[string][]string, len (_index.documents))
+	for _, _document := range _index.documents {
+		if _document.BodyEmpty || (_document.BodyFingerprint == "") {
+			continue
+		}
+		_fingerprintDocuments[_document.BodyFingerprint] = append (_fingerprintDocuments[_document.BodyFingerprint], _document.Identifier)
+	}
+	
+	for _fingerprint, _identifiers := range _fingerprintDocuments {
+		if len (_identifiers) != 1 {
+			// NOTE:  Identical bodies (e.g. template stubs) don't identify a document, thus mark the fingerprint as ambiguous.
+			_index.documentFingerprints[_fingerprint] = ""
+			continue
+		}
+		_identifier := _identifiers[0]
+		if _identifierOld, _exists := _index.documentFingerprints[_fingerprint]; _exists && (_identifierOld != "") && (_identifierOld != _identifier) {
+			if _, _exists := _index.documents[_identifierOld]; !_exists {
+				_index.documentMoves[_identifierOld] = _identifier
+			}
+		}
+		_index.documentFingerprints[_fingerprint] = _identifier
+	}
+}
+
+
 
 
 func IndexLibrariesSelectAll (_index *Index) ([]*Library, *Error) {
@@ -383,26 +555,35 @@ func IndexLibrariesSelectAll (_index *Index) ([]*Library, *Error) {
 }
 
 func IndexDocumentsSelectAll (_index *Index) ([]*Document, *Error) {
-	if _index.librariesRefreshEnabled && (_index.librariesRefreshCallback != nil) {
-		if _error := _index.librariesRefreshCallback (_index); _error != nil {
-			return nil, _error
+	// NOTE:  If some documents vanished (i.e. moved outside the tool), retry after the libraries are refreshed.
+	for _attempt := 0; ; _attempt += 1 {
+		if _index.librariesRefreshEnabled && (_index.librariesRefreshCallback != nil) {
+			if _error := _index.librariesRefreshCallback (_index); _error != nil {
+				return nil, _error
+			}
 		}
-	}
-	_documents := make ([]*Document, 0, len (_index.documents))
-	for _, _document := range _index.documents {
-		if _index.documentRefreshEnabled && (_index.documentRefreshCallback != nil) {
-			if _document_0, _error := _index.documentRefreshCallback (_index, _document); _error == nil {
-				_document = _document_0
+		_documents := make ([]*Document, 0, len (_index.documents))
+		_vanished := false
+		for _, _document := range _index.documents {
+			if _index.documentRefreshEnabled && (_index.documentRefreshCallback != nil) {
+				if _document_0, _error := _index.documentRefreshCallback (_index, _document); _error == nil {
+					_document = _document_0
+				} else {
+					return nil, _error
+				}
+			}
+			if _document != nil {
+				_documents = append (_documents, _document)
 			} else {
-				return nil, _error
+				_vanished = true
 			}
 		}
-		if _document != nil {
-			_documents = append (_documents, _document)
+		if _vanished && (_attempt == 0) {
+			continue
 		}
+		DocumentsSort (_documents)
+		return _documents, nil
 	}
-	DocumentsSort (_documents)
-	return _documents, nil
 }
 
 func IndexDocumentsSelectInLibrary (_index *Index, _libraryIdentifier string) ([]*Document, *Error) {
@@ -437,6 +618,96 @@ func IndexDocumentsSelectInLibrary (_index *Index, _libraryIdentifier string) ([
 }
 
 
+func IndexDocumentsSelectWithTags (_index *Index, _tags []string) ([]*Document, *Error) {
+	_documentsAll, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_documents := make ([]*Document, 0, len (_documentsAll))
+	for _, _document := range _documentsAll {
+		if DocumentHasTags (_document, _tags) {
+			_documents = append (_documents, _document)
+		}
+	}
+	return _documents, nil
+}
+
+// NOTE:  Matches both the title and the alternative titles, case-insensitively.
+func IndexDocumentsSelectWithTitle (_index *Index, _title string) ([]*Document, *Error) {
+	_documentsAll, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_title = strings.Join (strings.Fields (_title), " ")
+	_documents := make ([]*Document, 0, 16)
+	for _, _document := range _documentsAll {
+		_matches := strings.EqualFold (_document.Title, _title)
+		for _, _alternative := range _document.TitleAlternatives {
+			_matches = _matches || strings.EqualFold (_alternative, _title)
+		}
+		if _matches {
+			_documents = append (_documents, _document)
+		}
+	}
+	return _documents, nil
+}
+
+func IndexTagsSelectAll (_index *Index) ([]string, *Error) {
+	_documents, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_tagsSet := make (map[string]bool, 1024)
+	_tags := make ([]string, 0, 1024)
+	for _, _document := range _documents {
+		for _, _tag := range _document.Tags {
+			if _, _exists := _tagsSet[_tag]; _exists {
+				continue
+			}
+			_tagsSet[_tag] = true
+			_tags = append (_tags, _tag)
+		}
+	}
+	sort.Strings (_tags)
+	return _tags, nil
+}
+
+
+
+func IndexDiagnosticsSelectAll (_index *Index) ([]*Diagnostic, *Error) {
+	_diagnostics := make ([]*Diagnostic, len (_index.diagnostics))
+	copy (_diagnostics, _index.diagnostics)
+	return _diagnostics, nil
+}
+
+
+// NOTE:  The counts are taken after a refresh (if enabled), thus they reflect the libraries as they are now on disk.
+func IndexStatusSelect (_index *Index) (*IndexStatus, *Error) {
+	_libraries, _error := IndexLibrariesSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_documents, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_tags, _error := IndexTagsSelectAll (_index)
+	if _error != nil {
+		return nil, _error
+	}
+	_status := & IndexStatus {
+			RefreshTimestamp : _index.refreshTimestamp,
+			Libraries : uint (len (_libraries)),
+			Documents : uint (len (_documents)),
+			Tags : uint (len (_tags)),
+			SearchDocuments : uint (len (_index.searchDocuments)),
+			SearchTerms : uint (len (_index.searchTerms)),
+			Diagnostics : uint (len (_index.diagnostics)),
+		}
+	return _status, nil
+}
+
+
 
 
 func IndexLibraryResolve (_index *Index, _identifier string) (*Library, *Error) {
@@ -456,23 +727,50 @@ func IndexDocumentResolve (_index *Index, _identifier string) (*Document, *Error
 	if _identifier == "" {
 		return nil, errorw (0x25e42042, nil)
 	}
-	if _index.librariesRefreshEnabled && (_index.librariesRefreshCallback != nil) {
-		if _error := _index.librariesRefreshCallback (_index); _error != nil {
-			return nil, _error
+	// NOTE:  If the document vanished (i.e. moved outside the tool), retry after the libraries are refreshed.
+	for _attempt := 0; _attempt < 2; _attempt += 1 {
+		if _index.librariesRefreshEnabled && (_index.librariesRefreshCallback != nil) {
+			if _error := _index.librariesRefreshCallback (_index); _error != nil {
+				return nil, _error
+			}
+		}
+		_document, _ := _index.documents[_identifier]
+		if _document == nil {
+			_document = indexDocumentResolveAlias (_index, _identifier)
+		}
+		if _document == nil {
+			return nil, nil
+		}
+		if _index.documentRefreshEnabled && (_index.documentRefreshCallback != nil) {
+			if _document_0, _error := _index.documentRefreshCallback (_index, _document); _error == nil {
+				_document = _document_0
+			} else {
+				return nil, _error
+			}
+		}
+		if _document != nil {
+			return _document, nil
 		}
 	}
-	_document, _ := _index.documents[_identifier]
-	if _document == nil {
-		return nil, nil
-	}
-	if _index.documentRefreshEnabled && (_index.documentRefreshCallback != nil) {
-		if _document_0, _error := _index.documentRefreshCallback (_index, _document); _error == nil {
-			_document = _document_0
+	return nil, nil
+}
+
+
+func indexDocumentResolveAlias (_index *Index, _identifier string) (*Document) {
+	// NOTE:  Moves might be chained (i.e. renamed multiple times), thus follow them, but not indefinitely.
+	for _hop := 0; _hop < 16; _hop += 1 {
+		if _identifier_0, _exists := _index.documentAliases[_identifier]; _exists {
+			_identifier = _identifier_0
+		} else if _identifier_0, _exists := _index.documentMoves[_identifier]; _exists {
+			_identifier = _identifier_0
 		} else {
-			return nil, _error
+			return nil
+		}
+		if _document, _exists := _index.documents[_identifier]; _exists {
+			return _document
 		}
 	}
-	return _document, nil
+	return nil
 }
 
 
@@ -494,6 +792,22 @@ func DocumentsSort (_documents []*Document) () {
 		})
 }
 
+func DocumentsSortByCreated (_documents []*Document) () {
+	sort.SliceStable (_documents, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _documents[_leftIndex]
+			_right := _documents[_rightIndex]
+			return compareByTimestampOrIdentifier (_left.Created, _left.Identifier, _right.Created, _right.Identifier)
+		})
+}
+
+func DocumentsSortByUpdated (_documents []*Document) () {
+	sort.SliceStable (_documents, func (_leftIndex, _rightIndex int) (bool) {
+			_left := _documents[_leftIndex]
+			_right := _documents[_rightIndex]
+			return compareByTimestampOrIdentifier (_left.Updated, _left.Identifier, _right.Updated, _right.Identifier)
+		})
+}
+
 
 func compareByNameOrIdentifier (_leftTitle, _leftIdentifier, _rightTitle, _rightIdentifier string) (bool) {
 	if (_leftTitle != "") && (_rightTitle != "") {
@@ -514,3 +828,12 @@ func compareByNameOrIdentifier (_leftTitle, _leftIdentifier, _rightTitle, _right
 	}
 }
 
+func compareByTimestampOrIdentifier (_leftTimestamp time.Time, _leftIdentifier string, _rightTimestamp time.Time, _rightIdentifier string) (bool) {
+	// NOTE:  Newest first, thus the reversed comparison.
+	if _leftTimestamp.Equal (_rightTimestamp) {
+		return _leftIdentifier < _rightIdentifier
+	} else {
+		return _leftTimestamp.After (_rightTimestamp)
+	}
+}
+
@@ -34,6 +34,10 @@ type Library struct {
 	SnapshotEnabled bool `toml:"snapshot_enabled"`
 	SnapshotExtension string `toml:"snapshot_extension"`
 	
+	EncryptionEnabled bool `toml:"encryption_enabled"`
+	EncryptionIdentityPath string `toml:"encryption_identity_path"`
+	EncryptionIndexEnabled bool `toml:"encryption_index_enabled"`
+	
 	IncludeGlobPatterns []string `toml:"include_glob"`
 	ExcludeGlobPatterns []string `toml:"exclude_glob"`
 	
@@ -155,6 +159,21 @@ func LibraryInitialize (_library *Library) (*Error) {
 		}
 	}
 	
+	if _library.EncryptionIdentityPath != "" {
+		if _path, _error := encryptionIdentityPathResolve (_library.EncryptionIdentityPath); _error == nil {
+			_library.EncryptionIdentityPath = _path
+		} else {
+			return _error
+		}
+	} else {
+		if _library.EncryptionEnabled {
+			return errorw (0x99d97600, nil)
+		}
+		if _library.EncryptionIndexEnabled {
+			return errorw (0x1cb74f42, nil)
+		}
+	}
+	
 	sort.Strings (_library.IncludeGlobPatterns)
 	sort.Strings (_library.ExcludeGlobPatterns)
 	sort.Strings (_library.IncludeRegexPatterns)
@@ -208,51 +227,54 @@ func libraryInitializeMatchers (_library *Library) (*Error) {
 
 
 
-func libraryDocumentsLoad (_library *Library, _documentPaths [][2]string) ([]*Document, *Error) {
+func libraryDocumentsLoad (_library *Library, _documentPaths [][2]string) ([]*Document, []*Diagnostic, *Error) {
 	
 	_documents := make ([]*Document, 0, len (_documentPaths))
+	_diagnostics := []*Diagnostic (nil)
 	
 	for _, _documentPath := range _documentPaths {
-		if _document, _error := DocumentLoadFromPath (_documentPath[0]); _error == nil {
+		if _document, _error := DocumentLoadFromPath (_documentPath[0], _library); _error == nil {
 			if _document == nil {
 				continue
 			}
 			_document.PathInLibrary = _documentPath[1]
 			_documents = append (_documents, _document)
 		} else {
-			return nil, _error
+			_diagnostics = append (_diagnostics, DiagnosticNew (_library, _documentPath[0], _error))
 		}
 	}
 	
-	return _documents, nil
+	return _documents, _diagnostics, nil
 }
 
 
 
 
-func libraryDocumentsWalk (_library *Library) ([][2]string, *Error) {
+func libraryDocumentsWalk (_library *Library) ([][2]string, []*Diagnostic, *Error) {
 	
 	_documentPaths := [][2]string (nil)
+	_diagnostics := []*Diagnostic (nil)
 	for _, _libraryPath := range _library.Paths {
-		if _documentPaths_0, _error := libraryDocumentsWalkPath (_library, _libraryPath); _error == nil {
+		if _documentPaths_0, _diagnostics_0, _error := libraryDocumentsWalkPath (_library, _libraryPath); _error == nil {
 			if _documentPaths == nil {
 				_documentPaths = _documentPaths_0
 			} else {
 				_documentPaths = append (_documentPaths, _documentPaths_0 ...)
 			}
+			_diagnostics = append (_diagnostics, _diagnostics_0 ...)
 		} else {
-			return nil, _error
+			return nil, nil, _error
 		}
 	}
 	
-	return _documentPaths, nil
+	return _documentPaths, _diagnostics, nil
 }
 
 
-func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]string, *Error) {
+func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]string, []*Diagnostic, *Error) {
 	
 	if _libraryPath == "" {
-		return nil, errorw (0x83afc399, nil)
+		return nil, nil, errorw (0x83afc399, nil)
 	}
 	
 	_snapshotSuffix := ""
@@ -262,6 +284,7 @@ func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]str
 	
 	_documentPaths := make ([][2]string, 0, 16 * 1024)
 	_folderPaths := make ([]string, 0, 128)
+	_diagnostics := []*Diagnostic (nil)
 	
 	_walkFunc := func (_pathEntry string, _entry os.DirEntry) (*Error) {
 		
@@ -283,7 +306,8 @@ func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]str
 		if _stat_0, _error := os.Stat (_pathEntry); _error == nil {
 			_stat = _stat_0
 		} else {
-			return errorw (0xb00f4f21, _error)
+			_diagnostics = append (_diagnostics, DiagnosticNew (_library, _pathEntry, errorw (0xb00f4f21, _error)))
+			return nil
 		}
 		
 		_mode := _stat.Mode ()
@@ -294,7 +318,8 @@ func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]str
 			_folderPaths = append (_folderPaths, _pathEntry)
 			return nil
 		} else {
-			return errorf (0xb0cc4319, "invalid entry `%s`", _pathEntry)
+			_diagnostics = append (_diagnostics, DiagnosticNew (_library, _pathEntry, errorf (0xb0cc4319, "invalid entry `%s`", _pathEntry)))
+			return nil
 		}
 		
 		_pathRelative := ""
@@ -374,17 +399,21 @@ func libraryDocumentsWalkPath (_library *Library, _libraryPath string) ([][2]str
 		_folderPath := _folderPaths[_folderIndex]
 		_folderEntries, _error := os.ReadDir (_folderPath)
 		if _error != nil {
-			return nil, errorw (0x28422546, _error)
+			if _folderIndex == 0 {
+				return nil, nil, errorw (0x28422546, _error)
+			}
+			_diagnostics = append (_diagnostics, DiagnosticNew (_library, _folderPath, errorw (0xcf0e846d, _error)))
+			continue
 		}
 		for _, _folderEntry := range _folderEntries {
 			_folderEntryPath := filepath.Join (_folderPath, _folderEntry.Name ())
 			if _error := _walkFunc (_folderEntryPath, _folderEntry); _error != nil {
-				return nil, _error
+				return nil, nil, _error
 			}
 		}
 	}
 	
-	return _documentPaths, nil
+	return _documentPaths, _diagnostics, nil
 }
 
 
@@ -79,13 +79,17 @@ type EditorConfiguration struct {
 
 type ListFlags struct {
 	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Tags []string `long:"tag" value-name:"{tag}"`
 	Type *string `long:"type" short:"t" choice:"library" choice:"document"`
-	What *string `long:"what" short:"w" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"commonmark-link"`
+	What *string `long:"what" short:"w" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"commonmark-link" choice:"document"`
 	Format *string `long:"format" short:"f" choice:"text" choice:"text-sp" choice:"text-0" choice:"json"`
+	Sort *string `long:"sort" choice:"created" choice:"updated"`
 }
 
 type SearchFlags struct {
 	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Tags []string `long:"tag" value-name:"{tag}"`
+	Query *string `long:"query" short:"q" value-name:"{query}"`
 	Type *string `long:"type" short:"t" choice:"library" choice:"document"`
 	What *string `long:"what" short:"w" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"commonmark-link"`
 	How *string `long:"how" short:"W" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"body"`
@@ -96,13 +100,19 @@ type SearchFlags struct {
 
 type GrepFlags struct {
 	Library *string `long:"library" short:"l" value-name:"{identifier}"`
-	What *string `long:"what" short:"w" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"commonmark-link"`
+	Tags []string `long:"tag" value-name:"{tag}"`
+	Query *string `long:"query" short:"q" value-name:"{query}"`
+	What *string `long:"what" short:"w" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"commonmark-link" choice:"lines"`
 	Where *string `long:"where" short:"W" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"body"`
 	Format *string `long:"format" short:"f" choice:"text" choice:"text-sp" choice:"text-0" choice:"json"`
+	Context *uint16 `long:"context" value-name:"{lines}"`
 	Terms []string `long:"term" short:"t" value-name:"{term}"`
+	Sort *string `long:"sort" choice:"created" choice:"updated"`
 	Action *string `long:"action" short:"a" choice:"output" choice:"edit" choice:"export" choice:"browse"`
 	MultipleAllowed *bool `long:"multiple" short:"m"`
 	MatchAny *bool `long:"match-any"`
+	Ranked *bool `long:"ranked"`
+	Stemming *bool `long:"stem"`
 }
 
 
@@ -116,17 +126,99 @@ type EditFlags struct {
 	Library *string `long:"library" short:"l" value-name:"{identifier}"`
 	Document *string `long:"document" short:"d" value-name:"{identifier}"`
 	Select *bool `long:"select" short:"s"`
+	Line *uint `long:"line" value-name:"{line}"`
 }
 
 type ExportFlags struct {
 	Library *string `long:"library" short:"l" value-name:"{identifier}"`
 	Document *string `long:"document" short:"d" value-name:"{identifier}"`
-	Format *string `long:"format" short:"f" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"source" choice:"text" choice:"html" choice:"html-plain" choice:"html-github" choice:"html-github-auto" choice:"html-github-light" choice:"html-github-dark" choice:"html-modest" choice:"html-tufte" choice:"html-body"`
+	Format *string `long:"format" short:"f" choice:"identifier" choice:"title" choice:"name" choice:"path" choice:"source" choice:"text" choice:"html" choice:"html-plain" choice:"html-github" choice:"html-github-auto" choice:"html-github-light" choice:"html-github-dark" choice:"html-modest" choice:"html-tufte" choice:"html-body" choice:"commonmark" choice:"gemini"`
+	Outline *bool `long:"outline"`
+	Select *bool `long:"select" short:"s"`
+}
+
+type ConvertFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Format *string `long:"format" short:"f" choice:"commonmark" choice:"gemini"`
+	InPlace *bool `long:"in-place" short:"i"`
+	Select *bool `long:"select" short:"s"`
+}
+
+type SnippetFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Select *bool `long:"select" short:"s"`
+}
+
+type OutlineFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Edit *bool `long:"edit" short:"e"`
 	Select *bool `long:"select" short:"s"`
 }
 
+type LinksFlags struct {
+	From *string `long:"from" value-name:"{identifier}"`
+	To *string `long:"to" value-name:"{identifier}"`
+	ToLibrary *string `long:"to-library" value-name:"{identifier}"`
+	Format *string `long:"format" short:"f" choice:"text" choice:"json"`
+}
+
+type GraphFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Tags []string `long:"tag" value-name:"{tag}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Depth *uint16 `long:"depth" value-name:"{depth}"`
+	Format *string `long:"format" short:"f" choice:"dot" choice:"graphml" choice:"json"`
+}
+
+type CheckLinksFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Format *string `long:"format" short:"f" choice:"text" choice:"json"`
+}
+
 type DumpFlags struct {}
 
+type DoctorFlags struct {
+	Format *string `long:"format" short:"f" choice:"text" choice:"json"`
+}
+
+
+type MetaFlags struct {
+	Get *MetaGetFlags `command:"get"`
+	Set *MetaSetFlags `command:"set"`
+	Unset *MetaUnsetFlags `command:"unset"`
+}
+
+type MetaGetFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Select *bool `long:"select" short:"s"`
+	Arguments struct {
+		Key string `positional-arg-name:"{key}"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type MetaSetFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Select *bool `long:"select" short:"s"`
+	Arguments struct {
+		Key string `positional-arg-name:"{key}"`
+		Values []string `positional-arg-name:"{value}" required:"1"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type MetaUnsetFlags struct {
+	Library *string `long:"library" short:"l" value-name:"{identifier}"`
+	Document *string `long:"document" short:"d" value-name:"{identifier}"`
+	Select *bool `long:"select" short:"s"`
+	Arguments struct {
+		Key string `positional-arg-name:"{key}"`
+	} `positional-args:"yes" required:"yes"`
+}
+
 
 type ServerFlags struct {
 	UrlBase *string `long:"server-url" value-name:"{url}"`
@@ -192,7 +284,15 @@ type MainFlags struct {
 	Create *CreateFlags `command:"create"`
 	Edit *EditFlags `command:"edit"`
 	Export *ExportFlags `command:"export"`
+	Convert *ConvertFlags `command:"convert"`
+	Snippet *SnippetFlags `command:"snippet"`
+	Outline *OutlineFlags `command:"outline"`
+	Links *LinksFlags `command:"links"`
+	CheckLinks *CheckLinksFlags `command:"check-links"`
+	Graph *GraphFlags `command:"graph"`
 	Dump *DumpFlags `command:"dump"`
+	Meta *MetaFlags `command:"meta"`
+	Doctor *DoctorFlags `command:"doctor"`
 	
 	Server *ServerFlags `command:"server"`
 	Browse *BrowseFlags `command:"browse"`
@@ -259,7 +359,19 @@ func Main (_executable string, _arguments []string, _environment map[string]stri
 			Create : & CreateFlags {},
 			Edit : & EditFlags {},
 			Export : & ExportFlags {},
+			Convert : & ConvertFlags {},
+			Snippet : & SnippetFlags {},
+			Outline : & OutlineFlags {},
+			Links : & LinksFlags {},
+			CheckLinks : & CheckLinksFlags {},
+			Graph : & GraphFlags {},
 			Dump : & DumpFlags {},
+			Meta : & MetaFlags {
+					Get : & MetaGetFlags {},
+					Set : & MetaSetFlags {},
+					Unset : & MetaUnsetFlags {},
+				},
+			Doctor : & DoctorFlags {},
 			
 			Server : & ServerFlags {},
 			Browse : & BrowseFlags {},
@@ -471,6 +583,9 @@ func Main (_executable string, _arguments []string, _environment map[string]stri
 	_command := ""
 	if _parser.Active != nil {
 		_command = _parser.Active.Name
+		if _parser.Active.Active != nil {
+			_command = _command + "-" + _parser.Active.Active.Name
+		}
 	} else {
 		if len (_configuration.Menus) > 0 {
 			_command = "menu"
@@ -652,9 +767,39 @@ func MainWithFlagsAndContext (_command string, _flags *MainFlags, _configuration
 		case "export" :
 			return MainExport (_flags.Export, _globals, _index, _editor)
 		
+		case "convert" :
+			return MainConvert (_flags.Convert, _globals, _index, _editor)
+		
+		case "snippet" :
+			return MainSnippet (_flags.Snippet, _globals, _index, _editor)
+		
+		case "outline" :
+			return MainOutline (_flags.Outline, _globals, _index, _editor)
+		
+		case "links" :
+			return MainLinks (_flags.Links, _globals, _index)
+		
+		case "check-links" :
+			return MainCheckLinks (_flags.CheckLinks, _globals, _index)
+		
+		case "graph" :
+			return MainGraph (_flags.Graph, _globals, _index)
+		
 		case "dump" :
 			return MainDump (_flags.Dump, _globals, _index)
 		
+		case "doctor" :
+			return MainDoctor (_flags.Doctor, _globals, _index)
+		
+		case "meta-get" :
+			return MainMetaGet (_flags.Meta.Get, _globals, _index, _editor)
+		
+		case "meta-set" :
+			return MainMetaSet (_flags.Meta.Set, _globals, _index, _editor)
+		
+		case "meta-unset" :
+			return MainMetaUnset (_flags.Meta.Unset, _globals, _index, _editor)
+		
 		
 		case "server" :
 			return MainServer (_flags.Server, _configuration.Server, _globals, _index, _editor, _browser)
@@ -692,13 +837,33 @@ func MainList (_flags *ListFlags, _globals *Globals, _index *Index) (*Error) {
 	_type := flagStringOrDefault (_flags.Type, "document")
 	_what := flagStringOrDefault (_flags.What, "identifier")
 	_format := flagStringOrDefault (_flags.Format, "text")
+	_sort := flagStringOrDefault (_flags.Sort, "")
+	
+	// NOTE:  Asking for JSON without stating what to output implies the full document metadata.
+	if (_flags.What == nil) && (_format == "json") && (_type == "document") {
+		_what = "document"
+	}
+	
+	if _what == "document" {
+		if _type != "document" {
+			return errorw (0xdf938818, nil)
+		}
+		if _format != "json" {
+			return errorw (0xe15423ba, nil)
+		}
+		_options, _error := mainListOptions (_libraryIdentifier, _flags.Tags, nil, _sort, _type, "identifier", "identifier", _index)
+		if _error != nil {
+			return _error
+		}
+		return mainListOutputDocuments (_options, _sort, _globals, _index)
+	}
 	
-	_options, _error := mainListOptions (_libraryIdentifier, _type, "identifier", _what, _index)
+	_options, _error := mainListOptions (_libraryIdentifier, _flags.Tags, nil, _sort, _type, "identifier", _what, _index)
 	if _error != nil {
 		return _error
 	}
 	
-	return mainListOutput (_options, _format, _globals)
+	return mainListOutput (_options, _format, _sort, _globals)
 }
 
 
@@ -713,6 +878,11 @@ func MainSearch (_flags *SearchFlags, _globals *Globals, _index *Index, _editor
 	_format := flagStringOrDefault (_flags.Format, "text")
 	_action := flagStringOrDefault (_flags.Action, "output")
 	
+	_query, _error := mainQueryParse (_flags.Query)
+	if _error != nil {
+		return _error
+	}
+	
 	switch _action {
 		case "output" :
 			// NOP
@@ -730,7 +900,7 @@ func MainSearch (_flags *SearchFlags, _globals *Globals, _index *Index, _editor
 			return errorw (0x332d42c3, nil)
 	}
 	
-	_selection, _error := mainListOptionsAndSelect (_libraryIdentifier, _type, _how, _what, _index, _editor)
+	_selection, _error := mainListOptionsAndSelect (_libraryIdentifier, _flags.Tags, _query, _type, _how, _what, _index, _editor)
 	if _error != nil {
 		return _error
 	}
@@ -738,7 +908,7 @@ func MainSearch (_flags *SearchFlags, _globals *Globals, _index *Index, _editor
 	switch _action {
 		
 		case "output" :
-			return mainListOutput (_selection, _format, _globals)
+			return mainListOutput (_selection, _format, "", _globals)
 		
 		case "edit", "export", "browse" :
 			switch len (_selection) {
@@ -782,6 +952,42 @@ func MainGrep (_flags *GrepFlags, _globals *Globals, _index *Index, _editor *Edi
 	_format := flagStringOrDefault (_flags.Format, "text")
 	_action := flagStringOrDefault (_flags.Action, "output")
 	_matchAny := flagBoolOrDefault (_flags.MatchAny, false)
+	_sort := flagStringOrDefault (_flags.Sort, "")
+	_ranked := flagBoolOrDefault (_flags.Ranked, false)
+	_stemming := flagBoolOrDefault (_flags.Stemming, false)
+	_context := flagUint16OrDefault (_flags.Context, 0)
+	
+	// NOTE:  The lines are always searched in the body, and are always reported with the document identifier.
+	_lines := _what == "lines"
+	if _lines {
+		if (_flags.Where != nil) && (_where != "body") {
+			return errorf (0x8da62f98, "`--what lines` requires `--where body`!")
+		}
+		if (_format != "text") && (_format != "json") {
+			return errorf (0x607f1bb3, "`--what lines` requires `--format text` or `--format json`!")
+		}
+		_where = "body"
+		_what = "identifier"
+	} else if _flags.Context != nil {
+		return errorf (0x30430cc8, "`--context` requires `--what lines`!")
+	}
+	
+	if _ranked {
+		// NOTE:  The ranking always considers both the title and the body, and it also defines the order.
+		if _flags.Where != nil {
+			return errorf (0x89895e11, "`--ranked` can't be used with `--where`!")
+		}
+		if _flags.Sort != nil {
+			return errorf (0x5c41d302, "`--ranked` can't be used with `--sort`!")
+		}
+	} else if _stemming {
+		return errorf (0xb0f6d256, "`--stem` requires `--ranked`!")
+	}
+	
+	_query, _error := mainQueryParse (_flags.Query)
+	if _error != nil {
+		return _error
+	}
 	
 	switch _action {
 		case "output" :
@@ -804,41 +1010,37 @@ func MainGrep (_flags *GrepFlags, _globals *Globals, _index *Index, _editor *Edi
 		}
 		_terms = append (_terms, _term)
 	}
-	if len (_terms) == 0 {
+	if (len (_terms) == 0) && ((_query == nil) || _ranked || _lines) {
 		return errorw (0xa95cd520, nil)
 	}
 	
-	_options, _error := mainListOptions (_libraryIdentifier, "document", _where, _what, _index)
-	if _error != nil {
-		return _error
-	}
-	
-	_matchExpected := len (_terms)
-	if _matchAny {
-		_matchExpected = 1
-	}
-	
-	_selection := make ([][2]string, 0, len (_options) / 2)
-	for _, _option := range _options {
-		_contents := _option[0]
-		_matchCount := 0
-		for _, _term := range _terms {
-			if strings.Index (_contents, _term) != -1 {
-				_matchCount += 1
-				if _matchCount == _matchExpected {
-					break
-				}
-			}
+	_selection := [][2]string (nil)
+	if _ranked {
+		if _selection_0, _error := mainGrepRanked (_libraryIdentifier, _flags.Tags, _query, _terms, _what, _stemming, _matchAny, _index); _error == nil {
+			_selection = _selection_0
+		} else {
+			return _error
 		}
-		if _matchCount == _matchExpected {
-			_selection = append (_selection, _option)
+		_sort = "ranked"
+	} else {
+		if _selection_0, _error := mainGrepSubstrings (_libraryIdentifier, _flags.Tags, _query, _terms, _sort, _where, _what, _matchAny, _lines, _index); _error == nil {
+			_selection = _selection_0
+		} else {
+			return _error
 		}
 	}
 	
+	// NOTE:  The ranked search folds the case and matches documents containing any of the terms, thus so should the lines.
+	_linesFolded := _ranked
+	_linesMatchAny := _matchAny || _ranked
+	
 	switch _action {
 		
 		case "output" :
-			return mainListOutput (_selection, _format, _globals)
+			if _lines {
+				return mainGrepLinesOutput (_selection, _terms, _linesFolded, _linesMatchAny, uint (_context), _format, _sort, _globals, _index)
+			}
+			return mainListOutput (_selection, _format, _sort, _globals)
 		
 		case "edit", "export", "browse" :
 			switch len (_selection) {
@@ -851,6 +1053,9 @@ func MainGrep (_flags *GrepFlags, _globals *Globals, _index *Index, _editor *Edi
 						return errorw (0x1e4d02e6, nil)
 					}
 			}
+			if (_action == "edit") && (len (_terms) != 0) {
+				return mainGrepEditAt (_selection, _terms, _linesFolded, _linesMatchAny, _index, _editor)
+			}
 			return mainListAction (_selection, _action, _globals, _index, _editor, _browser)
 		
 		default :
@@ -859,6 +1064,211 @@ func MainGrep (_flags *GrepFlags, _globals *Globals, _index *Index, _editor *Edi
 }
 
 
+func mainGrepLinesOutput (_selection [][2]string, _terms []string, _folded bool, _matchAny bool, _context uint, _format string, _sort string, _globals *Globals, _index *Index) (*Error) {
+	
+	_identifiers := make ([]string, 0, len (_selection))
+	_identifiersSet := make (map[string]bool, len (_selection))
+	for _, _option := range _selection {
+		_identifier := _option[1]
+		if _, _exists := _identifiersSet[_identifier]; _exists {
+			continue
+		}
+		_identifiers = append (_identifiers, _identifier)
+		_identifiersSet[_identifier] = true
+	}
+	if _sort == "" {
+		sortfold.Strings (_identifiers)
+	}
+	
+	_lines := make ([]*DocumentGrepLine, 0, 1024)
+	for _, _identifier := range _identifiers {
+		_document, _error := WorkflowDocumentResolve (_identifier, _index)
+		if _error != nil {
+			return _error
+		}
+		_lines = append (_lines, DocumentGrepLines (_document, _terms, _folded, _matchAny, _context) ...)
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	switch _format {
+		
+		// NOTE:  The matches are in the `vimgrep` format, while the context lines and group separators are as `grep` outputs them.
+		case "text" :
+			for _index, _line := range _lines {
+				if (_context > 0) && (_index > 0) {
+					_previous := _lines[_index - 1]
+					if (_previous.Document != _line.Document) || ((_previous.Line + 1) != _line.Line) {
+						_buffer.WriteString ("--\n")
+					}
+				}
+				if _line.Context {
+					fmt.Fprintf (_buffer, "%s-%d-%s\n", _line.Document, _line.Line, _line.Text)
+				} else {
+					fmt.Fprintf (_buffer, "%s:%d:%d:%s\n", _line.Document, _line.Line, _line.Column, _line.Text)
+				}
+			}
+		
+		case "json" :
+			type lineJson struct {
+				Document string `json:"document"`
+				Line uint `json:"line"`
+				Column uint `json:"column,omitempty"`
+				Text string `json:"text"`
+				Context bool `json:"context,omitempty"`
+			}
+			_list := make ([]*lineJson, 0, len (_lines))
+			for _, _line := range _lines {
+				_list = append (_list, & lineJson {
+						Document : _line.Document,
+						Line : _line.Line,
+						Column : _line.Column,
+						Text : _line.Text,
+						Context : _line.Context,
+					})
+			}
+			_encoder := json.NewEncoder (_buffer)
+			if _error := _encoder.Encode (_list); _error != nil {
+				return errorw (0xb2a96300, _error)
+			}
+		
+		default :
+			return errorw (0xb004603f, nil)
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0xccec2028, _error)
+	}
+	
+	return nil
+}
+
+
+// NOTE:  Each document is opened at its first matching line, or at the default position if the terms only matched its title.
+func mainGrepEditAt (_selection [][2]string, _terms []string, _folded bool, _matchAny bool, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifiersSet := make (map[string]bool, len (_selection))
+	for _, _option := range _selection {
+		_identifier := _option[1]
+		if _, _exists := _identifiersSet[_identifier]; _exists {
+			continue
+		}
+		_identifiersSet[_identifier] = true
+		_document, _error := WorkflowDocumentResolve (_identifier, _index)
+		if _error != nil {
+			return _error
+		}
+		_line := DocumentGrepLineFirst (_document, _terms, _folded, _matchAny)
+		if _error := WorkflowDocumentEditAt (_identifier, _line, _index, _editor, true); _error != nil {
+			return _error
+		}
+	}
+	
+	return nil
+}
+
+
+// NOTE:  Without terms, only the query (if any) filters the documents.
+// NOTE:  Only when grouped (i.e. for `--what lines`) the terms can be spread over the options of the same document.
+func mainGrepSubstrings (_libraryIdentifier string, _tags []string, _query *Query, _terms []string, _sort string, _where string, _what string, _matchAny bool, _grouped bool, _index *Index) ([][2]string, *Error) {
+	
+	_options, _error := mainListOptions (_libraryIdentifier, _tags, _query, _sort, "document", _where, _what, _index)
+	if _error != nil {
+		return nil, _error
+	}
+	
+	if len (_terms) == 0 {
+		return _options, nil
+	}
+	
+	_matchExpected := len (_terms)
+	if _matchAny {
+		_matchExpected = 1
+	}
+	
+	if ! _grouped {
+		_selection := make ([][2]string, 0, len (_options) / 2)
+		for _, _option := range _options {
+			_contents := _option[0]
+			_matchCount := 0
+			for _, _term := range _terms {
+				if strings.Index (_contents, _term) != -1 {
+					_matchCount += 1
+					if _matchCount == _matchExpected {
+						break
+					}
+				}
+			}
+			if _matchCount == _matchExpected {
+				_selection = append (_selection, _option)
+			}
+		}
+		return _selection, nil
+	}
+	
+	// NOTE:  When grouped, the terms must be found in the document as a whole (i.e. in any of its options), not in the same option.
+	_found := make (map[string]map[int]bool, len (_options))
+	_matching := make ([]bool, len (_options))
+	for _optionIndex, _option := range _options {
+		_contents := _option[0]
+		_group := _option[1]
+		if _found[_group] == nil {
+			_found[_group] = make (map[int]bool, len (_terms))
+		}
+		for _termIndex, _term := range _terms {
+			if strings.Index (_contents, _term) != -1 {
+				_found[_group][_termIndex] = true
+				_matching[_optionIndex] = true
+			}
+		}
+	}
+	
+	_selection := make ([][2]string, 0, len (_options) / 2)
+	for _optionIndex, _option := range _options {
+		if _matching[_optionIndex] && (len (_found[_option[1]]) >= _matchExpected) {
+			_selection = append (_selection, _option)
+		}
+	}
+	
+	return _selection, nil
+}
+
+
+// NOTE:  Each term is a clause on its own, thus a term made of multiple words is a phrase.
+func mainGrepRanked (_libraryIdentifier string, _tags []string, _query *Query, _terms []string, _what string, _stemming bool, _matchAny bool, _index *Index) ([][2]string, *Error) {
+	
+	// NOTE:  The options are used only to apply the library, tags and query filters, and to obtain the output values.
+	_options, _error := mainListOptions (_libraryIdentifier, _tags, _query, "", "document", "identifier", _what, _index)
+	if _error != nil {
+		return nil, _error
+	}
+	_values := make (map[string][]string, len (_options))
+	for _, _option := range _options {
+		_values[_option[0]] = append (_values[_option[0]], _option[1])
+	}
+	
+	_clauses := make ([]string, 0, len (_terms))
+	for _, _term := range _terms {
+		_clauses = append (_clauses, "\"" + strings.ReplaceAll (_term, "\"", " ") + "\"")
+	}
+	
+	_results, _error := IndexSearchSelect (_index, strings.Join (_clauses, " "), _stemming, _matchAny)
+	if _error != nil {
+		return nil, _error
+	}
+	
+	_selection := make ([][2]string, 0, len (_results))
+	for _, _result := range _results {
+		for _, _value := range _values[_result.Document.Identifier] {
+			_selection = append (_selection, [2]string { _result.Document.Identifier, _value })
+		}
+	}
+	
+	return _selection, nil
+}
+
+
 
 
 func MainCreate (_flags *CreateFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
@@ -889,105 +1299,794 @@ func MainCreate (_flags *CreateFlags, _globals *Globals, _index *Index, _editor
 		return nil
 	}
 	
-	return WorkflowDocumentCreate (_identifier, _index, _editor, true)
-}
-
-
-
-
-func MainEdit (_flags *EditFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	return WorkflowDocumentCreate (_identifier, _index, _editor, true)
+}
+
+
+
+
+func MainEdit (_flags *EditFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	if _flags.Line != nil {
+		return WorkflowDocumentEditAt (_identifier, *_flags.Line, _index, _editor, true)
+	}
+	
+	return WorkflowDocumentEdit (_identifier, _index, _editor, true)
+}
+
+
+
+
+func MainExport (_flags *ExportFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	_format := flagStringOrDefault (_flags.Format, "source")
+	_outline := flagBoolOrDefault (_flags.Outline, false)
+	
+	return mainExportOutput (_identifier, _format, _outline, _globals, _index)
+}
+
+
+func mainExportOutput (_identifier string, _format string, _outline bool, _globals *Globals, _index *Index) (*Error) {
+	
+	_document, _error := WorkflowDocumentResolve (_identifier, _index)
+	if _error != nil {
+		return _error
+	}
+	
+	_buffer := (*bytes.Buffer) (nil)
+	switch _format {
+		
+		case "identifier" :
+			_buffer = bytes.NewBufferString (_document.Identifier)
+		
+		case "title", "name" :
+			_buffer = bytes.NewBufferString (_document.Title)
+		
+		case "path" :
+			_buffer = bytes.NewBufferString (_document.Path)
+		
+		case "source" :
+			if _output, _error := DocumentRenderToSource (_document); _error == nil {
+				_buffer = bytes.NewBufferString (_output)
+			} else {
+				return _error
+			}
+		
+		case "html-body" :
+			if _output, _error := DocumentRenderToHtml (_document, true, _index); _error == nil {
+				_buffer = bytes.NewBufferString (_output)
+			} else {
+				return _error
+			}
+		
+		case "html", "html-plain", "html-github", "html-github-auto", "html-github-light", "html-github-dark", "html-modest", "html-tufte" :
+			if _format == "html" {
+				_format = "html-default"
+			}
+			_theme := _format[5:]
+			_buffer = BytesBufferNewSize (128 * 1024)
+			defer BytesBufferRelease (_buffer)
+			_templates, _error := TemplatesNew ()
+			if _error != nil {
+				return _error
+			}
+			if _error := DocumentRenderToHtmlDocument (_document, true, _theme, _outline, _index, _templates, _buffer); _error != nil {
+				return _error
+			}
+		
+		case "text" :
+			if _output, _error := DocumentRenderToText (_document); _error == nil {
+				_buffer = bytes.NewBufferString (_output)
+			} else {
+				return _error
+			}
+		
+		case "commonmark", "gemini" :
+			_documentConverted := & Document {}
+			*_documentConverted = *_document
+			if _bodyLines, _error := DocumentConvert (_document, _format); _error == nil {
+				_documentConverted.BodyLines = _bodyLines
+				_documentConverted.BodyEmpty = len (_bodyLines) == 0
+				_documentConverted.Format = _format
+			} else {
+				return _error
+			}
+			if _output, _error := DocumentRenderToSource (_documentConverted); _error == nil {
+				_buffer = bytes.NewBufferString (_output)
+			} else {
+				return _error
+			}
+		
+		default :
+			return errorw (0x326240d3, nil)
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0xa797b17f, _error)
+	}
+	
+	return nil
+}
+
+
+
+
+func MainDump (_flags *DumpFlags, _globals *Globals, _index *Index) (*Error) {
+	
+	_documents, _error := IndexDocumentsSelectAll (_index)
+	if _error != nil {
+		return _error
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	for _, _document := range _documents {
+		_buffer.WriteString ("\n")
+		_error = DocumentDump (_buffer, _document, true, false, false)
+		if _error != nil {
+			return _error
+		}
+		_buffer.WriteString ("\n")
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0xbf6a449c, _error)
+	}
+	
+	return nil
+}
+
+
+
+
+func MainDoctor (_flags *DoctorFlags, _globals *Globals, _index *Index) (*Error) {
+	
+	_format := flagStringOrDefault (_flags.Format, "text")
+	
+	// NOTE:  The diagnostics are available only after walking the libraries, thus always walk them.
+	_libraries, _error := IndexLibrariesSelectAll (_index)
+	if _error != nil {
+		return _error
+	}
+	if _error := mainIndexWalkAndLoad (_index, _libraries); _error != nil {
+		return _error
+	}
+	
+	_diagnostics, _error := IndexDiagnosticsSelectAll (_index)
+	if _error != nil {
+		return _error
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	switch _format {
+		
+		case "text" :
+			for _, _diagnostic := range _diagnostics {
+				fmt.Fprintf (_buffer, "%s\n", _diagnostic.Path)
+				fmt.Fprintf (_buffer, "    [%08x]  %s\n", _diagnostic.Error.Code, DiagnosticExplanation (_diagnostic))
+				if _message := DiagnosticMessage (_diagnostic); _message != "" {
+					fmt.Fprintf (_buffer, "    [%08x]  >> %s\n", _diagnostic.Error.Code, _message)
+				}
+			}
+		
+		case "json" :
+			type diagnosticJson struct {
+				Path string `json:"path"`
+				Library string `json:"library,omitempty"`
+				Code string `json:"code"`
+				Explanation string `json:"explanation"`
+				Message string `json:"message,omitempty"`
+			}
+			_list := make ([]diagnosticJson, 0, len (_diagnostics))
+			for _, _diagnostic := range _diagnostics {
+				_list = append (_list, diagnosticJson {
+						Path : _diagnostic.Path,
+						Library : _diagnostic.Library,
+						Code : fmt.Sprintf ("%08x", _diagnostic.Error.Code),
+						Explanation : DiagnosticExplanation (_diagnostic),
+						Message : DiagnosticMessage (_diagnostic),
+					})
+			}
+			_encoder := json.NewEncoder (_buffer)
+			if _error := _encoder.Encode (_list); _error != nil {
+				return errorw (0xc5fe275d, _error)
+			}
+		
+		default :
+			return errorw (0xee7a9adb, nil)
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0xfc2c87f7, _error)
+	}
+	
+	if len (_diagnostics) > 0 {
+		return errorf (0x764b4c46, "found %d problematic library entries!", len (_diagnostics))
+	}
+	
+	return nil
+}
+
+
+
+
+func MainConvert (_flags *ConvertFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	_format := flagStringOrDefault (_flags.Format, "")
+	if _format == "" {
+		return errorw (0x14baa06e, nil)
+	}
+	
+	if flagBoolOrDefault (_flags.InPlace, false) {
+		return WorkflowDocumentConvert (_identifier, _format, _index)
+	}
+	
+	return mainExportOutput (_identifier, _format, false, _globals, _index)
+}
+
+
+
+
+func MainSnippet (_flags *SnippetFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_documents := []*Document (nil)
+	if (_flags.Document != nil) || flagBoolOrDefault (_flags.Select, false) {
+		_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+		if _error != nil {
+			return _error
+		}
+		if _identifier == "" {
+			return nil
+		}
+		if _document, _error := IndexDocumentResolve (_index, _identifier); _error == nil {
+			if _document == nil {
+				return errorw (0xe918c6b7, nil)
+			}
+			if _document.Format != "snippets" {
+				return errorf (0xb329b303, "document `%s` is not in the `snippets` format", _identifier)
+			}
+			_documents = []*Document { _document }
+		} else {
+			return _error
+		}
+	} else if _flags.Library != nil {
+		if _library, _error := LibraryParseIdentifier (*_flags.Library); _error == nil {
+			if _documents_0, _error := IndexDocumentsSelectInLibrary (_index, _library); _error == nil {
+				_documents = _documents_0
+			} else {
+				return _error
+			}
+		} else {
+			return _error
+		}
+	} else {
+		if _documents_0, _error := IndexDocumentsSelectAll (_index); _error == nil {
+			_documents = _documents_0
+		} else {
+			return _error
+		}
+	}
+	
+	_labels := make ([]string, 0, 1024)
+	_snippets := make (map[string]*SnippetTextBlock, 1024)
+	for _, _document := range _documents {
+		if _document.Format != "snippets" {
+			continue
+		}
+		_blocks, _error := parseSnippetsTextBlocks (_document.BodyLines)
+		if _error != nil {
+			return _error
+		}
+		_title := _document.Title
+		if _title == "" {
+			_title = "[" + _document.Identifier + "]"
+		}
+		for _, _block := range _blocks {
+			_line := ""
+			for _, _line_0 := range _block.Lines {
+				if _line_0 = stringTrimSpaces (_line_0); _line_0 != "" {
+					_line = _line_0
+					break
+				}
+			}
+			_label := fmt.Sprintf ("%s | %s", _title, _line)
+			for _count := 2; ; _count += 1 {
+				if _, _exists := _snippets[_label]; !_exists {
+					break
+				}
+				_label = fmt.Sprintf ("%s | %s | #%d", _title, _line, _count)
+			}
+			_labels = append (_labels, _label)
+			_snippets[_label] = _block
+		}
+	}
+	
+	if len (_labels) == 0 {
+		return errorf (0x92941946, "no snippets found!")
+	}
+	
+	_selection, _error := EditorSelect (_editor, _labels)
+	if _error != nil {
+		return _error
+	}
+	
+	_block := (*SnippetTextBlock) (nil)
+	switch len (_selection) {
+		case 0 :
+			return nil
+		case 1 :
+			if _block_0, _exists := _snippets[_selection[0]]; _exists {
+				_block = _block_0
+			} else {
+				return errorw (0xd60ec299, nil)
+			}
+		default :
+			return errorw (0x1a5dc728, nil)
+	}
+	
+	_placeholders := SnippetPlaceholders (_block.Lines)
+	_values := make (map[string]string, len (_placeholders))
+	for _, _placeholder := range _placeholders {
+		if _value, _ok, _error := EditorInput (_editor, _placeholder.Name, _placeholder.Default); _error == nil {
+			if !_ok {
+				return nil
+			}
+			_values[_placeholder.Name] = _value
+		} else {
+			return _error
+		}
+	}
+	
+	_snippet := strings.Join (_block.Lines, "\n") + "\n"
+	_snippet = SnippetPlaceholdersFill (_snippet, _values)
+	
+	return EditorClipboardStore (_editor, _snippet)
+}
+
+
+
+
+func MainOutline (_flags *OutlineFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	_document, _error := WorkflowDocumentResolve (_identifier, _index)
+	if _error != nil {
+		return _error
+	}
+	
+	_outline, _error := DocumentOutline (_document, _index)
+	if _error != nil {
+		return _error
+	}
+	_lines := DocumentOutlineRenderText (_outline)
+	
+	if ! flagBoolOrDefault (_flags.Edit, false) {
+		_buffer := BytesBufferNewSize (16 * 1024)
+		defer BytesBufferRelease (_buffer)
+		for _, _line := range _lines {
+			_buffer.WriteString (_line)
+			_buffer.WriteByte ('\n')
+		}
+		if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+			return errorw (0x31e25174, _error)
+		}
+		return nil
+	}
+	
+	if len (_outline) == 0 {
+		return errorf (0x4d94855a, "no headings found!")
+	}
+	
+	_labels := make ([]string, 0, len (_outline))
+	_entries := make (map[string]*DocumentOutlineEntry, len (_outline))
+	for _index, _entry := range _outline {
+		_label := _lines[_index]
+		for _count := 2; ; _count += 1 {
+			if _, _exists := _entries[_label]; !_exists {
+				break
+			}
+			_label = fmt.Sprintf ("%s | #%d", _lines[_index], _count)
+		}
+		_labels = append (_labels, _label)
+		_entries[_label] = _entry
+	}
+	
+	_selection, _error := EditorSelect (_editor, _labels)
+	if _error != nil {
+		return _error
+	}
+	
+	_entry := (*DocumentOutlineEntry) (nil)
+	switch len (_selection) {
+		case 0 :
+			return nil
+		case 1 :
+			if _entry_0, _exists := _entries[_selection[0]]; _exists {
+				_entry = _entry_0
+			} else {
+				return errorw (0xdf213ca0, nil)
+			}
+		default :
+			return errorw (0x3207d9e1, nil)
+	}
+	
+	// NOTE:  If the heading line couldn't be determined, the document is opened at the default position.
+	return WorkflowDocumentEditAt (_identifier, _entry.Line, _index, _editor, true)
+}
+
+
+
+
+func MainLinks (_flags *LinksFlags, _globals *Globals, _index *Index) (*Error) {
+	
+	_format := flagStringOrDefault (_flags.Format, "text")
+	
+	_count := 0
+	for _, _flag := range []*string { _flags.From, _flags.To, _flags.ToLibrary } {
+		if _flag != nil {
+			_count += 1
+		}
+	}
+	if _count != 1 {
+		return errorf (0x8927daf6, "exactly one of `--from`, `--to` or `--to-library` is required!")
+	}
+	
+	type linkJson struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+		Type string `json:"type"`
+		Labels []string `json:"labels"`
+	}
+	_links := make ([]linkJson, 0, 128)
+	_lines := make ([]string, 0, 128)
+	
+	if _flags.From != nil {
+		
+		_document, _error := WorkflowDocumentResolve (*_flags.From, _index)
+		if _error != nil {
+			return _error
+		}
+		_documentLinks, _error := IndexDocumentLinksSelect (_index, _document.Identifier)
+		if _error != nil {
+			return _error
+		}
+		
+		for _, _type := range []string { "document", "library" } {
+			_targets := _documentLinks.Documents
+			_prefix := "sd:"
+			if _type == "library" {
+				_targets = _documentLinks.Libraries
+				_prefix = "sl:"
+			}
+			_targetsSorted := make ([]string, 0, len (_targets))
+			for _target, _ := range _targets {
+				_targetsSorted = append (_targetsSorted, _target)
+			}
+			sortfold.Strings (_targetsSorted)
+			for _, _target := range _targetsSorted {
+				_links = append (_links, linkJson { _document.Identifier, _target, _type, _targets[_target] })
+				_lines = append (_lines, _prefix + _target)
+			}
+		}
+		
+	} else {
+		
+		_target := ""
+		_type := ""
+		_sources := []string (nil)
+		if _flags.To != nil {
+			_target = *_flags.To
+			_type = "document"
+			if _document, _error := IndexDocumentResolve (_index, _target); _error == nil {
+				if _document != nil {
+					_target = _document.Identifier
+				}
+			} else {
+				return _error
+			}
+			if _sources_0, _error := IndexDocumentBacklinksSelect (_index, _target); _error == nil {
+				_sources = _sources_0
+			} else {
+				return _error
+			}
+		} else {
+			_target = *_flags.ToLibrary
+			_type = "library"
+			if _sources_0, _error := IndexLibraryBacklinksSelect (_index, _target); _error == nil {
+				_sources = _sources_0
+			} else {
+				return _error
+			}
+		}
+		
+		for _, _source := range _sources {
+			_documentLinks, _error := IndexDocumentLinksSelect (_index, _source)
+			if _error != nil {
+				return _error
+			}
+			_labels := _documentLinks.Documents[_target]
+			if _type == "library" {
+				_labels = _documentLinks.Libraries[_target]
+			}
+			_links = append (_links, linkJson { _source, _target, _type, _labels })
+			_lines = append (_lines, _source)
+		}
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	switch _format {
+		
+		case "text" :
+			for _, _line := range _lines {
+				_buffer.WriteString (_line)
+				_buffer.WriteByte ('\n')
+			}
+		
+		case "json" :
+			for _index, _ := range _links {
+				if _links[_index].Labels == nil {
+					_links[_index].Labels = []string {}
+				}
+			}
+			_encoder := json.NewEncoder (_buffer)
+			if _error := _encoder.Encode (_links); _error != nil {
+				return errorw (0xb9985022, _error)
+			}
+		
+		default :
+			return errorw (0x5b63207c, nil)
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0x91061850, _error)
+	}
+	
+	return nil
+}
+
+
+
+
+func MainCheckLinks (_flags *CheckLinksFlags, _globals *Globals, _index *Index) (*Error) {
+	
+	_libraryIdentifier := flagStringOrDefault (_flags.Library, "")
+	_format := flagStringOrDefault (_flags.Format, "text")
+	
+	_documents := []*Document (nil)
+	if _libraryIdentifier != "" {
+		if _documents_0, _error := IndexDocumentsSelectInLibrary (_index, _libraryIdentifier); _error == nil {
+			_documents = _documents_0
+		} else {
+			return _error
+		}
+	} else {
+		if _documents_0, _error := IndexDocumentsSelectAll (_index); _error == nil {
+			_documents = _documents_0
+		} else {
+			return _error
+		}
+	}
+	
+	_anchorsCache := make (map[string]map[string]bool, len (_documents))
+	_broken := make ([]*DocumentLinkBroken, 0, 128)
+	for _, _document := range _documents {
+		_documentBroken, _error := DocumentLinksCheck (_document, _index, _anchorsCache)
+		if _error != nil {
+			return _error
+		}
+		_broken = append (_broken, _documentBroken ...)
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
 	
-	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
-	if _error != nil {
-		return _error
+	switch _format {
+		
+		case "text" :
+			for _, _link := range _broken {
+				fmt.Fprintf (_buffer, "%s:%d:  [%s]  `%s`  (%s)\n", _link.Path, _link.Line, _link.Document, _link.Url, _link.Label)
+				fmt.Fprintf (_buffer, "    >> %s\n", _link.Reason)
+			}
+		
+		case "json" :
+			type brokenJson struct {
+				Document string `json:"document"`
+				Path string `json:"path"`
+				Line uint `json:"line,omitempty"`
+				Label string `json:"label"`
+				Url string `json:"url"`
+				Reason string `json:"reason"`
+			}
+			_list := make ([]brokenJson, 0, len (_broken))
+			for _, _link := range _broken {
+				_list = append (_list, brokenJson (*_link))
+			}
+			_encoder := json.NewEncoder (_buffer)
+			if _error := _encoder.Encode (_list); _error != nil {
+				return errorw (0x092bf256, _error)
+			}
+		
+		default :
+			return errorw (0x0b14e67c, nil)
 	}
-	if _identifier == "" {
-		return nil
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0x0d3d9ac1, _error)
 	}
 	
-	return WorkflowDocumentEdit (_identifier, _index, _editor, true)
+	if len (_broken) > 0 {
+		return errorf (0x5be31f03, "found %d broken links!", len (_broken))
+	}
+	
+	return nil
 }
 
 
 
 
-func MainExport (_flags *ExportFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+func MainGraph (_flags *GraphFlags, _globals *Globals, _index *Index) (*Error) {
 	
-	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
-	if _error != nil {
-		return _error
+	_libraryIdentifier := flagStringOrDefault (_flags.Library, "")
+	_documentIdentifier := flagStringOrDefault (_flags.Document, "")
+	_depth := flagUint16OrDefault (_flags.Depth, 1)
+	_format := flagStringOrDefault (_flags.Format, "dot")
+	
+	if (_flags.Depth != nil) && (_documentIdentifier == "") {
+		return errorf (0x3630c664, "`--depth` requires `--document`!")
 	}
-	if _identifier == "" {
-		return nil
+	
+	_tags := make ([]string, 0, len (_flags.Tags))
+	for _, _tag := range _flags.Tags {
+		if _tag_0, _error := DocumentParseTag (_tag); _error == nil {
+			_tags = append (_tags, _tag_0)
+		} else {
+			return _error
+		}
 	}
 	
-	_format := flagStringOrDefault (_flags.Format, "source")
+	_documents := []*Document (nil)
+	if _libraryIdentifier != "" {
+		_library, _error := WorkflowLibraryResolve (_libraryIdentifier, _index)
+		if _error != nil {
+			return _error
+		}
+		if _documents_0, _error := IndexDocumentsSelectInLibrary (_index, _library.Identifier); _error == nil {
+			_documents = _documents_0
+		} else {
+			return _error
+		}
+	} else {
+		if _documents_0, _error := IndexDocumentsSelectAll (_index); _error == nil {
+			_documents = _documents_0
+		} else {
+			return _error
+		}
+	}
 	
-	return mainExportOutput (_identifier, _format, _globals, _index)
-}
-
-
-func mainExportOutput (_identifier string, _format string, _globals *Globals, _index *Index) (*Error) {
+	if len (_tags) > 0 {
+		_documentsWithTags := make ([]*Document, 0, len (_documents))
+		for _, _document := range _documents {
+			if DocumentHasTags (_document, _tags) {
+				_documentsWithTags = append (_documentsWithTags, _document)
+			}
+		}
+		_documents = _documentsWithTags
+	}
 	
-	_document, _error := WorkflowDocumentResolve (_identifier, _index)
+	_center := (*Document) (nil)
+	if _documentIdentifier != "" {
+		if _document, _error := WorkflowDocumentResolve (_documentIdentifier, _index); _error == nil {
+			_center = _document
+		} else {
+			return _error
+		}
+	}
+	
+	_graph, _error := DocumentGraphBuild (_index, _documents, _center, uint (_depth))
 	if _error != nil {
 		return _error
 	}
 	
-	_buffer := (*bytes.Buffer) (nil)
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
 	switch _format {
 		
-		case "identifier" :
-			_buffer = bytes.NewBufferString (_document.Identifier)
-		
-		case "title", "name" :
-			_buffer = bytes.NewBufferString (_document.Title)
+		case "dot" :
+			DocumentGraphRenderDot (_graph, _buffer)
 		
-		case "path" :
-			_buffer = bytes.NewBufferString (_document.Path)
+		case "graphml" :
+			DocumentGraphRenderGraphml (_graph, _buffer)
 		
-		case "source" :
-			if _output, _error := DocumentRenderToSource (_document); _error == nil {
-				_buffer = bytes.NewBufferString (_output)
-			} else {
-				return _error
+		case "json" :
+			type nodeJson struct {
+				Identifier string `json:"identifier"`
+				Title string `json:"title,omitempty"`
+				Library string `json:"library"`
+				Depth *uint `json:"depth,omitempty"`
+				LinksIn uint `json:"links_in"`
+				LinksOut uint `json:"links_out"`
 			}
-		
-		case "html-body" :
-			if _output, _error := DocumentRenderToHtml (_document, true); _error == nil {
-				_buffer = bytes.NewBufferString (_output)
-			} else {
-				return _error
+			type edgeJson struct {
+				Source string `json:"source"`
+				Target string `json:"target"`
+				Labels []string `json:"labels"`
 			}
-		
-		case "html", "html-plain", "html-github", "html-github-auto", "html-github-light", "html-github-dark", "html-modest", "html-tufte" :
-			if _format == "html" {
-				_format = "html-default"
+			type graphJson struct {
+				Nodes []nodeJson `json:"nodes"`
+				Edges []edgeJson `json:"edges"`
 			}
-			_theme := _format[5:]
-			_buffer = BytesBufferNewSize (128 * 1024)
-			defer BytesBufferRelease (_buffer)
-			_templates, _error := TemplatesNew ()
-			if _error != nil {
-				return _error
+			_json := graphJson {
+					Nodes : make ([]nodeJson, 0, len (_graph.Nodes)),
+					Edges : make ([]edgeJson, 0, len (_graph.Edges)),
+				}
+			for _, _node := range _graph.Nodes {
+				_nodeJson := nodeJson {
+						Identifier : _node.Document.Identifier,
+						Title : _node.Document.Title,
+						Library : _node.Document.Library,
+						LinksIn : _node.LinksIn,
+						LinksOut : _node.LinksOut,
+					}
+				if _graph.Center != "" {
+					_nodeDepth := _node.Depth
+					_nodeJson.Depth = & _nodeDepth
+				}
+				_json.Nodes = append (_json.Nodes, _nodeJson)
 			}
-			if _error := DocumentRenderToHtmlDocument (_document, true, _theme, _templates, _buffer); _error != nil {
-				return _error
+			for _, _edge := range _graph.Edges {
+				_labels := _edge.Labels
+				if _labels == nil {
+					_labels = []string {}
+				}
+				_json.Edges = append (_json.Edges, edgeJson { _edge.Source, _edge.Target, _labels })
 			}
-		
-		case "text" :
-			if _output, _error := DocumentRenderToText (_document); _error == nil {
-				_buffer = bytes.NewBufferString (_output)
-			} else {
-				return _error
+			_encoder := json.NewEncoder (_buffer)
+			if _error := _encoder.Encode (_json); _error != nil {
+				return errorw (0x126059d7, _error)
 			}
 		
 		default :
-			return errorw (0x326240d3, nil)
+			return errorw (0x7b8b9201, nil)
 	}
 	
 	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
-		return errorw (0xa797b17f, _error)
+		return errorw (0xc8d353ab, _error)
 	}
 	
 	return nil
@@ -996,32 +2095,73 @@ func mainExportOutput (_identifier string, _format string, _globals *Globals, _i
 
 
 
-func MainDump (_flags *DumpFlags, _globals *Globals, _index *Index) (*Error) {
+func MainMetaGet (_flags *MetaGetFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
 	
-	_documents, _error := IndexDocumentsSelectAll (_index)
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	_document, _error := WorkflowDocumentResolve (_identifier, _index)
 	if _error != nil {
 		return _error
 	}
 	
-	_buffer := BytesBufferNewSize (128 * 1024)
+	_values, _error := DocumentHeaderValues (_document, _flags.Arguments.Key)
+	if _error != nil {
+		return _error
+	}
+	
+	_buffer := BytesBufferNewSize (4 * 1024)
 	defer BytesBufferRelease (_buffer)
-	for _, _document := range _documents {
-		_buffer.WriteString ("\n")
-		_error = DocumentDump (_buffer, _document, true, false, false)
-		if _error != nil {
-			return _error
-		}
-		_buffer.WriteString ("\n")
+	for _, _value := range _values {
+		_buffer.WriteString (_value)
+		_buffer.WriteByte ('\n')
 	}
 	
 	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
-		return errorw (0xbf6a449c, _error)
+		return errorw (0x4ae741cc, _error)
 	}
 	
 	return nil
 }
 
 
+func MainMetaSet (_flags *MetaSetFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	if len (_flags.Arguments.Values) == 0 {
+		return errorw (0xf47c4bbd, nil)
+	}
+	
+	return WorkflowDocumentHeaderUpdate (_identifier, _flags.Arguments.Key, _flags.Arguments.Values, _index)
+}
+
+
+func MainMetaUnset (_flags *MetaUnsetFlags, _globals *Globals, _index *Index, _editor *Editor) (*Error) {
+	
+	_identifier, _error := mainResolveDocumentIdentifier (_flags.Library, _flags.Document, _flags.Select, _index, _editor)
+	if _error != nil {
+		return _error
+	}
+	if _identifier == "" {
+		return nil
+	}
+	
+	return WorkflowDocumentHeaderUpdate (_identifier, _flags.Arguments.Key, nil, _index)
+}
+
+
 
 
 func mainResolveLibraryIdentifier (_libraryFlag *string, _selectFlag *bool, _index *Index, _editor *Editor) (string, *Error) {
@@ -1035,7 +2175,7 @@ func mainResolveLibraryIdentifier (_libraryFlag *string, _selectFlag *bool, _ind
 	
 	if _select {
 		
-		_options, _error := mainListOptionsAndSelect ("", "library", "title", "identifier", _index, _editor)
+		_options, _error := mainListOptionsAndSelect ("", nil, nil, "library", "title", "identifier", _index, _editor)
 		if _error != nil {
 			return "", _error
 		}
@@ -1078,7 +2218,7 @@ func mainResolveDocumentIdentifier (_libraryFlag *string, _documentFlag *string,
 	if _select {
 		
 		_libraryIdentifier := flagStringOrDefault (_libraryFlag, "")
-		_options, _error := mainListOptionsAndSelect (_libraryIdentifier, "document", "title", "identifier", _index, _editor)
+		_options, _error := mainListOptionsAndSelect (_libraryIdentifier, nil, nil, "document", "title", "identifier", _index, _editor)
 		if _error != nil {
 			return "", _error
 		}
@@ -1144,9 +2284,9 @@ func mainMergeLibraryAndDocumentIdentifiers (_library *string, _document *string
 
 
 
-func mainListOptionsAndSelect (_libraryIdentifier string, _type string, _labelSource string, _valueSource string, _index *Index, _editor *Editor) ([][2]string, *Error) {
+func mainListOptionsAndSelect (_libraryIdentifier string, _tags []string, _query *Query, _type string, _labelSource string, _valueSource string, _index *Index, _editor *Editor) ([][2]string, *Error) {
 	
-	_options, _error := mainListOptions (_libraryIdentifier, _type, _labelSource, _valueSource, _index)
+	_options, _error := mainListOptions (_libraryIdentifier, _tags, _query, "", _type, _labelSource, _valueSource, _index)
 	if _error != nil {
 		return nil, _error
 	}
@@ -1160,7 +2300,7 @@ func mainListOptionsAndSelect (_libraryIdentifier string, _type string, _labelSo
 }
 
 
-func mainListOptions (_libraryIdentifier string, _type string, _labelSource string, _valueSource string, _index *Index) ([][2]string, *Error) {
+func mainListOptions (_libraryIdentifier string, _tagsUnsafe []string, _query *Query, _sort string, _type string, _labelSource string, _valueSource string, _index *Index) ([][2]string, *Error) {
 	
 	_library := (*Library) (nil)
 	if _libraryIdentifier != "" {
@@ -1171,12 +2311,31 @@ func mainListOptions (_libraryIdentifier string, _type string, _labelSource stri
 		}
 	}
 	
+	_tags := make ([]string, 0, len (_tagsUnsafe))
+	for _, _tag := range _tagsUnsafe {
+		if _tag_0, _error := DocumentParseTag (_tag); _error == nil {
+			_tags = append (_tags, _tag_0)
+		} else {
+			return nil, _error
+		}
+	}
+	
 	_options := make ([][2]string, 0, 1024)
 	
 	switch _type {
 		
 		case "libraries", "library" :
 			
+			if len (_tags) != 0 {
+				return nil, errorw (0xe0616b21, nil)
+			}
+			if _query != nil {
+				return nil, errorf (0x178aa71f, "queries can't be used for libraries!")
+			}
+			if _sort != "" {
+				return nil, errorw (0x52b6bc6d, nil)
+			}
+			
 			_libraries := []*Library (nil)
 			if _library != nil {
 				_libraries = []*Library { _library }
@@ -1268,8 +2427,26 @@ func mainListOptions (_libraryIdentifier string, _type string, _labelSource stri
 				}
 			}
 			
+			switch _sort {
+				case "" :
+					// NOP
+				case "created" :
+					DocumentsSortByCreated (_documents)
+				case "updated" :
+					DocumentsSortByUpdated (_documents)
+				default :
+					return nil, errorw (0xc4f7f818, nil)
+			}
+			
 			for _, _document := range _documents {
 				
+				if ! DocumentHasTags (_document, _tags) {
+					continue
+				}
+				if ! QueryMatches (_query, _document) {
+					continue
+				}
+				
 				_label := ""
 				_labels := make ([]string, 0, 16)
 				switch _labelSource {
@@ -1399,7 +2576,23 @@ func mainListSelect (_options [][2]string, _editor *Editor) ([][2]string, *Error
 	
 	sortfold.Strings (_labels)
 	
-	_selection_0, _error := EditorSelect (_editor, _labels)
+	// NOTE:  The preview is available only for labels with a single value that resolves to a document.
+	_preview := func (_labelDisplayed string) (string) {
+			_values_0 := _values[_labelsMap[_labelDisplayed]]
+			if len (_values_0) != 1 {
+				return ""
+			}
+			for _value, _ := range _values_0 {
+				if _document, _error := WorkflowDocumentResolve (_value, _editor.index); _error == nil {
+					if _text, _error := DocumentRenderToText (_document); _error == nil {
+						return _text
+					}
+				}
+			}
+			return ""
+		}
+	
+	_selection_0, _error := EditorSelectWithPreview (_editor, _labels, _preview)
 	if _error != nil {
 		return nil, _error
 	}
@@ -1424,7 +2617,7 @@ func mainListSelect (_options [][2]string, _editor *Editor) ([][2]string, *Error
 }
 
 
-func mainListOutput (_options [][2]string, _format string, _globals *Globals) (*Error) {
+func mainListOutput (_options [][2]string, _format string, _sort string, _globals *Globals) (*Error) {
 	
 	_list := make ([]string, 0, len (_options))
 	_listSet := make (map[string]bool, len (_options))
@@ -1437,7 +2630,9 @@ func mainListOutput (_options [][2]string, _format string, _globals *Globals) (*
 		_listSet[_value] = true
 	}
 	
-	sortfold.Strings (_list)
+	if _sort == "" {
+		sortfold.Strings (_list)
+	}
 	
 	_buffer := BytesBufferNewSize (128 * 1024)
 	defer BytesBufferRelease (_buffer)
@@ -1482,6 +2677,82 @@ func mainListOutput (_options [][2]string, _format string, _globals *Globals) (*
 	return nil
 }
 
+func mainListOutputDocuments (_options [][2]string, _sort string, _globals *Globals, _index *Index) (*Error) {
+	
+	type documentJson struct {
+		Identifier string `json:"identifier"`
+		Library string `json:"library"`
+		Aliases []string `json:"aliases,omitempty"`
+		Title string `json:"title,omitempty"`
+		Titles []string `json:"titles,omitempty"`
+		Format string `json:"format"`
+		Path string `json:"path,omitempty"`
+		Tags []string `json:"tags,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+		Timestamp string `json:"timestamp,omitempty"`
+		Created string `json:"created,omitempty"`
+		Updated string `json:"updated,omitempty"`
+	}
+	
+	_list := make ([]string, 0, len (_options))
+	_listSet := make (map[string]bool, len (_options))
+	for _, _option := range _options {
+		_value := _option[1]
+		if _, _exists := _listSet[_value]; _exists {
+			continue
+		}
+		_list = append (_list, _value)
+		_listSet[_value] = true
+	}
+	
+	if _sort == "" {
+		sortfold.Strings (_list)
+	}
+	
+	_records := make ([]*documentJson, 0, len (_list))
+	for _, _identifier := range _list {
+		_document, _error := WorkflowDocumentResolve (_identifier, _index)
+		if _error != nil {
+			return _error
+		}
+		_record := & documentJson {
+				Identifier : _document.Identifier,
+				Library : _document.Library,
+				Aliases : _document.Aliases,
+				Title : _document.Title,
+				Titles : _document.TitleAlternatives,
+				Format : _document.Format,
+				Path : _document.Path,
+				Tags : _document.Tags,
+				Metadata : _document.Metadata,
+			}
+		if ! _document.Timestamp.IsZero () {
+			_record.Timestamp = _document.Timestamp.Format (time.RFC3339)
+		}
+		if ! _document.Created.IsZero () {
+			_record.Created = _document.Created.Format (time.RFC3339)
+		}
+		if ! _document.Updated.IsZero () {
+			_record.Updated = _document.Updated.Format (time.RFC3339)
+		}
+		_records = append (_records, _record)
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	_encoder := json.NewEncoder (_buffer)
+	if _error := _encoder.Encode (_records); _error != nil {
+		return errorw (0xcd496d8b, _error)
+	}
+	
+	if _, _error := _buffer.WriteTo (_globals.Stdout); _error != nil {
+		return errorw (0xf8a8887a, _error)
+	}
+	
+	return nil
+}
+
 
 func mainListAction (_options [][2]string, _action string, _globals *Globals, _index *Index, _editor *Editor, _browser *Browser) (*Error) {
 	
@@ -1505,7 +2776,7 @@ func mainListAction (_options [][2]string, _action string, _globals *Globals, _i
 				_error = WorkflowDocumentBrowse (_identifier, _index, _browser, true)
 			case "export" :
 				// FIXME:  Add support for other formats!
-				_error = mainExportOutput (_identifier, "source", _globals, _index)
+				_error = mainExportOutput (_identifier, "source", false, _globals, _index)
 			default :
 				return errorw (0xb5fa0b59, nil)
 		}
@@ -2025,11 +3296,23 @@ func mainIndexLoad (_index *Index, _libraries []*Library, _databasePath string,
 		}
 	}
 	
+	if _databaseShouldWalk && !_databaseLoaded && _databaseCanLoad && _index.refreshTimestamp.IsZero () {
+		// NOTE:  Load the previous database (even if dirty) only to preserve the document moves.
+		if _, _error := os.Stat (_databasePath); _error == nil {
+			if _, _error := IndexLoadFromPath (_index, _databasePath); _error != nil {
+				logError ('w', _error)
+			}
+		}
+	}
+	
 	if _databaseShouldWalk {
 		_databaseTimestamp = time.Now ()
 		if _error := mainIndexWalkAndLoad (_index, _libraries); _error != nil {
 			return _error
 		}
+		if len (_index.diagnostics) > 0 {
+			logf ('w', 0xbcf13088, "skipped %d problematic library entries;  (use the `doctor` command for details;)", len (_index.diagnostics))
+		}
 //		logf ('d', 0xb6d41aa3, "index database walked;")
 		_databaseLoaded = true
 	}
@@ -2059,17 +3342,21 @@ func mainIndexLoad (_index *Index, _libraries []*Library, _databasePath string,
 
 func mainIndexWalkAndLoad (_index *Index, _libraries []*Library) (*Error) {
 	
-	_documentPaths, _error := mainLibrariesWalk (_libraries)
+	_documentPaths, _diagnosticsWalk, _error := mainLibrariesWalk (_libraries)
 	if _error != nil {
 		return _error
 	}
 	
-	_documents, _error := mainLibrariesLoad (_libraries, _documentPaths)
+	_documents, _diagnosticsLoad, _error := mainLibrariesLoad (_libraries, _documentPaths)
 	if _error != nil {
 		return _error
 	}
 	
-	_error = mainLibrariesInclude (_index, _libraries, _documents)
+	_diagnostics := make ([]*Diagnostic, 0, len (_diagnosticsWalk) + len (_diagnosticsLoad))
+	_diagnostics = append (_diagnostics, _diagnosticsWalk ...)
+	_diagnostics = append (_diagnostics, _diagnosticsLoad ...)
+	
+	_error = mainLibrariesInclude (_index, _libraries, _documents, _diagnostics)
 	if _error != nil {
 		return _error
 	}
@@ -2128,38 +3415,43 @@ func mainLibrariesResolve (_flags *LibraryFlags, _configuration []*Library) ([]*
 }
 
 
-func mainLibrariesWalk (_libraries []*Library) ([][][2]string, *Error) {
+func mainLibrariesWalk (_libraries []*Library) ([][][2]string, []*Diagnostic, *Error) {
 	
 	_documents := make ([][][2]string, 0, len (_libraries))
+	_diagnostics := []*Diagnostic (nil)
 	
 	for _, _library := range _libraries {
 		
-		_libraryDocuments, _error := libraryDocumentsWalk (_library)
+		_libraryDocuments, _libraryDiagnostics, _error := libraryDocumentsWalk (_library)
 		if _error != nil {
-			return nil, _error
+			return nil, nil, _error
 		}
 		
 		_documents = append (_documents, _libraryDocuments)
+		_diagnostics = append (_diagnostics, _libraryDiagnostics ...)
 	}
 	
-	return _documents, nil
+	return _documents, _diagnostics, nil
 }
 
 
-func mainLibrariesLoad (_libraries []*Library, _libraryDocuments [][][2]string) ([]*Document, *Error) {
+func mainLibrariesLoad (_libraries []*Library, _libraryDocuments [][][2]string) ([]*Document, []*Diagnostic, *Error) {
 	
 	_documents := make ([]*Document, 0, 16 * 1024)
+	_diagnostics := []*Diagnostic (nil)
 	
 	for _libraryIndex := range _libraries {
 		
 		_library := _libraries[_libraryIndex]
 		_libraryDocumentPaths := _libraryDocuments[_libraryIndex]
 		
-		_libraryDocuments, _error := libraryDocumentsLoad (_library, _libraryDocumentPaths)
+		_libraryDocuments, _libraryDiagnostics, _error := libraryDocumentsLoad (_library, _libraryDocumentPaths)
 		if _error != nil {
-			return nil, _error
+			return nil, nil, _error
 		}
 		
+		_diagnostics = append (_diagnostics, _libraryDiagnostics ...)
+		
 		for _, _document := range _libraryDocuments {
 			
 			if _document.Library == "" {
@@ -2170,28 +3462,31 @@ func mainLibrariesLoad (_libraries []*Library, _libraryDocuments [][][2]string)
 			
 			_error = DocumentInitializeIdentifier (_document, _library)
 			if _error != nil {
-				return nil, _error
+				_diagnostics = append (_diagnostics, DiagnosticNew (_library, _document.Path, _error))
+				continue
 			}
 			
 			_error = DocumentInitializeFormat (_document, _library)
 			if _error != nil {
-				return nil, _error
+				_diagnostics = append (_diagnostics, DiagnosticNew (_library, _document.Path, _error))
+				continue
 			}
 			
 			_error = DocumentInitializeTitle (_document, _library)
 			if _error != nil {
-				return nil, _error
+				_diagnostics = append (_diagnostics, DiagnosticNew (_library, _document.Path, _error))
+				continue
 			}
 			
 			_documents = append (_documents, _document)
 		}
 	}
 	
-	return _documents, nil
+	return _documents, _diagnostics, nil
 }
 
 
-func mainLibrariesInclude (_index *Index, _libraries []*Library, _documents []*Document) (*Error) {
+func mainLibrariesInclude (_index *Index, _libraries []*Library, _documents []*Document, _diagnostics []*Diagnostic) (*Error) {
 	
 	IndexClearData (_index)
 	
@@ -2208,10 +3503,16 @@ func mainLibrariesInclude (_index *Index, _libraries []*Library, _documents []*D
 		
 		_error := IndexDocumentInclude (_index, _document)
 		if _error != nil {
-			return _error
+			_diagnostics = append (_diagnostics, DiagnosticNew (_index.libraries[_document.Library], _document.Path, _error))
+			continue
 		}
 	}
 	
+	IndexDocumentMovesRefresh (_index)
+	
+	DiagnosticsSort (_diagnostics)
+	_index.diagnostics = _diagnostics
+	
 	_index.dirtyEnabled = _dirtyEnabled
 	
 	return nil
@@ -2225,7 +3526,7 @@ func mainLibraryForPaths (_paths []string) (*Library, *Error) {
 			Paths : _paths,
 			UsePathInLibraryAsIdentifier : true,
 			UseFileExtensionAsFormat : true,
-			IncludeGlobPatterns : []string { "**/*.{md,markdown,gmi,gemini,txt,text}" },
+			IncludeGlobPatterns : []string { "**/*.{md,markdown,gmi,gemini,org,txt,text}" },
 			EditEnabled : true,
 			CreateEnabled : true,
 			CreatePath : _paths[0],
@@ -2281,6 +3582,16 @@ func mainProfileMemory (_path string) (func () (*Error), *Error) {
 
 
 
+func mainQueryParse (_value *string) (*Query, *Error) {
+	if _value == nil {
+		return nil, nil
+	}
+	return QueryParse (*_value)
+}
+
+
+
+
 func flagBoolOrDefault (_value *bool, _default bool) (bool) {
 	if _value != nil {
 		return *_value
@@ -3,14 +3,22 @@
 package zscratchpad
 
 
+import "bytes"
+import "fmt"
+import "net/url"
+import "regexp"
 import "unicode/utf8"
 import "strings"
 
 import goldmark "github.com/yuin/goldmark"
+import goldmark_ast "github.com/yuin/goldmark/ast"
+import goldmark_extensions_ast "github.com/yuin/goldmark/extension/ast"
 import goldmark_extensions "github.com/yuin/goldmark/extension"
 import goldmark_parser "github.com/yuin/goldmark/parser"
+import goldmark_renderer "github.com/yuin/goldmark/renderer"
 import goldmark_html "github.com/yuin/goldmark/renderer/html"
 import goldmark_text "github.com/yuin/goldmark/text"
+import goldmark_util "github.com/yuin/goldmark/util"
 
 
 
@@ -25,14 +33,47 @@ func parseAndRenderCommonmarkToHtml (_sourceLines []string) (string, *Error) {
 	}
 	_sourceBytes := _sourceBuffer.Bytes ()
 	
+	_markdown := commonmarkNew ()
+	_parser := _markdown.Parser ()
+	_renderer := _markdown.Renderer ()
+	
+	_reader := goldmark_text.NewReader (_sourceBytes)
+	_outputBuffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_outputBuffer)
+	
+	_ast := _parser.Parse (_reader)
+	if _error := _renderer.Render (_outputBuffer, _sourceBytes, _ast); _error != nil {
+		return "", errorw (0xfc82f523, _error)
+	}
+	
+	_outputBytes := _outputBuffer.Bytes ()
+	if ! utf8.Valid (_outputBytes) {
+		return "", errorw (0xbc65423b, nil)
+	}
+	
+	_output := string (_outputBytes)
+	
+	// FIXME:  This is generated for custom HTML!
+	_output = strings.ReplaceAll (_output, "<p><!-- raw HTML omitted --><!-- raw HTML omitted --></p>\n", "")
+	
+	return _output, nil
+}
+
+
+
+
+func commonmarkNew () (goldmark.Markdown) {
+	
 	_parser := goldmark.DefaultParser ()
 	_parser.AddOptions (goldmark_parser.WithAutoHeadingID ())
+	_parser.AddOptions (goldmark_parser.WithInlineParsers (goldmark_util.Prioritized (& commonmarkWikiLinkParser {}, 199)))
 	
 	_renderer := goldmark.DefaultRenderer ()
 	_renderer.AddOptions (goldmark_html.WithXHTML ())
 	_renderer.AddOptions (goldmark_html.WithUnsafe ())
+	_renderer.AddOptions (goldmark_renderer.WithNodeRenderers (goldmark_util.Prioritized (& commonmarkHighlightRenderer {}, 100)))
 	
-	goldmark.New (
+	return goldmark.New (
 			goldmark.WithParser (_parser),
 			goldmark.WithRenderer (_renderer),
 			goldmark.WithExtensions (
@@ -48,26 +89,598 @@ func parseAndRenderCommonmarkToHtml (_sourceLines []string) (string, *Error) {
 					goldmark_extensions.Footnote,
 				),
 		)
+}
+
+
+
+
+// NOTE:  Returns the body lines of the headings, in the same order as they are rendered.
+func commonmarkHeadingLines (_sourceLines []string) ([]uint) {
+	
+	_sourceBytes := []byte (strings.Join (_sourceLines, "\n") + "\n")
+	
+	_parser := commonmarkNew () .Parser ()
+	_ast := _parser.Parse (goldmark_text.NewReader (_sourceBytes))
+	
+	_lines := make ([]uint, 0, 16)
+	goldmark_ast.Walk (_ast, func (_node goldmark_ast.Node, _entering bool) (goldmark_ast.WalkStatus, error) {
+			if !_entering {
+				return goldmark_ast.WalkContinue, nil
+			}
+			if _heading, _ok := _node.(*goldmark_ast.Heading); _ok {
+				if (_heading.Lines () .Len () > 0) && (stringTrimSpaces (string (_heading.Text (_sourceBytes))) != "") {
+					_offset := _heading.Lines () .At (0) .Start
+					_lines = append (_lines, uint (bytes.Count (_sourceBytes[:_offset], []byte ("\n"))))
+				}
+				return goldmark_ast.WalkSkipChildren, nil
+			}
+			return goldmark_ast.WalkContinue, nil
+		})
+	
+	return _lines
+}
+
+
+
+
+// NOTE:  Parses `[[Some Title]]` and `[[Some Title|label]]` into links to `w:Some%20Title`.
+type commonmarkWikiLinkParser struct {}
+
+func (_parser *commonmarkWikiLinkParser) Trigger () ([]byte) {
+	return []byte { '[' }
+}
+
+func (_parser *commonmarkWikiLinkParser) Parse (_parent goldmark_ast.Node, _block goldmark_text.Reader, _context goldmark_parser.Context) (goldmark_ast.Node) {
+	
+	_line, _ := _block.PeekLine ()
+	if !bytes.HasPrefix (_line, []byte ("[[")) {
+		return nil
+	}
+	_end := bytes.Index (_line, []byte ("]]"))
+	if _end < 0 {
+		return nil
+	}
+	
+	_content := string (_line[2:_end])
+	if strings.ContainsAny (_content, "[]") {
+		return nil
+	}
+	_title := _content
+	_label := _content
+	if _index := strings.IndexByte (_content, '|'); _index >= 0 {
+		_title = _content[:_index]
+		_label = _content[_index + 1:]
+	}
+	_title = strings.Join (strings.Fields (_title), " ")
+	_label = stringTrimSpaces (_label)
+	if _title == "" {
+		return nil
+	}
+	if _label == "" {
+		_label = _title
+	}
+	
+	_block.Advance (_end + 2)
+	
+	_link := goldmark_ast.NewLink ()
+	_link.Destination = []byte ("w:" + url.PathEscape (_title))
+	_link.AppendChild (_link, goldmark_ast.NewString ([]byte (_label)))
+	
+	return _link
+}
+
+
+
+
+type commonmarkHighlightRenderer struct {}
+
+func (_renderer *commonmarkHighlightRenderer) RegisterFuncs (_registerer goldmark_renderer.NodeRendererFuncRegisterer) () {
+	_registerer.Register (goldmark_ast.KindFencedCodeBlock, commonmarkRenderFencedCodeBlock)
+}
+
+func commonmarkRenderFencedCodeBlock (_writer goldmark_util.BufWriter, _source []byte, _node goldmark_ast.Node, _entering bool) (goldmark_ast.WalkStatus, error) {
+	
+	if !_entering {
+		return goldmark_ast.WalkContinue, nil
+	}
+	
+	_block := _node.(*goldmark_ast.FencedCodeBlock)
+	_language := string (_block.Language (_source))
+	
+	_segments := _block.Lines ()
+	_lines := make ([]string, 0, _segments.Len ())
+	for _index := 0; _index < _segments.Len (); _index += 1 {
+		_segment := _segments.At (_index)
+		_line := string (_segment.Value (_source))
+		_line = strings.TrimSuffix (_line, "\n")
+		_lines = append (_lines, _line)
+	}
+	
+	_buffer := BytesBufferNewSize (16 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	highlightRenderHtmlInto (_buffer, _lines, _language, "")
+	
+	if _, _error := _writer.Write (_buffer.Bytes ()); _error != nil {
+		return goldmark_ast.WalkStop, _error
+	}
+	
+	return goldmark_ast.WalkSkipChildren, nil
+}
+
+
+
+
+func parseAndRenderCommonmarkToText (_sourceLines []string) (string, *Error) {
+	
+	_sourceBuffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_sourceBuffer)
+	for _, _line := range _sourceLines {
+		_sourceBuffer.WriteString (_line)
+		_sourceBuffer.WriteByte ('\n')
+	}
+	_sourceBytes := _sourceBuffer.Bytes ()
+	
+	_parser := commonmarkNew () .Parser ()
 	
 	_reader := goldmark_text.NewReader (_sourceBytes)
-	_outputBuffer := BytesBufferNewSize (128 * 1024)
-	defer BytesBufferRelease (_outputBuffer)
+	_ast := _parser.Parse (_reader)
+	
+	_renderer := textRendererNew ()
+	
+	for _node := _ast.FirstChild (); _node != nil; _node = _node.NextSibling () {
+		if _footnotes, _ok := _node.(*goldmark_extensions_ast.FootnoteList); _ok {
+			for _footnote := _footnotes.FirstChild (); _footnote != nil; _footnote = _footnote.NextSibling () {
+				_lines := commonmarkRenderBlocksToText (_renderer, _footnote, _sourceBytes, textRenderWidth - 6, true)
+				_label := fmt.Sprintf ("[^%d] ", _footnote.(*goldmark_extensions_ast.Footnote) .Index)
+				_lines = textRenderPrefix (_lines, _label, strings.Repeat (" ", len (_label)))
+				_renderer.footnotes = append (_renderer.footnotes, strings.Join (_lines, "\n") + "\n")
+			}
+			continue
+		}
+		textRenderBlock (_renderer, commonmarkRenderBlockToText (_renderer, _node, _sourceBytes, textRenderWidth))
+	}
+	
+	return textRenderFinalize (_renderer), nil
+}
+
+
+func commonmarkRenderBlocksToText (_renderer *textRenderer, _parent goldmark_ast.Node, _source []byte, _width int, _tight bool) ([]string) {
+	_lines := []string (nil)
+	for _node := _parent.FirstChild (); _node != nil; _node = _node.NextSibling () {
+		_block := commonmarkRenderBlockToText (_renderer, _node, _source, _width)
+		if len (_block) == 0 {
+			continue
+		}
+		if (len (_lines) > 0) && !_tight {
+			_lines = append (_lines, "")
+		}
+		_lines = append (_lines, _block ...)
+	}
+	return _lines
+}
+
+
+func commonmarkRenderBlockToText (_renderer *textRenderer, _node goldmark_ast.Node, _source []byte, _width int) ([]string) {
+	
+	switch _node := _node.(type) {
+		
+		case *goldmark_ast.Paragraph, *goldmark_ast.TextBlock :
+			return textRenderWrap (commonmarkRenderInlineToText (_renderer, _node, _source), _width, "", "")
+		
+		case *goldmark_ast.Heading :
+			return textRenderHeading (commonmarkRenderInlineToText (_renderer, _node, _source), _node.Level)
+		
+		case *goldmark_ast.ThematicBreak :
+			return []string { "* * *" }
+		
+		case *goldmark_ast.CodeBlock, *goldmark_ast.FencedCodeBlock :
+			_lines := make ([]string, 0, _node.Lines () .Len ())
+			for _index := 0; _index < _node.Lines () .Len (); _index += 1 {
+				_segment := _node.Lines () .At (_index)
+				_lines = append (_lines, strings.TrimRight (string (_segment.Value (_source)), "\r\n"))
+			}
+			return textRenderCode (_lines, "    ")
+		
+		case *goldmark_ast.Blockquote :
+			_lines := commonmarkRenderBlocksToText (_renderer, _node, _source, _width - 2, false)
+			return textRenderPrefix (_lines, "> ", "> ")
+		
+		case *goldmark_ast.List :
+			_lines := []string (nil)
+			_number := _node.Start
+			for _item := _node.FirstChild (); _item != nil; _item = _item.NextSibling () {
+				_marker := "* "
+				if _node.IsOrdered () {
+					_marker = fmt.Sprintf ("%d. ", _number)
+					_number += 1
+				}
+				_itemLines := commonmarkRenderBlocksToText (_renderer, _item, _source, _width - len (_marker), _node.IsTight)
+				if len (_itemLines) == 0 {
+					_itemLines = []string { "" }
+				}
+				if (len (_lines) > 0) && !_node.IsTight {
+					_lines = append (_lines, "")
+				}
+				_lines = append (_lines, textRenderPrefix (_itemLines, _marker, strings.Repeat (" ", len (_marker))) ...)
+			}
+			return _lines
+		
+		case *goldmark_extensions_ast.Table :
+			_rows := make ([][]string, 0, 16)
+			_header := false
+			for _row := _node.FirstChild (); _row != nil; _row = _row.NextSibling () {
+				if _, _ok := _row.(*goldmark_extensions_ast.TableHeader); _ok {
+					_header = true
+				}
+				_cells := make ([]string, 0, 16)
+				for _cell := _row.FirstChild (); _cell != nil; _cell = _cell.NextSibling () {
+					_cells = append (_cells, commonmarkRenderInlineToText (_renderer, _cell, _source))
+				}
+				_rows = append (_rows, _cells)
+			}
+			_alignments := make ([]byte, 0, len (_node.Alignments))
+			for _, _alignment := range _node.Alignments {
+				switch _alignment {
+					case goldmark_extensions_ast.AlignRight :
+						_alignments = append (_alignments, 'r')
+					case goldmark_extensions_ast.AlignCenter :
+						_alignments = append (_alignments, 'c')
+					default :
+						_alignments = append (_alignments, 'l')
+				}
+			}
+			return textRenderTable (_rows, _header, _alignments)
+		
+		case *goldmark_extensions_ast.DefinitionList :
+			_lines := []string (nil)
+			for _item := _node.FirstChild (); _item != nil; _item = _item.NextSibling () {
+				switch _item.(type) {
+					case *goldmark_extensions_ast.DefinitionTerm :
+						if len (_lines) > 0 {
+							_lines = append (_lines, "")
+						}
+						_lines = append (_lines, textRenderWrap (commonmarkRenderInlineToText (_renderer, _item, _source), _width, "", "") ...)
+					case *goldmark_extensions_ast.DefinitionDescription :
+						_itemLines := commonmarkRenderBlocksToText (_renderer, _item, _source, _width - 4, false)
+						_lines = append (_lines, textRenderPrefix (_itemLines, "    ", "    ") ...)
+				}
+			}
+			return _lines
+		
+		case *goldmark_ast.HTMLBlock :
+			return nil
+		
+		default :
+			return commonmarkRenderBlocksToText (_renderer, _node, _source, _width, false)
+	}
+}
+
+
+func commonmarkRenderInlineToText (_renderer *textRenderer, _parent goldmark_ast.Node, _source []byte) (string) {
+	
+	_buffer := strings.Builder {}
+	
+	for _node := _parent.FirstChild (); _node != nil; _node = _node.NextSibling () {
+		switch _node := _node.(type) {
+			
+			case *goldmark_ast.Text :
+				_buffer.Write (_node.Segment.Value (_source))
+				if _node.HardLineBreak () {
+					_buffer.WriteByte ('\n')
+				} else if _node.SoftLineBreak () {
+					_buffer.WriteByte (' ')
+				}
+			
+			case *goldmark_ast.String :
+				_buffer.Write (_node.Value)
+			
+			case *goldmark_ast.CodeSpan :
+				_buffer.WriteByte ('`')
+				_buffer.WriteString (commonmarkRenderInlineToText (_renderer, _node, _source))
+				_buffer.WriteByte ('`')
+			
+			case *goldmark_ast.Emphasis :
+				_marker := "_"
+				if _node.Level >= 2 {
+					_marker = "*"
+				}
+				_buffer.WriteString (_marker)
+				_buffer.WriteString (commonmarkRenderInlineToText (_renderer, _node, _source))
+				_buffer.WriteString (_marker)
+			
+			case *goldmark_extensions_ast.Strikethrough :
+				_buffer.WriteString ("~~")
+				_buffer.WriteString (commonmarkRenderInlineToText (_renderer, _node, _source))
+				_buffer.WriteString ("~~")
+			
+			case *goldmark_ast.Link :
+				_url := string (_node.Destination)
+				_label := commonmarkRenderInlineToText (_renderer, _node, _source)
+				if (_label == "") || (_label == _url) {
+					_buffer.WriteString (_url)
+				} else {
+					fmt.Fprintf (&_buffer, "%s [%d]", _label, textRenderLink (_renderer, _url))
+				}
+			
+			case *goldmark_ast.AutoLink :
+				_buffer.Write (_node.Label (_source))
+			
+			case *goldmark_ast.Image :
+				_url := string (_node.Destination)
+				_label := commonmarkRenderInlineToText (_renderer, _node, _source)
+				if _label == "" {
+					_label = "image"
+				}
+				fmt.Fprintf (&_buffer, "[%s] [%d]", _label, textRenderLink (_renderer, _url))
+			
+			case *goldmark_extensions_ast.TaskCheckBox :
+				if _node.IsChecked {
+					_buffer.WriteString ("[x] ")
+				} else {
+					_buffer.WriteString ("[ ] ")
+				}
+			
+			case *goldmark_extensions_ast.FootnoteLink :
+				fmt.Fprintf (&_buffer, "[^%d]", _node.Index)
+			
+			case *goldmark_extensions_ast.FootnoteBacklink, *goldmark_ast.RawHTML :
+				// NOP
+			
+			default :
+				_buffer.WriteString (commonmarkRenderInlineToText (_renderer, _node, _source))
+		}
+	}
+	
+	return _buffer.String ()
+}
+
+
+
+
+
+func parseAndConvertCommonmarkToGemini (_sourceLines []string) ([]string, *Error) {
+	
+	_sourceBuffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_sourceBuffer)
+	for _, _line := range _sourceLines {
+		_sourceBuffer.WriteString (_line)
+		_sourceBuffer.WriteByte ('\n')
+	}
+	_sourceBytes := _sourceBuffer.Bytes ()
+	
+	_parser := commonmarkNew () .Parser ()
 	
+	_reader := goldmark_text.NewReader (_sourceBytes)
 	_ast := _parser.Parse (_reader)
-	if _error := _renderer.Render (_outputBuffer, _sourceBytes, _ast); _error != nil {
-		return "", errorw (0xfc82f523, _error)
+	
+	_blocks := make ([][]string, 0, 128)
+	for _node := _ast.FirstChild (); _node != nil; _node = _node.NextSibling () {
+		_links := make ([]string, 0, 16)
+		_blocks = append (_blocks, commonmarkConvertBlockToGemini (_node, _sourceBytes, &_links))
+		_blocks = append (_blocks, _links)
 	}
 	
-	_outputBytes := _outputBuffer.Bytes ()
-	if ! utf8.Valid (_outputBytes) {
-		return "", errorw (0xbc65423b, nil)
+	return convertBlocksJoin (_blocks), nil
+}
+
+
+// NOTE:  The links are hoisted from the text, and collected as `=>` lines to be placed after the block.
+func commonmarkConvertBlockToGemini (_node goldmark_ast.Node, _source []byte, _links *[]string) ([]string) {
+	
+	switch _node := _node.(type) {
+		
+		case *goldmark_ast.Paragraph, *goldmark_ast.TextBlock :
+			_lines := []string (nil)
+			for _, _line := range strings.Split (commonmarkConvertInlineToGemini (_node, _source, _links), "\n") {
+				if _line = stringTrimSpaces (_line); _line != "" {
+					_lines = append (_lines, _line)
+				}
+			}
+			return _lines
+		
+		case *goldmark_ast.Heading :
+			_level := _node.Level
+			if _level > 3 {
+				_level = 3
+			}
+			_text := commonmarkConvertInlineToGemini (_node, _source, _links)
+			_text = strings.Join (strings.Fields (_text), " ")
+			return []string { strings.Repeat ("#", _level) + " " + _text }
+		
+		case *goldmark_ast.ThematicBreak :
+			return []string { "---" }
+		
+		case *goldmark_ast.CodeBlock, *goldmark_ast.FencedCodeBlock, *goldmark_ast.HTMLBlock :
+			_lines := make ([]string, 0, _node.Lines () .Len ())
+			for _index := 0; _index < _node.Lines () .Len (); _index += 1 {
+				_segment := _node.Lines () .At (_index)
+				_lines = append (_lines, strings.TrimRight (string (_segment.Value (_source)), "\r\n"))
+			}
+			_alternative := ""
+			switch _node := _node.(type) {
+				case *goldmark_ast.FencedCodeBlock :
+					_alternative = string (_node.Language (_source))
+				case *goldmark_ast.HTMLBlock :
+					_alternative = "html"
+					if _node.HasClosure () {
+						_lines = append (_lines, strings.TrimRight (string (_node.ClosureLine.Value (_source)), "\r\n"))
+					}
+			}
+			return convertPreformattedToGemini (_lines, _alternative)
+		
+		case *goldmark_ast.Blockquote :
+			_lines := []string (nil)
+			for _child := _node.FirstChild (); _child != nil; _child = _child.NextSibling () {
+				for _, _line := range commonmarkConvertBlockToGemini (_child, _source, _links) {
+					_line = strings.TrimPrefix (strings.TrimPrefix (_line, ">"), " ")
+					if _line != "" {
+						_lines = append (_lines, "> " + _line)
+					}
+				}
+			}
+			return _lines
+		
+		case *goldmark_ast.List :
+			_lines := []string (nil)
+			_number := _node.Start
+			for _item := _node.FirstChild (); _item != nil; _item = _item.NextSibling () {
+				_marker := "* "
+				if _node.IsOrdered () {
+					_marker = fmt.Sprintf ("* %d. ", _number)
+					_number += 1
+				}
+				_first := true
+				for _child := _item.FirstChild (); _child != nil; _child = _child.NextSibling () {
+					for _, _line := range commonmarkConvertBlockToGemini (_child, _source, _links) {
+						if _first {
+							_line = _marker + _line
+							_first = false
+						}
+						_lines = append (_lines, _line)
+					}
+				}
+				if _first {
+					_lines = append (_lines, stringTrimSpacesRight (_marker))
+				}
+			}
+			return _lines
+		
+		case *goldmark_extensions_ast.Table :
+			_rows := make ([][]string, 0, 16)
+			_header := false
+			for _row := _node.FirstChild (); _row != nil; _row = _row.NextSibling () {
+				if _, _ok := _row.(*goldmark_extensions_ast.TableHeader); _ok {
+					_header = true
+				}
+				_cells := make ([]string, 0, 16)
+				for _cell := _row.FirstChild (); _cell != nil; _cell = _cell.NextSibling () {
+					_cells = append (_cells, commonmarkConvertInlineToGemini (_cell, _source, _links))
+				}
+				_rows = append (_rows, _cells)
+			}
+			return convertPreformattedToGemini (textRenderTable (_rows, _header, nil), "table")
+		
+		case *goldmark_extensions_ast.FootnoteList :
+			_lines := []string (nil)
+			for _footnote := _node.FirstChild (); _footnote != nil; _footnote = _footnote.NextSibling () {
+				_label := fmt.Sprintf ("[^%d] ", _footnote.(*goldmark_extensions_ast.Footnote) .Index)
+				for _child := _footnote.FirstChild (); _child != nil; _child = _child.NextSibling () {
+					for _, _line := range commonmarkConvertBlockToGemini (_child, _source, _links) {
+						_lines = append (_lines, _label + _line)
+						_label = ""
+					}
+				}
+			}
+			return _lines
+		
+		default :
+			_lines := []string (nil)
+			for _child := _node.FirstChild (); _child != nil; _child = _child.NextSibling () {
+				_lines = append (_lines, commonmarkConvertBlockToGemini (_child, _source, _links) ...)
+			}
+			return _lines
 	}
+}
+
+
+func commonmarkConvertInlineToGemini (_parent goldmark_ast.Node, _source []byte, _links *[]string) (string) {
 	
-	_output := string (_outputBytes)
+	_buffer := strings.Builder {}
 	
-	// FIXME:  This is generated for custom HTML!
-	_output = strings.ReplaceAll (_output, "<p><!-- raw HTML omitted --><!-- raw HTML omitted --></p>\n", "")
+	_link := func (_url string, _label string) () {
+			_line := "=> " + _url
+			if (_label != "") && (_label != _url) {
+				_line += " " + _label
+			}
+			for _, _existing := range *_links {
+				if _existing == _line {
+					return
+				}
+			}
+			*_links = append (*_links, _line)
+		}
 	
-	return _output, nil
+	for _node := _parent.FirstChild (); _node != nil; _node = _node.NextSibling () {
+		switch _node := _node.(type) {
+			
+			case *goldmark_ast.Text :
+				_buffer.Write (_node.Segment.Value (_source))
+				if _node.HardLineBreak () {
+					_buffer.WriteByte ('\n')
+				} else if _node.SoftLineBreak () {
+					_buffer.WriteByte (' ')
+				}
+			
+			case *goldmark_ast.String :
+				_buffer.Write (_node.Value)
+			
+			case *goldmark_ast.CodeSpan :
+				_buffer.WriteByte ('`')
+				_buffer.WriteString (commonmarkConvertInlineToGemini (_node, _source, _links))
+				_buffer.WriteByte ('`')
+			
+			case *goldmark_ast.Link :
+				_label := commonmarkConvertInlineToGemini (_node, _source, _links)
+				_buffer.WriteString (_label)
+				_link (string (_node.Destination), strings.Join (strings.Fields (_label), " "))
+			
+			case *goldmark_ast.AutoLink :
+				_buffer.Write (_node.Label (_source))
+				_link (string (_node.URL (_source)), "")
+			
+			case *goldmark_ast.Image :
+				_label := commonmarkConvertInlineToGemini (_node, _source, _links)
+				if _label == "" {
+					_label = "image"
+				}
+				_link (string (_node.Destination), strings.Join (strings.Fields (_label), " "))
+			
+			case *goldmark_extensions_ast.TaskCheckBox :
+				if _node.IsChecked {
+					_buffer.WriteString ("[x] ")
+				} else {
+					_buffer.WriteString ("[ ] ")
+				}
+			
+			case *goldmark_extensions_ast.FootnoteLink :
+				fmt.Fprintf (&_buffer, "[^%d]", _node.Index)
+			
+			case *goldmark_extensions_ast.FootnoteBacklink, *goldmark_ast.RawHTML :
+				// NOP
+			
+			default :
+				_buffer.WriteString (commonmarkConvertInlineToGemini (_node, _source, _links))
+		}
+	}
+	
+	return _buffer.String ()
 }
 
+
+
+
+func commonmarkEscape (_text string) (string) {
+	
+	_buffer := strings.Builder {}
+	for _, _rune := range _text {
+		switch _rune {
+			case '\\', '`', '*', '_', '[', ']', '<', '>' :
+				_buffer.WriteByte ('\\')
+		}
+		_buffer.WriteRune (_rune)
+	}
+	_text = _buffer.String ()
+	
+	// NOTE:  Escape the markers that would start a block (like a list item or a heading).
+	if strings.HasPrefix (_text, "-") || strings.HasPrefix (_text, "+") || strings.HasPrefix (_text, "#") || strings.HasPrefix (_text, "=") {
+		_text = "\\" + _text
+	} else if _matches := commonmarkOrderedMarkerRegex.FindStringSubmatchIndex (_text); _matches != nil {
+		_text = _text[:_matches[3]] + "\\" + _text[_matches[3]:]
+	}
+	
+	return _text
+}
+
+
+var commonmarkOrderedMarkerRegex *regexp.Regexp = regexp.MustCompile (`^([0-9]+)[.)]`)
@@ -3,6 +3,9 @@
 package zscratchpad
 
 
+import "bytes"
+import "fmt"
+import "strings"
 import "unicode/utf8"
 
 import "github.com/volution/z-scratchpad/extensions/gemini"
@@ -15,7 +18,7 @@ func parseAndRenderGeminiToHtml (_sourceLines []string) (string, *Error) {
 	_outputBuffer := BytesBufferNewSize (128 * 1024)
 	defer BytesBufferRelease (_outputBuffer)
 	
-	_state := gemini.NewHtmlState (_outputBuffer)
+	_state := gemini.NewHtmlState (_outputBuffer, geminiRenderPreformattedToHtml)
 	_render := func (_line gemini.Line) () {
 			_line.Html (_state)
 		}
@@ -37,3 +40,192 @@ func parseAndRenderGeminiToHtml (_sourceLines []string) (string, *Error) {
 	return _output, nil
 }
 
+
+
+
+
+func geminiRenderPreformattedToHtml (_buffer *bytes.Buffer, _lines []string, _alternative string) () {
+	// NOTE:  The alternative text is free-form, thus only its first word is tried as the language.
+	_language := ""
+	if _fields := strings.Fields (_alternative); len (_fields) > 0 {
+		_language = _fields[0]
+	}
+	highlightRenderHtmlInto (_buffer, _lines, _language, "")
+}
+
+
+
+
+// NOTE:  Returns the body lines of the headings, in the same order as they are rendered.
+func geminiHeadingLines (_source []string) ([]uint) {
+	_lines := make ([]uint, 0, 16)
+	_preformatted := false
+	for _index, _line := range _source {
+		if strings.HasPrefix (_line, "```") {
+			_preformatted = !_preformatted
+		} else if !_preformatted && strings.HasPrefix (_line, "#") {
+			if stringTrimSpaces (strings.TrimLeft (_line, "#")) != "" {
+				_lines = append (_lines, uint (_index))
+			}
+		}
+	}
+	return _lines
+}
+
+
+
+
+func parseAndRenderGeminiToText (_sourceLines []string) (string, *Error) {
+	
+	_renderer := textRendererNew ()
+	
+	_block := []string (nil)
+	_blockKind := ""
+	_push := func (_kind string) () {
+			if (_kind == _blockKind) && (_kind != "text") {
+				return
+			}
+			textRenderBlock (_renderer, _block)
+			_block = nil
+			_blockKind = _kind
+		}
+	
+	_render := func (_line gemini.Line) () {
+			switch _line := _line.(type) {
+				case gemini.LineHeading1 :
+					_push ("heading")
+					_block = textRenderHeading (string (_line), 1)
+					_push ("")
+				case gemini.LineHeading2 :
+					_push ("heading")
+					_block = textRenderHeading (string (_line), 2)
+					_push ("")
+				case gemini.LineHeading3 :
+					_push ("heading")
+					_block = textRenderHeading (string (_line), 3)
+					_push ("")
+				case gemini.LineText :
+					_push ("text")
+					_block = textRenderWrap (string (_line), textRenderWidth, "", "")
+				case gemini.LineListItem :
+					_push ("list")
+					_block = append (_block, textRenderWrap (string (_line), textRenderWidth, "  * ", "    ") ...)
+				case gemini.LineQuote :
+					_push ("quote")
+					_block = append (_block, textRenderWrap (string (_line), textRenderWidth, "> ", "> ") ...)
+				case gemini.LineLink :
+					_push ("links")
+					_label := stringTrimSpaces (_line.Name)
+					if (_label == "") || (_label == _line.URL) {
+						_label = _line.URL
+					} else {
+						_label = fmt.Sprintf ("%s [%d]", _label, textRenderLink (_renderer, _line.URL))
+					}
+					_block = append (_block, textRenderWrap (_label, textRenderWidth, "  => ", "     ") ...)
+				case gemini.LinePreformattingToggle :
+					if _blockKind == "code" {
+						_push ("")
+					} else {
+						_push ("code")
+					}
+				case gemini.LinePreformattedText :
+					_push ("code")
+					_block = append (_block, textRenderCode ([]string { string (_line) }, "    ") ...)
+				default :
+					panic (abortUnreachable (0x90bc7a57))
+			}
+		}
+	
+	_error := gemini.ParseLines (_sourceLines, _render)
+	if _error != nil {
+		return "", errorw (0x47d88ecf, _error)
+	}
+	
+	_push ("")
+	
+	return textRenderFinalize (_renderer), nil
+}
+
+
+
+
+func parseAndConvertGeminiToCommonmark (_sourceLines []string) ([]string, *Error) {
+	
+	_blocks := make ([][]string, 0, 128)
+	_block := []string (nil)
+	_blockKind := ""
+	_preformatted := false
+	_alternative := ""
+	_push := func (_kind string) () {
+			if (_kind == _blockKind) && (_kind != "text") && (_kind != "heading") {
+				return
+			}
+			if _blockKind == "code" {
+				_block = convertPreformattedToCommonmark (_block, _alternative)
+			}
+			_blocks = append (_blocks, _block)
+			_block = nil
+			_blockKind = _kind
+		}
+	
+	_render := func (_line gemini.Line) () {
+			switch _line := _line.(type) {
+				case gemini.LineHeading1 :
+					_push ("heading")
+					_block = []string { "# " + commonmarkEscape (string (_line)) }
+				case gemini.LineHeading2 :
+					_push ("heading")
+					_block = []string { "## " + commonmarkEscape (string (_line)) }
+				case gemini.LineHeading3 :
+					_push ("heading")
+					_block = []string { "### " + commonmarkEscape (string (_line)) }
+				case gemini.LineText :
+					_push ("text")
+					_block = []string { commonmarkEscape (string (_line)) }
+				case gemini.LineListItem :
+					_push ("list")
+					_block = append (_block, "- " + commonmarkEscape (string (_line)))
+				case gemini.LineQuote :
+					_push ("quote")
+					if len (_block) > 0 {
+						_block = append (_block, ">")
+					}
+					_block = append (_block, "> " + commonmarkEscape (string (_line)))
+				case gemini.LineLink :
+					_push ("links")
+					_url := _line.URL
+					if strings.ContainsAny (_url, " ()<>") {
+						_url = "<" + _url + ">"
+					}
+					_label := stringTrimSpaces (_line.Name)
+					if _label == "" {
+						_label = _line.URL
+					}
+					_block = append (_block, "- [" + commonmarkEscape (_label) + "](" + _url + ")")
+				case gemini.LinePreformattingToggle :
+					if _preformatted {
+						_push ("")
+					} else {
+						_push ("code")
+						_alternative = ""
+						if _fields := strings.Fields (string (_line)); len (_fields) > 0 {
+							_alternative = _fields[0]
+						}
+					}
+					_preformatted = !_preformatted
+				case gemini.LinePreformattedText :
+					_block = append (_block, string (_line))
+				default :
+					panic (abortUnreachable (0xc07e1752))
+			}
+		}
+	
+	_error := gemini.ParseLines (_sourceLines, _render)
+	if _error != nil {
+		return nil, errorw (0x6e3ef237, _error)
+	}
+	
+	_push ("")
+	
+	return convertBlocksJoin (_blocks), nil
+}
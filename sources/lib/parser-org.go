@@ -0,0 +1,1081 @@
+package zscratchpad
+
+
+import "bytes"
+import "fmt"
+import "html"
+import "regexp"
+import "strings"
+import "unicode"
+
+
+
+
+type orgRenderer struct {
+	buffer *bytes.Buffer
+	paragraph []string
+	lists []*orgList
+	anchors map[string]bool
+}
+
+type orgList struct {
+	indent int
+	tag string
+	itemTag string
+}
+
+type orgTextRenderer struct {
+	renderer *textRenderer
+	width int
+	blocks [][]string
+	paragraph []string
+	// NOTE:  The indentation of the currently open lists, from the outermost to the innermost.
+	lists []int
+	listLines []string
+	listPrefixFirst string
+	listPrefixRest string
+}
+
+
+
+
+func parseAndRenderOrgToHtml (_source []string) (string, *Error) {
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	_renderer := & orgRenderer {
+			buffer : _buffer,
+			anchors : make (map[string]bool, 128),
+		}
+	
+	if _error := orgRenderLines (_renderer, _source); _error != nil {
+		return "", _error
+	}
+	
+	_output := string (_buffer.Bytes ())
+	
+	return _output, nil
+}
+
+
+
+
+func orgRenderLines (_renderer *orgRenderer, _source []string) (*Error) {
+	
+	_buffer := _renderer.buffer
+	_emptyLines := 0
+	
+	for _index := 0; _index < len (_source); _index += 1 {
+		
+		_line := _source[_index]
+		_lineTrimmed := stringTrimSpaces (_line)
+		_indent := orgLineIndent (_line)
+		
+		if _lineTrimmed == "" {
+			orgFlushParagraph (_renderer)
+			_emptyLines += 1
+			if _emptyLines >= 2 {
+				// NOTE:  Two empty lines end all the lists.
+				orgCloseLists (_renderer, -1)
+			}
+			continue
+		}
+		_emptyLines = 0
+		
+		if _matches := orgHeadingRegex.FindStringSubmatch (_line); _matches != nil {
+			orgFlushParagraph (_renderer)
+			orgCloseLists (_renderer, -1)
+			orgRenderHeading (_renderer, len (_matches[1]), _matches[2])
+			continue
+		}
+		
+		if _matches := orgBlockBeginRegex.FindStringSubmatch (_lineTrimmed); _matches != nil {
+			_name := strings.ToLower (_matches[1])
+			_arguments := stringTrimSpaces (_matches[2])
+			_end := "#+end_" + _name
+			_lines := make ([]string, 0, 64)
+			for _index += 1; _index < len (_source); _index += 1 {
+				if strings.ToLower (stringTrimSpaces (_source[_index])) == _end {
+					break
+				}
+				_lines = append (_lines, _source[_index])
+			}
+			orgFlushParagraph (_renderer)
+			if (len (_renderer.lists) > 0) && (_indent <= _renderer.lists[len (_renderer.lists) - 1].indent) {
+				orgCloseLists (_renderer, _indent - 1)
+			}
+			if _error := orgRenderBlock (_renderer, _name, _arguments, _lines); _error != nil {
+				return _error
+			}
+			continue
+		}
+		
+		if strings.HasPrefix (_lineTrimmed, "#+") || (_lineTrimmed == "#") || strings.HasPrefix (_lineTrimmed, "# ") {
+			// NOTE:  Keywords and comments are not rendered.
+			continue
+		}
+		
+		if _end := orgDrawerEnd (_source, _index); _end != -1 {
+			// NOTE:  Drawers (like `:PROPERTIES:` or `:LOGBOOK:`) are not rendered.
+			_index = _end
+			continue
+		}
+		
+		if orgPlanningRegex.MatchString (_lineTrimmed) {
+			orgFlushParagraph (_renderer)
+			_buffer.WriteString ("<p class=\"org-planning\"><code>")
+			_buffer.WriteString (html.EscapeString (_lineTrimmed))
+			_buffer.WriteString ("</code></p>\n")
+			continue
+		}
+		
+		if orgRuleRegex.MatchString (_lineTrimmed) {
+			orgFlushParagraph (_renderer)
+			orgCloseLists (_renderer, -1)
+			_buffer.WriteString ("<hr/>\n")
+			continue
+		}
+		
+		if (_lineTrimmed == ":") || strings.HasPrefix (_lineTrimmed, ": ") {
+			orgFlushParagraph (_renderer)
+			_buffer.WriteString ("<pre><code>")
+			for ; _index < len (_source); _index += 1 {
+				_lineTrimmed := stringTrimSpaces (_source[_index])
+				if (_lineTrimmed != ":") && ! strings.HasPrefix (_lineTrimmed, ": ") {
+					break
+				}
+				_buffer.WriteString (html.EscapeString (strings.TrimPrefix (_lineTrimmed[1:], " ")))
+				_buffer.WriteString ("\n")
+			}
+			_index -= 1
+			_buffer.WriteString ("</code></pre>\n")
+			continue
+		}
+		
+		if strings.HasPrefix (_lineTrimmed, "|") {
+			orgFlushParagraph (_renderer)
+			if (len (_renderer.lists) > 0) && (_indent <= _renderer.lists[len (_renderer.lists) - 1].indent) {
+				orgCloseLists (_renderer, _indent - 1)
+			}
+			_rows := make ([]string, 0, 64)
+			for ; _index < len (_source); _index += 1 {
+				_lineTrimmed := stringTrimSpaces (_source[_index])
+				if ! strings.HasPrefix (_lineTrimmed, "|") {
+					break
+				}
+				_rows = append (_rows, _lineTrimmed)
+			}
+			_index -= 1
+			orgRenderTable (_renderer, _rows)
+			continue
+		}
+		
+		if _matches := orgItemRegex.FindStringSubmatch (_line); _matches != nil {
+			orgFlushParagraph (_renderer)
+			orgRenderItem (_renderer, _indent, _matches[2], _matches[3])
+			continue
+		}
+		
+		if (len (_renderer.lists) > 0) && (_indent <= _renderer.lists[len (_renderer.lists) - 1].indent) {
+			orgFlushParagraph (_renderer)
+			orgCloseLists (_renderer, _indent - 1)
+		}
+		
+		_renderer.paragraph = append (_renderer.paragraph, _lineTrimmed)
+	}
+	
+	orgFlushParagraph (_renderer)
+	orgCloseLists (_renderer, -1)
+	
+	return nil
+}
+
+
+
+
+func orgRenderHeading (_renderer *orgRenderer, _level int, _text string) () {
+	
+	_buffer := _renderer.buffer
+	
+	if _level > 6 {
+		_level = 6
+	}
+	_levelTag := fmt.Sprintf ("%d", _level)
+	
+	_keyword, _priority, _text, _tags := orgHeadingSplit (_text)
+	
+	_anchor := orgAnchor (_renderer, _text)
+	
+	_buffer.WriteString ("<h" + _levelTag + " id=\"" + html.EscapeString (_anchor) + "\">")
+	if _keyword != "" {
+		_class := "org-todo"
+		if orgTodoKeywords[_keyword] {
+			_class = "org-done"
+		}
+		_buffer.WriteString ("<span class=\"" + _class + "\">" + html.EscapeString (_keyword) + "</span> ")
+	}
+	if _priority != "" {
+		_buffer.WriteString ("<span class=\"org-priority\">[#" + html.EscapeString (_priority) + "]</span> ")
+	}
+	_buffer.WriteString (orgRenderInline (_text))
+	if len (_tags) > 0 {
+		_buffer.WriteString (" <span class=\"org-tags\">")
+		for _, _tag := range _tags {
+			_buffer.WriteString ("<span class=\"org-tag\">" + html.EscapeString (_tag) + "</span>")
+		}
+		_buffer.WriteString ("</span>")
+	}
+	_buffer.WriteString ("</h" + _levelTag + ">\n")
+}
+
+
+// NOTE:  Splits a heading into its TODO keyword, priority, title and tags.
+func orgHeadingSplit (_text string) (string, string, string, []string) {
+	_keyword := ""
+	_priority := ""
+	_tags := []string (nil)
+	if _splitIndex := strings.IndexByte (_text, ' '); _splitIndex > 0 {
+		if _, _exists := orgTodoKeywords[_text[:_splitIndex]]; _exists {
+			_keyword = _text[:_splitIndex]
+			_text = stringTrimSpaces (_text[_splitIndex + 1:])
+		}
+	} else if _, _exists := orgTodoKeywords[_text]; _exists {
+		_keyword = _text
+		_text = ""
+	}
+	if _matches := orgPriorityRegex.FindStringSubmatch (_text); _matches != nil {
+		_priority = _matches[1]
+		_text = stringTrimSpaces (_text[len (_matches[0]):])
+	}
+	if _matches := orgHeadingTagsRegex.FindStringSubmatch (_text); _matches != nil {
+		_tags = strings.FieldsFunc (_matches[1], func (_rune rune) (bool) { return _rune == ':' })
+		_text = stringTrimSpaces (_text[: len (_text) - len (_matches[0])])
+	}
+	return _keyword, _priority, _text, _tags
+}
+
+
+func orgAnchor (_renderer *orgRenderer, _text string) (string) {
+	_anchor := orgAnchorSlug (_text)
+	if _anchor == "" {
+		_anchor = "heading"
+	}
+	_anchorBase := _anchor
+	for _counter := 1; _renderer.anchors[_anchor]; _counter += 1 {
+		_anchor = fmt.Sprintf ("%s-%d", _anchorBase, _counter)
+	}
+	_renderer.anchors[_anchor] = true
+	return _anchor
+}
+
+
+func orgAnchorSlug (_text string) (string) {
+	_buffer := strings.Builder {}
+	_dash := false
+	for _, _rune := range strings.ToLower (_text) {
+		if unicode.IsLetter (_rune) || unicode.IsDigit (_rune) {
+			if _dash && (_buffer.Len () > 0) {
+				_buffer.WriteByte ('-')
+			}
+			_buffer.WriteRune (_rune)
+			_dash = false
+		} else {
+			_dash = true
+		}
+	}
+	return _buffer.String ()
+}
+
+
+
+
+func orgRenderItem (_renderer *orgRenderer, _indent int, _bullet string, _text string) () {
+	
+	_buffer := _renderer.buffer
+	
+	_tag := "ul"
+	_itemTag := "li"
+	_term := ""
+	if (_bullet[len (_bullet) - 1] == '.') || (_bullet[len (_bullet) - 1] == ')') {
+		_tag = "ol"
+	} else if _splitIndex := strings.Index (_text, " :: "); _splitIndex > 0 {
+		_tag = "dl"
+		_itemTag = "dd"
+		_term = _text[:_splitIndex]
+		_text = stringTrimSpaces (_text[_splitIndex + 4:])
+	} else if strings.HasSuffix (_text, " ::") {
+		_tag = "dl"
+		_itemTag = "dd"
+		_term = strings.TrimSuffix (_text, " ::")
+		_text = ""
+	}
+	
+	orgCloseLists (_renderer, _indent)
+	
+	_list := (*orgList) (nil)
+	if len (_renderer.lists) > 0 {
+		_list = _renderer.lists[len (_renderer.lists) - 1]
+	}
+	if (_list != nil) && (_list.indent == _indent) {
+		_buffer.WriteString ("</" + _list.itemTag + ">\n")
+		if _list.tag != _tag {
+			_buffer.WriteString ("</" + _list.tag + ">\n")
+			_renderer.lists = _renderer.lists[: len (_renderer.lists) - 1]
+			_list = nil
+		}
+	} else {
+		_list = nil
+	}
+	if _list == nil {
+		_list = & orgList {
+				indent : _indent,
+				tag : _tag,
+				itemTag : _itemTag,
+			}
+		_renderer.lists = append (_renderer.lists, _list)
+		_buffer.WriteString ("<" + _tag + ">\n")
+	}
+	
+	if _tag == "dl" {
+		_buffer.WriteString ("<dt>" + orgRenderInline (_term) + "</dt>\n")
+	}
+	_buffer.WriteString ("<" + _itemTag + ">")
+	
+	if strings.HasPrefix (_text, "[ ] ") || (_text == "[ ]") {
+		_buffer.WriteString ("<input type=\"checkbox\" disabled=\"\"/> ")
+		_text = stringTrimSpaces (_text[3:])
+	} else if strings.HasPrefix (_text, "[X] ") || strings.HasPrefix (_text, "[x] ") || (_text == "[X]") || (_text == "[x]") {
+		_buffer.WriteString ("<input type=\"checkbox\" checked=\"\" disabled=\"\"/> ")
+		_text = stringTrimSpaces (_text[3:])
+	} else if strings.HasPrefix (_text, "[-] ") || (_text == "[-]") {
+		_buffer.WriteString ("<input type=\"checkbox\" disabled=\"\"/> ")
+		_text = stringTrimSpaces (_text[3:])
+	}
+	
+	if _text != "" {
+		_renderer.paragraph = append (_renderer.paragraph, _text)
+	}
+}
+
+
+func orgCloseLists (_renderer *orgRenderer, _indent int) () {
+	_buffer := _renderer.buffer
+	for len (_renderer.lists) > 0 {
+		_list := _renderer.lists[len (_renderer.lists) - 1]
+		if _list.indent <= _indent {
+			break
+		}
+		_buffer.WriteString ("</" + _list.itemTag + ">\n")
+		_buffer.WriteString ("</" + _list.tag + ">\n")
+		_renderer.lists = _renderer.lists[: len (_renderer.lists) - 1]
+	}
+}
+
+
+func orgFlushParagraph (_renderer *orgRenderer) () {
+	if len (_renderer.paragraph) == 0 {
+		return
+	}
+	_buffer := _renderer.buffer
+	_lines := make ([]string, 0, len (_renderer.paragraph))
+	for _, _line := range _renderer.paragraph {
+		_break := false
+		if strings.HasSuffix (_line, "\\\\") {
+			_line = stringTrimSpacesRight (_line[: len (_line) - 2])
+			_break = true
+		}
+		_line = orgRenderInline (_line)
+		if _break {
+			_line += "<br/>"
+		}
+		_lines = append (_lines, _line)
+	}
+	_renderer.paragraph = nil
+	if len (_renderer.lists) > 0 {
+		_buffer.WriteString (strings.Join (_lines, "\n"))
+		_buffer.WriteString ("\n")
+	} else {
+		_buffer.WriteString ("<p>")
+		_buffer.WriteString (strings.Join (_lines, "\n"))
+		_buffer.WriteString ("</p>\n")
+	}
+}
+
+
+
+
+func orgRenderBlock (_renderer *orgRenderer, _name string, _arguments string, _lines []string) (*Error) {
+	
+	_buffer := _renderer.buffer
+	
+	switch _name {
+		
+		case "src", "example", "export" :
+			_language := ""
+			if _name == "src" {
+				if _fields := strings.Fields (_arguments); len (_fields) > 0 {
+					_language = _fields[0]
+				}
+			} else if _name == "export" {
+				// NOTE:  Raw exports (even `html`) are shown as source, the sanitizer would mangle them anyway.
+				if _fields := strings.Fields (_arguments); len (_fields) > 0 {
+					_language = _fields[0]
+				}
+			}
+			_lines = orgBlockDedent (_lines)
+			for _index, _line := range _lines {
+				if strings.HasPrefix (_line, ",*") || strings.HasPrefix (_line, ",#+") {
+					_lines[_index] = _line[1:]
+				}
+			}
+			highlightRenderHtmlInto (_buffer, _lines, _language, "")
+		
+		case "verse" :
+			_lines = orgBlockDedent (_lines)
+			_buffer.WriteString ("<p class=\"org-verse\">")
+			for _index, _line := range _lines {
+				if _index > 0 {
+					_buffer.WriteString ("<br/>\n")
+				}
+				_buffer.WriteString (orgRenderInline (_line))
+			}
+			_buffer.WriteString ("</p>\n")
+		
+		default :
+			_tag := "div"
+			if _name == "quote" {
+				_tag = "blockquote"
+			}
+			_lists := _renderer.lists
+			_renderer.lists = nil
+			_buffer.WriteString ("<" + _tag + " class=\"org-" + html.EscapeString (_name) + "\">\n")
+			if _error := orgRenderLines (_renderer, orgBlockDedent (_lines)); _error != nil {
+				return _error
+			}
+			_buffer.WriteString ("</" + _tag + ">\n")
+			_renderer.lists = _lists
+	}
+	
+	return nil
+}
+
+
+// NOTE:  Returns the body lines of the headings, in the same order as they are rendered.
+func orgHeadingLines (_source []string) ([]uint) {
+	_lines := make ([]uint, 0, 16)
+	for _index := 0; _index < len (_source); _index += 1 {
+		_line := _source[_index]
+		_lineTrimmed := stringTrimSpaces (_line)
+		if orgHeadingRegex.MatchString (_line) {
+			_lines = append (_lines, uint (_index))
+		} else if _matches := orgBlockBeginRegex.FindStringSubmatch (_lineTrimmed); _matches != nil {
+			_end := "#+end_" + strings.ToLower (_matches[1])
+			for _index += 1; _index < len (_source); _index += 1 {
+				if strings.ToLower (stringTrimSpaces (_source[_index])) == _end {
+					break
+				}
+			}
+		} else if _end := orgDrawerEnd (_source, _index); _end != -1 {
+			_index = _end
+		}
+	}
+	return _lines
+}
+
+
+// NOTE:  Returns the index of the `:END:` line closing the drawer that starts at the given line, or -1 if there is no such drawer (in which case the line is just text).
+func orgDrawerEnd (_source []string, _index int) (int) {
+	_lineTrimmed := stringTrimSpaces (_source[_index])
+	if ! orgDrawerRegex.MatchString (_lineTrimmed) || (strings.ToUpper (_lineTrimmed) == ":END:") {
+		return -1
+	}
+	for _end := _index + 1; _end < len (_source); _end += 1 {
+		// NOTE:  Drawers can't span headings.
+		if orgHeadingRegex.MatchString (_source[_end]) {
+			return -1
+		}
+		if strings.ToUpper (stringTrimSpaces (_source[_end])) == ":END:" {
+			return _end
+		}
+	}
+	return -1
+}
+
+
+
+
+func orgBlockDedent (_lines []string) ([]string) {
+	_indent := -1
+	for _, _line := range _lines {
+		if stringTrimSpaces (_line) == "" {
+			continue
+		}
+		if _lineIndent := orgLineIndent (_line); (_indent == -1) || (_lineIndent < _indent) {
+			_indent = _lineIndent
+		}
+	}
+	if _indent <= 0 {
+		return _lines
+	}
+	_linesDedented := make ([]string, 0, len (_lines))
+	for _, _line := range _lines {
+		_column := 0
+		_offset := 0
+		for (_offset < len (_line)) && (_column < _indent) {
+			if _line[_offset] == ' ' {
+				_column += 1
+			} else if _line[_offset] == '\t' {
+				_column += 8 - (_column % 8)
+			} else {
+				break
+			}
+			_offset += 1
+		}
+		_line = _line[_offset:]
+		if _column > _indent {
+			// NOTE:  A tab was only partially consumed, thus the remainder is kept as spaces.
+			_line = strings.Repeat (" ", _column - _indent) + _line
+		}
+		_linesDedented = append (_linesDedented, _line)
+	}
+	return _linesDedented
+}
+
+
+
+
+func orgRenderTable (_renderer *orgRenderer, _rows []string) () {
+	
+	_buffer := _renderer.buffer
+	
+	_header := -1
+	for _index, _row := range _rows {
+		if strings.HasPrefix (_row, "|-") {
+			if _index > 0 {
+				_header = _index
+			}
+			break
+		}
+	}
+	
+	_buffer.WriteString ("<table>\n")
+	
+	_section := ""
+	for _index, _row := range _rows {
+		if strings.HasPrefix (_row, "|-") {
+			continue
+		}
+		_sectionNew := "tbody"
+		_cellTag := "td"
+		if _index < _header {
+			_sectionNew = "thead"
+			_cellTag = "th"
+		}
+		if _section != _sectionNew {
+			if _section != "" {
+				_buffer.WriteString ("</" + _section + ">\n")
+			}
+			_buffer.WriteString ("<" + _sectionNew + ">\n")
+			_section = _sectionNew
+		}
+		_row = strings.TrimPrefix (_row, "|")
+		_row = strings.TrimSuffix (_row, "|")
+		_buffer.WriteString ("<tr>")
+		for _, _cell := range strings.Split (_row, "|") {
+			_buffer.WriteString ("<" + _cellTag + ">")
+			_buffer.WriteString (orgRenderInline (stringTrimSpaces (_cell)))
+			_buffer.WriteString ("</" + _cellTag + ">")
+		}
+		_buffer.WriteString ("</tr>\n")
+	}
+	if _section != "" {
+		_buffer.WriteString ("</" + _section + ">\n")
+	}
+	
+	_buffer.WriteString ("</table>\n")
+}
+
+
+
+
+func orgRenderInline (_text string) (string) {
+	
+	_buffer := strings.Builder {}
+	
+	for _offset := 0; _offset < len (_text); {
+		
+		_rest := _text[_offset:]
+		_boundary := (_offset == 0) || (strings.IndexByte (orgEmphasisBefore, _text[_offset - 1]) != -1)
+		
+		if strings.HasPrefix (_rest, "[[") {
+			if _matches := orgLinkRegex.FindStringSubmatch (_rest); _matches != nil {
+				_buffer.WriteString (orgRenderLink (_matches[1], _matches[2]))
+				_offset += len (_matches[0])
+				continue
+			}
+		}
+		
+		if _boundary {
+			if _match := orgUrlRegex.FindString (_rest); _match != "" {
+				_match = strings.TrimRight (_match, ".,:;!?")
+				_buffer.WriteString ("<a href=\"" + html.EscapeString (_match) + "\">" + html.EscapeString (_match) + "</a>")
+				_offset += len (_match)
+				continue
+			}
+		}
+		
+		if _tags, _exists := orgEmphasisTags[_rest[0]]; _exists && _boundary && (len (_rest) > 2) && ! orgSpace (_rest[1]) {
+			_marker := _rest[0]
+			_close := -1
+			for _index := 2; _index < len (_rest); _index += 1 {
+				if (_rest[_index] == _marker) && ! orgSpace (_rest[_index - 1]) {
+					if (_index + 1 == len (_rest)) || (strings.IndexByte (orgEmphasisAfter, _rest[_index + 1]) != -1) {
+						_close = _index
+						break
+					}
+				}
+			}
+			if _close != -1 {
+				_content := _rest[1:_close]
+				_buffer.WriteString (_tags[0])
+				if (_marker == '=') || (_marker == '~') {
+					_buffer.WriteString (html.EscapeString (_content))
+				} else {
+					_buffer.WriteString (orgRenderInline (_content))
+				}
+				_buffer.WriteString (_tags[1])
+				_offset += _close + 1
+				continue
+			}
+		}
+		
+		_buffer.WriteString (html.EscapeString (_rest[:1]))
+		_offset += 1
+	}
+	
+	return _buffer.String ()
+}
+
+
+func orgRenderLink (_target string, _label string) (string) {
+	
+	_target = stringTrimSpaces (_target)
+	_label = stringTrimSpaces (_label)
+	
+	_href := _target
+	if strings.HasPrefix (_href, "file:") {
+		_href = _href[5:]
+		if _splitIndex := strings.Index (_href, "::"); _splitIndex != -1 {
+			_href = _href[:_splitIndex]
+		}
+		if ! strings.HasPrefix (_href, "/") && ! strings.HasPrefix (_href, "./") && ! strings.HasPrefix (_href, "../") {
+			_href = "./" + _href
+		}
+	} else if strings.HasPrefix (_href, "*") {
+		_href = "#" + orgAnchorSlug (_href[1:])
+	} else if strings.HasPrefix (_href, "#") {
+		// NOP
+	} else if strings.HasPrefix (_href, "/") || strings.HasPrefix (_href, "./") || strings.HasPrefix (_href, "../") {
+		// NOP
+	} else if ! orgSchemeRegex.MatchString (_href) {
+		// NOTE:  Org-mode would search for a target with this text, thus the closest is a heading.
+		_href = "#" + orgAnchorSlug (_href)
+	}
+	
+	if (_label == "") && orgImageRegex.MatchString (_href) {
+		return "<img src=\"" + html.EscapeString (_href) + "\" alt=\"" + html.EscapeString (_target) + "\"/>"
+	}
+	
+	_labelHtml := ""
+	if _label != "" {
+		_labelHtml = orgRenderInline (_label)
+	} else {
+		_labelHtml = html.EscapeString (_target)
+	}
+	
+	return "<a href=\"" + html.EscapeString (_href) + "\">" + _labelHtml + "</a>"
+}
+
+
+
+
+func parseAndRenderOrgToText (_source []string) (string, *Error) {
+	
+	_renderer := textRendererNew ()
+	
+	for _, _block := range orgTextRenderLines (_renderer, _source, textRenderWidth) {
+		textRenderBlock (_renderer, _block)
+	}
+	
+	return textRenderFinalize (_renderer), nil
+}
+
+
+
+
+func orgTextRenderLines (_renderer *textRenderer, _source []string, _width int) ([][]string) {
+	
+	_text := & orgTextRenderer {
+			renderer : _renderer,
+			width : _width,
+		}
+	_emptyLines := 0
+	
+	for _index := 0; _index < len (_source); _index += 1 {
+		
+		_line := _source[_index]
+		_lineTrimmed := stringTrimSpaces (_line)
+		_indent := orgLineIndent (_line)
+		
+		if _lineTrimmed == "" {
+			orgTextFlushParagraph (_text)
+			_emptyLines += 1
+			if _emptyLines >= 2 {
+				// NOTE:  Two empty lines end all the lists.
+				orgTextCloseLists (_text, -1)
+			}
+			continue
+		}
+		_emptyLines = 0
+		
+		if _matches := orgHeadingRegex.FindStringSubmatch (_line); _matches != nil {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, -1)
+			_keyword, _, _title, _ := orgHeadingSplit (_matches[2])
+			if _keyword != "" {
+				_title = stringTrimSpaces (_keyword + " " + _title)
+			}
+			_text.blocks = append (_text.blocks, textRenderHeading (orgTextRenderInline (_renderer, _title), len (_matches[1])))
+			continue
+		}
+		
+		if _matches := orgBlockBeginRegex.FindStringSubmatch (_lineTrimmed); _matches != nil {
+			_name := strings.ToLower (_matches[1])
+			_end := "#+end_" + _name
+			_lines := make ([]string, 0, 64)
+			for _index += 1; _index < len (_source); _index += 1 {
+				if strings.ToLower (stringTrimSpaces (_source[_index])) == _end {
+					break
+				}
+				_lines = append (_lines, _source[_index])
+			}
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, _indent - 1)
+			orgTextEmit (_text, orgTextRenderBlock (_text, _name, orgBlockDedent (_lines)))
+			continue
+		}
+		
+		if strings.HasPrefix (_lineTrimmed, "#+") || (_lineTrimmed == "#") || strings.HasPrefix (_lineTrimmed, "# ") {
+			// NOTE:  Keywords and comments are not rendered.
+			continue
+		}
+		
+		if _end := orgDrawerEnd (_source, _index); _end != -1 {
+			// NOTE:  Drawers (like `:PROPERTIES:` or `:LOGBOOK:`) are not rendered.
+			_index = _end
+			continue
+		}
+		
+		if orgPlanningRegex.MatchString (_lineTrimmed) {
+			orgTextFlushParagraph (_text)
+			orgTextEmit (_text, []string { _lineTrimmed })
+			continue
+		}
+		
+		if orgRuleRegex.MatchString (_lineTrimmed) {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, -1)
+			_text.blocks = append (_text.blocks, []string { "* * *" })
+			continue
+		}
+		
+		if (_lineTrimmed == ":") || strings.HasPrefix (_lineTrimmed, ": ") {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, _indent - 1)
+			_lines := make ([]string, 0, 64)
+			for ; _index < len (_source); _index += 1 {
+				_lineTrimmed := stringTrimSpaces (_source[_index])
+				if (_lineTrimmed != ":") && ! strings.HasPrefix (_lineTrimmed, ": ") {
+					break
+				}
+				_lines = append (_lines, strings.TrimPrefix (_lineTrimmed[1:], " "))
+			}
+			_index -= 1
+			orgTextEmit (_text, textRenderCode (_lines, "    "))
+			continue
+		}
+		
+		if strings.HasPrefix (_lineTrimmed, "|") {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, _indent - 1)
+			_rows := make ([][]string, 0, 64)
+			_header := false
+			for ; _index < len (_source); _index += 1 {
+				_lineTrimmed := stringTrimSpaces (_source[_index])
+				if ! strings.HasPrefix (_lineTrimmed, "|") {
+					break
+				}
+				if strings.HasPrefix (_lineTrimmed, "|-") {
+					if len (_rows) == 1 {
+						_header = true
+					}
+					continue
+				}
+				_row := strings.TrimSuffix (strings.TrimPrefix (_lineTrimmed, "|"), "|")
+				_cells := make ([]string, 0, 16)
+				for _, _cell := range strings.Split (_row, "|") {
+					_cells = append (_cells, orgTextRenderInline (_renderer, stringTrimSpaces (_cell)))
+				}
+				_rows = append (_rows, _cells)
+			}
+			_index -= 1
+			orgTextEmit (_text, textRenderTable (_rows, _header, nil))
+			continue
+		}
+		
+		if _matches := orgItemRegex.FindStringSubmatch (_line); _matches != nil {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, _indent)
+			if (len (_text.lists) == 0) || (_text.lists[len (_text.lists) - 1] < _indent) {
+				_text.lists = append (_text.lists, _indent)
+			}
+			_bullet := stringTrimSpaces (_matches[2])
+			_marker := "* "
+			if (_bullet[len (_bullet) - 1] == '.') || (_bullet[len (_bullet) - 1] == ')') {
+				_marker = _bullet + " "
+			}
+			_marker = strings.Repeat ("  ", len (_text.lists) - 1) + _marker
+			_text.listPrefixFirst = _marker
+			_text.listPrefixRest = strings.Repeat (" ", len (_marker))
+			if _matches[3] != "" {
+				_text.paragraph = append (_text.paragraph, _matches[3])
+			} else {
+				_text.listLines = append (_text.listLines, stringTrimSpacesRight (_marker))
+				_text.listPrefixFirst = _text.listPrefixRest
+			}
+			continue
+		}
+		
+		if (len (_text.lists) > 0) && (_indent <= _text.lists[len (_text.lists) - 1]) {
+			orgTextFlushParagraph (_text)
+			orgTextCloseLists (_text, _indent - 1)
+		}
+		
+		_text.paragraph = append (_text.paragraph, _lineTrimmed)
+	}
+	
+	orgTextFlushParagraph (_text)
+	orgTextCloseLists (_text, -1)
+	
+	return _text.blocks
+}
+
+
+func orgTextRenderBlock (_text *orgTextRenderer, _name string, _lines []string) ([]string) {
+	
+	switch _name {
+		
+		case "src", "example", "export" :
+			for _index, _line := range _lines {
+				if strings.HasPrefix (_line, ",*") || strings.HasPrefix (_line, ",#+") {
+					_lines[_index] = _line[1:]
+				}
+			}
+			return textRenderCode (_lines, "    ")
+		
+		case "verse" :
+			_linesRendered := make ([]string, 0, len (_lines))
+			for _, _line := range _lines {
+				_linesRendered = append (_linesRendered, stringTrimSpacesRight (orgTextRenderInline (_text.renderer, _line)))
+			}
+			return _linesRendered
+		
+		default :
+			_width := _text.width
+			if _name == "quote" {
+				_width -= 2
+			}
+			_linesRendered := []string (nil)
+			for _, _block := range orgTextRenderLines (_text.renderer, _lines, _width) {
+				if len (_linesRendered) > 0 {
+					_linesRendered = append (_linesRendered, "")
+				}
+				_linesRendered = append (_linesRendered, _block ...)
+			}
+			if _name == "quote" {
+				_linesRendered = textRenderPrefix (_linesRendered, "> ", "> ")
+			}
+			return _linesRendered
+	}
+}
+
+
+// NOTE:  Within a list, the lines are indented as the text of the current item.
+func orgTextEmit (_text *orgTextRenderer, _lines []string) () {
+	if len (_lines) == 0 {
+		return
+	}
+	if len (_text.lists) > 0 {
+		_text.listLines = append (_text.listLines, textRenderPrefix (_lines, _text.listPrefixRest, _text.listPrefixRest) ...)
+	} else {
+		_text.blocks = append (_text.blocks, _lines)
+	}
+}
+
+
+func orgTextCloseLists (_text *orgTextRenderer, _indent int) () {
+	for (len (_text.lists) > 0) && (_text.lists[len (_text.lists) - 1] > _indent) {
+		_text.lists = _text.lists[: len (_text.lists) - 1]
+	}
+	if (len (_text.lists) == 0) && (len (_text.listLines) > 0) {
+		_text.blocks = append (_text.blocks, _text.listLines)
+		_text.listLines = nil
+	}
+}
+
+
+func orgTextFlushParagraph (_text *orgTextRenderer) () {
+	if len (_text.paragraph) == 0 {
+		return
+	}
+	_buffer := strings.Builder {}
+	for _index, _line := range _text.paragraph {
+		if _index > 0 {
+			_buffer.WriteByte (' ')
+		}
+		if strings.HasSuffix (_line, "\\\\") {
+			_buffer.WriteString (orgTextRenderInline (_text.renderer, stringTrimSpacesRight (_line[: len (_line) - 2])))
+			_buffer.WriteByte ('\n')
+		} else {
+			_buffer.WriteString (orgTextRenderInline (_text.renderer, _line))
+		}
+	}
+	_text.paragraph = nil
+	if len (_text.lists) > 0 {
+		_lines := textRenderWrap (_buffer.String (), _text.width, _text.listPrefixFirst, _text.listPrefixRest)
+		_text.listLines = append (_text.listLines, _lines ...)
+		_text.listPrefixFirst = _text.listPrefixRest
+	} else {
+		_text.blocks = append (_text.blocks, textRenderWrap (_buffer.String (), _text.width, "", ""))
+	}
+}
+
+
+
+
+// NOTE:  Emphasis markers are dropped, while verbatim and code are quoted like in Commonmark.
+func orgTextRenderInline (_renderer *textRenderer, _text string) (string) {
+	
+	_buffer := strings.Builder {}
+	
+	for _offset := 0; _offset < len (_text); {
+		
+		_rest := _text[_offset:]
+		_boundary := (_offset == 0) || (strings.IndexByte (orgEmphasisBefore, _text[_offset - 1]) != -1)
+		
+		if strings.HasPrefix (_rest, "[[") {
+			if _matches := orgLinkRegex.FindStringSubmatch (_rest); _matches != nil {
+				_target := stringTrimSpaces (_matches[1])
+				_label := stringTrimSpaces (_matches[2])
+				if (_label == "") || (_label == _target) {
+					_buffer.WriteString (_target)
+				} else {
+					fmt.Fprintf (&_buffer, "%s [%d]", orgTextRenderInline (_renderer, _label), textRenderLink (_renderer, _target))
+				}
+				_offset += len (_matches[0])
+				continue
+			}
+		}
+		
+		if _, _exists := orgEmphasisTags[_rest[0]]; _exists && _boundary && (len (_rest) > 2) && ! orgSpace (_rest[1]) {
+			_marker := _rest[0]
+			_close := -1
+			for _index := 2; _index < len (_rest); _index += 1 {
+				if (_rest[_index] == _marker) && ! orgSpace (_rest[_index - 1]) {
+					if (_index + 1 == len (_rest)) || (strings.IndexByte (orgEmphasisAfter, _rest[_index + 1]) != -1) {
+						_close = _index
+						break
+					}
+				}
+			}
+			if _close != -1 {
+				_content := _rest[1:_close]
+				if (_marker == '=') || (_marker == '~') {
+					_buffer.WriteString ("`" + _content + "`")
+				} else {
+					_buffer.WriteString (orgTextRenderInline (_renderer, _content))
+				}
+				_offset += _close + 1
+				continue
+			}
+		}
+		
+		_buffer.WriteByte (_rest[0])
+		_offset += 1
+	}
+	
+	return _buffer.String ()
+}
+
+
+
+
+func orgLineIndent (_line string) (int) {
+	_indent := 0
+	for _, _rune := range _line {
+		if _rune == ' ' {
+			_indent += 1
+		} else if _rune == '\t' {
+			_indent += 8 - (_indent % 8)
+		} else {
+			break
+		}
+	}
+	return _indent
+}
+
+
+func orgSpace (_byte byte) (bool) {
+	return (_byte == ' ') || (_byte == '\t') || (_byte == '\n')
+}
+
+
+
+
+var orgHeadingRegex *regexp.Regexp = regexp.MustCompile (`^(\*+)[ \t]+(.*)$`)
+var orgHeadingTagsRegex *regexp.Regexp = regexp.MustCompile (`[ \t]+:((?:[\p{L}\p{N}_@#%]+:)+)$`)
+var orgPriorityRegex *regexp.Regexp = regexp.MustCompile (`^\[#([A-Za-z0-9])\](?:[ \t]+|$)`)
+var orgItemRegex *regexp.Regexp = regexp.MustCompile (`^([ \t]*)([-+]|[ \t]+\*|[0-9]+[.)]|[a-zA-Z][.)])(?:[ \t]+(.*))?$`)
+var orgBlockBeginRegex *regexp.Regexp = regexp.MustCompile (`^(?i)#\+begin_([a-z0-9_-]+)(?:[ \t]+(.*))?$`)
+var orgDrawerRegex *regexp.Regexp = regexp.MustCompile (`^:[A-Za-z0-9_-]+:$`)
+var orgPlanningRegex *regexp.Regexp = regexp.MustCompile (`^(?:SCHEDULED|DEADLINE|CLOSED):`)
+var orgRuleRegex *regexp.Regexp = regexp.MustCompile (`^-{5,}$`)
+var orgLinkRegex *regexp.Regexp = regexp.MustCompile (`^\[\[((?:[^\]\\]|\\.)+)\](?:\[([^\]]+)\])?\]`)
+var orgUrlRegex *regexp.Regexp = regexp.MustCompile (`^(?:https?|ftp|gemini)://[^\s<>"\[\]]+`)
+var orgSchemeRegex *regexp.Regexp = regexp.MustCompile (`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+var orgImageRegex *regexp.Regexp = regexp.MustCompile (`(?i)\.(?:png|jpe?g|gif|svg|webp)$`)
+
+const orgEmphasisBefore = " \t\n-('\"{"
+const orgEmphasisAfter = " \t\n-.,:!?;'\")}["
+
+var orgEmphasisTags = map[byte][2]string {
+		'*' : { "<strong>", "</strong>" },
+		'/' : { "<em>", "</em>" },
+		'_' : { "<u>", "</u>" },
+		'+' : { "<del>", "</del>" },
+		'=' : { "<code>", "</code>" },
+		'~' : { "<code>", "</code>" },
+	}
+
+// NOTE:  The value marks the keywords that denote a finished state.
+var orgTodoKeywords = map[string]bool {
+		"TODO" : false,
+		"NEXT" : false,
+		"WAITING" : false,
+		"HOLD" : false,
+		"DONE" : true,
+		"CANCELED" : true,
+		"CANCELLED" : true,
+	}
+
@@ -4,7 +4,7 @@ package zscratchpad
 
 
 import "bytes"
-import "html"
+import "regexp"
 import "strings"
 import "unicode"
 
@@ -19,6 +19,11 @@ type SnippetTextBlock struct {
 	Lines []string
 }
 
+type SnippetPlaceholder struct {
+	Name string
+	Default string
+}
+
 type SnippetBreakBlock struct {
 	Lines uint
 }
@@ -32,13 +37,7 @@ type SnippetEmptyBlock struct {
 
 func (_block *SnippetTextBlock) RenderHtmlInto (_buffer *bytes.Buffer) (*Error) {
 	
-	_buffer.WriteString ("<pre><code>")
-	for _, _line := range _block.Lines {
-		_line = html.EscapeString (_line)
-		_buffer.WriteString (_line)
-		_buffer.WriteString ("\n")
-	}
-	_buffer.WriteString ("</code></pre>\n")
+	highlightRenderHtmlInto (_buffer, _block.Lines, highlightLanguageGuess (_block.Lines), "snippet")
 	
 	return nil
 }
@@ -100,6 +99,26 @@ func parseAndRenderSnippetsToHtml (_source []string) (string, *Error) {
 
 
 
+func parseSnippetsTextBlocks (_source []string) ([]*SnippetTextBlock, *Error) {
+	
+	var _blocks []SnippetBlock
+	if _blocks_0, _error := parseSnippets (_source); _error == nil {
+		_blocks = _blocks_0
+	} else {
+		return nil, _error
+	}
+	
+	_textBlocks := make ([]*SnippetTextBlock, 0, len (_blocks))
+	for _, _block := range _blocks {
+		if _block, _ok := _block.(*SnippetTextBlock); _ok {
+			_textBlocks = append (_textBlocks, _block)
+		}
+	}
+	
+	return _textBlocks, nil
+}
+
+
 func parseSnippets (_source []string) ([]SnippetBlock, *Error) {
 	
 	_blocks := []SnippetBlock (nil)
@@ -191,3 +210,86 @@ func parseSnippets (_source []string) ([]SnippetBlock, *Error) {
 	return _blocks, nil
 }
 
+
+
+
+
+// NOTE:  Returns the placeholders (i.e. `{{name}}` or `{{name:default}}`) in order of appearance;  the first default wins.
+func SnippetPlaceholders (_lines []string) ([]*SnippetPlaceholder) {
+	_placeholders := []*SnippetPlaceholder (nil)
+	_placeholdersByName := make (map[string]*SnippetPlaceholder, 16)
+	for _, _line := range _lines {
+		for _, _match := range SnippetPlaceholderRegex.FindAllStringSubmatch (_line, -1) {
+			_name := _match[1]
+			_default := _match[2]
+			if _placeholder, _exists := _placeholdersByName[_name]; _exists {
+				if _placeholder.Default == "" {
+					_placeholder.Default = _default
+				}
+				continue
+			}
+			_placeholder := & SnippetPlaceholder {
+					Name : _name,
+					Default : _default,
+				}
+			_placeholders = append (_placeholders, _placeholder)
+			_placeholdersByName[_name] = _placeholder
+		}
+	}
+	return _placeholders
+}
+
+
+func SnippetPlaceholdersFill (_text string, _values map[string]string) (string) {
+	return SnippetPlaceholderRegex.ReplaceAllStringFunc (_text, func (_placeholder string) (string) {
+			_match := SnippetPlaceholderRegex.FindStringSubmatch (_placeholder)
+			if _value, _exists := _values[_match[1]]; _exists {
+				return _value
+			}
+			return _match[2]
+		})
+}
+
+
+var SnippetPlaceholderRegex *regexp.Regexp = regexp.MustCompile (`\{\{[ \t]*([A-Za-z_][A-Za-z0-9_.-]*)[ \t]*(?::([^{}]*))?\}\}`)
+
+
+
+
+func parseAndConvertSnippetsToCommonmark (_source []string) ([]string, *Error) {
+	return parseAndConvertSnippets (_source, convertPreformattedToCommonmark)
+}
+
+func parseAndConvertSnippetsToGemini (_source []string) ([]string, *Error) {
+	return parseAndConvertSnippets (_source, convertPreformattedToGemini)
+}
+
+func parseAndConvertSnippets (_source []string, _preformatted func ([]string, string) ([]string)) ([]string, *Error) {
+	
+	var _blocks []SnippetBlock
+	if _blocks_0, _error := parseSnippets (_source); _error == nil {
+		_blocks = _blocks_0
+	} else {
+		return nil, _error
+	}
+	
+	_output := make ([][]string, 0, len (_blocks))
+	for _, _block := range _blocks {
+		switch _block := _block.(type) {
+			case *SnippetTextBlock :
+				_output = append (_output, _preformatted (_block.Lines, ""))
+			case *SnippetBreakBlock :
+				for _lines := _block.Lines; _lines >= 1; _lines -= 1 {
+					_output = append (_output, []string { "---" })
+				}
+			case *SnippetEmptyBlock :
+				if _block.Lines > 3 {
+					_output = append (_output, []string { "---" })
+				}
+			default :
+				panic (abortUnreachable (0xfced433d))
+		}
+	}
+	
+	return convertBlocksJoin (_output), nil
+}
@@ -0,0 +1,216 @@
+package zscratchpad
+
+
+import "regexp"
+import "sort"
+import "strings"
+import "unicode/utf8"
+
+import html_parser "golang.org/x/net/html"
+import html_atom "golang.org/x/net/html/atom"
+
+
+
+
+type QueryHighlightFragment struct {
+	Text string
+	Match bool
+}
+
+type QueryExcerpt struct {
+	Line uint
+	Fragments []*QueryHighlightFragment
+}
+
+
+const queryExcerptPrefixMaximum = 60
+const queryExcerptLengthMaximum = 240
+
+
+
+
+func QueryHighlightFragments (_text string, _patterns []*regexp.Regexp) ([]*QueryHighlightFragment) {
+	
+	_ranges := queryHighlightRanges (_text, _patterns)
+	
+	_fragments := make ([]*QueryHighlightFragment, 0, len (_ranges) * 2 + 1)
+	_offset := 0
+	for _, _range := range _ranges {
+		if _range[0] > _offset {
+			_fragments = append (_fragments, & QueryHighlightFragment { Text : _text[_offset : _range[0]] })
+		}
+		_fragments = append (_fragments, & QueryHighlightFragment { Text : _text[_range[0] : _range[1]], Match : true })
+		_offset = _range[1]
+	}
+	if _offset < len (_text) {
+		_fragments = append (_fragments, & QueryHighlightFragment { Text : _text[_offset:] })
+	}
+	
+	return _fragments
+}
+
+
+// NOTE:  Each excerpt is a body line that matches, cut to start a little before the first match.
+func QueryExcerpts (_document *Document, _patterns []*regexp.Regexp, _limit int) ([]*QueryExcerpt) {
+	
+	_excerpts := make ([]*QueryExcerpt, 0, _limit)
+	
+	for _index, _line := range _document.BodyLines {
+		
+		if len (_excerpts) >= _limit {
+			break
+		}
+		
+		_line = stringTrimSpaces (_line)
+		_ranges := queryHighlightRanges (_line, _patterns)
+		if len (_ranges) == 0 {
+			continue
+		}
+		
+		_start := 0
+		if _ranges[0][0] > queryExcerptPrefixMaximum {
+			_start = _ranges[0][0] - queryExcerptPrefixMaximum
+			for (_start < len (_line)) && !utf8.RuneStart (_line[_start]) {
+				_start += 1
+			}
+		}
+		_end := len (_line)
+		if (_end - _start) > queryExcerptLengthMaximum {
+			_end = _start + queryExcerptLengthMaximum
+			for (_end > _start) && !utf8.RuneStart (_line[_end]) {
+				_end -= 1
+			}
+		}
+		
+		_fragments := QueryHighlightFragments (_line[_start : _end], _patterns)
+		if _start > 0 {
+			_fragments = append ([]*QueryHighlightFragment { & QueryHighlightFragment { Text : "... " } }, _fragments ...)
+		}
+		if _end < len (_line) {
+			_fragments = append (_fragments, & QueryHighlightFragment { Text : " ..." })
+		}
+		
+		_excerpts = append (_excerpts, & QueryExcerpt {
+				Line : uint (_document.BodyOffset) + uint (_index) + 1,
+				Fragments : _fragments,
+			})
+	}
+	
+	return _excerpts
+}
+
+
+// NOTE:  The matches are wrapped in `<strong class="search-match">`, thus they are visible also in text browsers (that usually ignore `<mark>`).
+func QueryHighlightHtml (_html string, _patterns []*regexp.Regexp) (string, *Error) {
+	
+	if len (_patterns) == 0 {
+		return _html, nil
+	}
+	
+	_context := & html_parser.Node {
+			Type : html_parser.ElementNode,
+			Data : "body",
+			DataAtom : html_atom.Body,
+		}
+	_nodes, _error := html_parser.ParseFragment (strings.NewReader (_html), _context)
+	if _error != nil {
+		return "", errorw (0x8ffc5de9, _error)
+	}
+	
+	for _, _node := range _nodes {
+		queryHighlightHtmlNode (_node, _patterns)
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	for _, _node := range _nodes {
+		if _error := html_parser.Render (_buffer, _node); _error != nil {
+			return "", errorw (0x046f11c2, _error)
+		}
+	}
+	
+	return string (_buffer.Bytes ()), nil
+}
+
+
+func queryHighlightHtmlNode (_node *html_parser.Node, _patterns []*regexp.Regexp) () {
+	
+	switch _node.Type {
+		
+		case html_parser.ElementNode :
+			switch _node.DataAtom {
+				case html_atom.Script, html_atom.Style, html_atom.Svg :
+					return
+			}
+			// NOTE:  The children are collected first, because the text nodes are replaced while walking.
+			_children := make ([]*html_parser.Node, 0, 16)
+			for _child := _node.FirstChild; _child != nil; _child = _child.NextSibling {
+				_children = append (_children, _child)
+			}
+			for _, _child := range _children {
+				queryHighlightHtmlNode (_child, _patterns)
+			}
+		
+		case html_parser.TextNode :
+			if _node.Parent == nil {
+				return
+			}
+			_fragments := QueryHighlightFragments (_node.Data, _patterns)
+			if (len (_fragments) == 1) && !_fragments[0].Match {
+				return
+			}
+			for _, _fragment := range _fragments {
+				_text := & html_parser.Node {
+						Type : html_parser.TextNode,
+						Data : _fragment.Text,
+					}
+				if _fragment.Match {
+					_strong := & html_parser.Node {
+							Type : html_parser.ElementNode,
+							Data : "strong",
+							DataAtom : html_atom.Strong,
+							Attr : []html_parser.Attribute { { Key : "class", Val : "search-match" } },
+						}
+					_strong.AppendChild (_text)
+					_text = _strong
+				}
+				_node.Parent.InsertBefore (_text, _node)
+			}
+			_node.Parent.RemoveChild (_node)
+	}
+}
+
+
+// NOTE:  The ranges of all patterns are sorted and merged, and empty matches are ignored.
+func queryHighlightRanges (_text string, _patterns []*regexp.Regexp) ([][2]int) {
+	
+	_ranges := make ([][2]int, 0, 8)
+	for _, _pattern := range _patterns {
+		for _, _match := range _pattern.FindAllStringIndex (_text, -1) {
+			if _match[1] > _match[0] {
+				_ranges = append (_ranges, [2]int { _match[0], _match[1] })
+			}
+		}
+	}
+	if len (_ranges) == 0 {
+		return nil
+	}
+	
+	sort.Slice (_ranges, func (_left int, _right int) (bool) {
+			return _ranges[_left][0] < _ranges[_right][0]
+		})
+	
+	_merged := make ([][2]int, 0, len (_ranges))
+	for _, _range := range _ranges {
+		if _last := len (_merged) - 1; (_last >= 0) && (_range[0] <= _merged[_last][1]) {
+			if _range[1] > _merged[_last][1] {
+				_merged[_last][1] = _range[1]
+			}
+			continue
+		}
+		_merged = append (_merged, _range)
+	}
+	
+	return _merged
+}
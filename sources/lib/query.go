@@ -0,0 +1,623 @@
+package zscratchpad
+
+
+import "regexp"
+import "strings"
+import "time"
+
+
+
+
+// NOTE:  The query syntax, as accepted by `grep --query`, `search --query`, and the WUI documents index:
+//
+//        query      := or
+//        or         := and ( `OR` and )*
+//        and        := not ( [ `AND` ] not )*
+//        not        := ( `NOT` | `-` ) not | atom
+//        atom       := `(` or `)` | [ field `:` ] value
+//        value      := word | `"` phrase `"` | `/` regex `/` [ flags ]
+//
+//        Words and phrases are matched as case-folded substrings;
+//        regexes are case-sensitive, unless the `i` flag is given (e.g. `/todo|fixme/i`);
+//        without a field, a value is matched against the title (and its alternatives) or the body;
+//        the `library`, `format`, `tag` and `identifier` fields are matched exactly (but case-folded);
+//        the `created` and `modified` (or `updated`) fields take a comparison (`>`, `>=`, `<`, `<=`, `=`) and a timestamp.
+
+type Query struct {
+	Source string
+	root *queryNode
+}
+
+type queryNode struct {
+	operator string
+	children []*queryNode
+	field string
+	text string
+	regex *regexp.Regexp
+	comparison string
+	timestamp time.Time
+	timestampDay bool
+}
+
+type queryToken struct {
+	kind string
+	field string
+	value string
+	regex bool
+	regexFlags string
+}
+
+type queryParser struct {
+	tokens []*queryToken
+	offset int
+}
+
+type queryDocument struct {
+	document *Document
+	body string
+	bodyFolded string
+	bodyReady bool
+}
+
+
+var queryFields = map[string]string {
+		"title" : "title",
+		"body" : "body",
+		"library" : "library",
+		"path" : "path",
+		"format" : "format",
+		"tag" : "tag",
+		"identifier" : "identifier",
+		"id" : "identifier",
+		"created" : "created",
+		"modified" : "modified",
+		"updated" : "modified",
+	}
+
+var queryFieldRegex *regexp.Regexp = regexp.MustCompile (`^([a-z]+):`)
+var queryComparisonRegex *regexp.Regexp = regexp.MustCompile (`^(>=|<=|>|<|=)?(.+)$`)
+
+
+
+
+func QueryParse (_source string) (*Query, *Error) {
+	
+	_tokens, _error := queryLex (_source)
+	if _error != nil {
+		return nil, _error
+	}
+	if len (_tokens) == 0 {
+		return nil, errorf (0x49c0c227, "query empty")
+	}
+	
+	_parser := & queryParser {
+			tokens : _tokens,
+		}
+	
+	_root, _error := queryParseOr (_parser)
+	if _error != nil {
+		return nil, _error
+	}
+	if _parser.offset != len (_parser.tokens) {
+		return nil, errorf (0xdadaf662, "query invalid:  unexpected `%s`", queryTokenString (_parser.tokens[_parser.offset]))
+	}
+	
+	_query := & Query {
+			Source : _source,
+			root : _root,
+		}
+	
+	return _query, nil
+}
+
+
+func QueryMatches (_query *Query, _document *Document) (bool) {
+	if _query == nil {
+		return true
+	}
+	_context := & queryDocument {
+			document : _document,
+		}
+	return queryNodeMatches (_query.root, _context)
+}
+
+
+
+// NOTE:  Only the values that apply to the title or body, and are not negated, are highlighted.
+func QueryHighlightPatterns (_query *Query) ([]*regexp.Regexp) {
+	return queryNodeHighlightPatterns (_query.root, false, make ([]*regexp.Regexp, 0, 8))
+}
+
+
+// NOTE:  Returns the terms only if the query is a conjunction of plain values (i.e. without fields, regexes, negations or alternatives).
+func QueryTermsPlain (_query *Query) ([]string, bool) {
+	_nodes := []*queryNode { _query.root }
+	if _query.root.operator == "and" {
+		_nodes = _query.root.children
+	}
+	_terms := make ([]string, 0, len (_nodes))
+	for _, _node := range _nodes {
+		if (_node.operator != "match") || (_node.field != "") || (_node.regex != nil) {
+			return nil, false
+		}
+		_terms = append (_terms, _node.text)
+	}
+	return _terms, true
+}
+
+
+func queryNodeHighlightPatterns (_node *queryNode, _negated bool, _patterns []*regexp.Regexp) ([]*regexp.Regexp) {
+	switch _node.operator {
+		case "and", "or" :
+			for _, _child := range _node.children {
+				_patterns = queryNodeHighlightPatterns (_child, _negated, _patterns)
+			}
+		case "not" :
+			_patterns = queryNodeHighlightPatterns (_node.children[0], !_negated, _patterns)
+		case "match" :
+			if _negated {
+				break
+			}
+			switch _node.field {
+				case "", "title", "body" :
+					if _node.regex != nil {
+						_patterns = append (_patterns, _node.regex)
+					} else {
+						_patterns = append (_patterns, regexp.MustCompile ("(?i)" + regexp.QuoteMeta (_node.text)))
+					}
+			}
+	}
+	return _patterns
+}
+
+
+
+
+func queryLex (_source string) ([]*queryToken, *Error) {
+	
+	_tokens := make ([]*queryToken, 0, 16)
+	_offset := 0
+	
+	for {
+		
+		for (_offset < len (_source)) && queryLexSpace (_source[_offset]) {
+			_offset += 1
+		}
+		if _offset == len (_source) {
+			break
+		}
+		
+		switch _source[_offset] {
+			case '(', ')' :
+				_tokens = append (_tokens, & queryToken { kind : _source[_offset : _offset + 1] })
+				_offset += 1
+				continue
+			case '-' :
+				if (_offset + 1 < len (_source)) && !queryLexSpace (_source[_offset + 1]) && (_source[_offset + 1] != ')') {
+					_tokens = append (_tokens, & queryToken { kind : "not" })
+					_offset += 1
+					continue
+				}
+		}
+		
+		_token := & queryToken { kind : "value" }
+		
+		if _matches := queryFieldRegex.FindStringSubmatch (_source[_offset:]); _matches != nil {
+			if _field, _exists := queryFields[_matches[1]]; _exists {
+				_token.field = _field
+				_offset += len (_matches[0])
+			}
+		}
+		
+		if (_offset < len (_source)) && (_source[_offset] == '"') {
+			if _value, _offset_0, _error := queryLexDelimited (_source, _offset, '"'); _error == nil {
+				_token.value = _value
+				_offset = _offset_0
+			} else {
+				return nil, _error
+			}
+		} else if (_offset < len (_source)) && (_source[_offset] == '/') {
+			if _value, _offset_0, _error := queryLexDelimited (_source, _offset, '/'); _error == nil {
+				_token.value = _value
+				_token.regex = true
+				_offset = _offset_0
+			} else {
+				return nil, _error
+			}
+			_flagsOffset := _offset
+			for (_offset < len (_source)) && (_source[_offset] >= 'a') && (_source[_offset] <= 'z') {
+				_offset += 1
+			}
+			_token.regexFlags = _source[_flagsOffset : _offset]
+		} else {
+			_wordOffset := _offset
+			for (_offset < len (_source)) && !queryLexSpace (_source[_offset]) && (_source[_offset] != '(') && (_source[_offset] != ')') {
+				_offset += 1
+			}
+			_token.value = _source[_wordOffset : _offset]
+			if _token.field == "" {
+				switch _token.value {
+					case "AND" :
+						_token.kind = "and"
+					case "OR" :
+						_token.kind = "or"
+					case "NOT" :
+						_token.kind = "not"
+				}
+			}
+		}
+		
+		if (_token.kind == "value") && (_token.value == "") {
+			if _token.field != "" {
+				return nil, errorf (0xa9c824f3, "query invalid:  empty value for field `%s`", _token.field)
+			}
+			return nil, errorf (0x52fd1448, "query invalid:  empty value")
+		}
+		
+		_tokens = append (_tokens, _token)
+	}
+	
+	return _tokens, nil
+}
+
+
+// NOTE:  A backslash escapes the delimiter, and inside phrases also itself;  inside regexes any other escape is kept as is.
+func queryLexDelimited (_source string, _offset int, _delimiter byte) (string, int, *Error) {
+	_buffer := strings.Builder {}
+	_offset += 1
+	for _offset < len (_source) {
+		_byte := _source[_offset]
+		if (_byte == '\\') && (_offset + 1 < len (_source)) {
+			_next := _source[_offset + 1]
+			if (_next == _delimiter) || ((_delimiter == '"') && (_next == '\\')) {
+				_buffer.WriteByte (_next)
+			} else {
+				_buffer.WriteByte (_byte)
+				_buffer.WriteByte (_next)
+			}
+			_offset += 2
+			continue
+		}
+		if _byte == _delimiter {
+			return _buffer.String (), _offset + 1, nil
+		}
+		_buffer.WriteByte (_byte)
+		_offset += 1
+	}
+	return "", 0, errorf (0x21b3da7d, "query invalid:  unterminated `%c`", _delimiter)
+}
+
+
+func queryLexSpace (_byte byte) (bool) {
+	return (_byte == ' ') || (_byte == '\t') || (_byte == '\n') || (_byte == '\r')
+}
+
+
+func queryTokenString (_token *queryToken) (string) {
+	switch _token.kind {
+		case "value" :
+			if _token.field != "" {
+				return _token.field + ":" + _token.value
+			}
+			return _token.value
+		case "and", "or", "not" :
+			return strings.ToUpper (_token.kind)
+		default :
+			return _token.kind
+	}
+}
+
+
+
+
+func queryParseOr (_parser *queryParser) (*queryNode, *Error) {
+	_children := make ([]*queryNode, 0, 4)
+	for {
+		_child, _error := queryParseAnd (_parser)
+		if _error != nil {
+			return nil, _error
+		}
+		_children = append (_children, _child)
+		if _token := queryParsePeek (_parser); (_token != nil) && (_token.kind == "or") {
+			_parser.offset += 1
+			continue
+		}
+		break
+	}
+	if len (_children) == 1 {
+		return _children[0], nil
+	}
+	return & queryNode { operator : "or", children : _children }, nil
+}
+
+
+func queryParseAnd (_parser *queryParser) (*queryNode, *Error) {
+	_children := make ([]*queryNode, 0, 4)
+	for {
+		_child, _error := queryParseNot (_parser)
+		if _error != nil {
+			return nil, _error
+		}
+		_children = append (_children, _child)
+		_token := queryParsePeek (_parser)
+		if _token == nil {
+			break
+		}
+		if _token.kind == "and" {
+			_parser.offset += 1
+			continue
+		}
+		if (_token.kind == "value") || (_token.kind == "not") || (_token.kind == "(") {
+			continue
+		}
+		break
+	}
+	if len (_children) == 1 {
+		return _children[0], nil
+	}
+	return & queryNode { operator : "and", children : _children }, nil
+}
+
+
+func queryParseNot (_parser *queryParser) (*queryNode, *Error) {
+	if _token := queryParsePeek (_parser); (_token != nil) && (_token.kind == "not") {
+		_parser.offset += 1
+		_child, _error := queryParseNot (_parser)
+		if _error != nil {
+			return nil, _error
+		}
+		return & queryNode { operator : "not", children : []*queryNode { _child } }, nil
+	}
+	return queryParseAtom (_parser)
+}
+
+
+func queryParseAtom (_parser *queryParser) (*queryNode, *Error) {
+	
+	_token := queryParsePeek (_parser)
+	if _token == nil {
+		return nil, errorf (0x1ef01e33, "query invalid:  unexpected end")
+	}
+	
+	switch _token.kind {
+		
+		case "(" :
+			_parser.offset += 1
+			_node, _error := queryParseOr (_parser)
+			if _error != nil {
+				return nil, _error
+			}
+			if _token := queryParsePeek (_parser); (_token == nil) || (_token.kind != ")") {
+				return nil, errorf (0x3653e264, "query invalid:  missing `)`")
+			}
+			_parser.offset += 1
+			return _node, nil
+		
+		case "value" :
+			_parser.offset += 1
+			return queryParseValue (_token)
+		
+		default :
+			return nil, errorf (0x78b6e38b, "query invalid:  unexpected `%s`", queryTokenString (_token))
+	}
+}
+
+
+func queryParseValue (_token *queryToken) (*queryNode, *Error) {
+	
+	_node := & queryNode {
+			operator : "match",
+			field : _token.field,
+		}
+	
+	switch _token.field {
+		case "created", "modified" :
+			if _token.regex {
+				return nil, errorf (0xc201bc34, "query invalid:  field `%s` doesn't accept regexes", _token.field)
+			}
+			_matches := queryComparisonRegex.FindStringSubmatch (stringTrimSpaces (_token.value))
+			if _matches == nil {
+				return nil, errorf (0x36755a29, "query invalid:  field `%s` requires a timestamp", _token.field)
+			}
+			_node.comparison = _matches[1]
+			if _node.comparison == "" {
+				_node.comparison = "="
+			}
+			if _timestamp, _error := time.ParseInLocation ("2006-01-02", _matches[2], time.Local); _error == nil {
+				_node.timestamp = _timestamp
+				_node.timestampDay = true
+			} else if _timestamp, _error := documentTimestampParse (_matches[2]); _error == nil {
+				_node.timestamp = _timestamp
+			} else {
+				return nil, _error
+			}
+			return _node, nil
+	}
+	
+	if _token.regex {
+		_pattern := _token.value
+		for _, _flag := range _token.regexFlags {
+			switch _flag {
+				case 'i', 'm', 's' :
+					_pattern = "(?" + string (_flag) + ")" + _pattern
+				default :
+					return nil, errorf (0xc2c1e044, "query invalid:  regex flag `%c` unknown", _flag)
+			}
+		}
+		if _regex, _error := regexp.Compile (_pattern); _error == nil {
+			_node.regex = _regex
+		} else {
+			return nil, errorw (0x6663aefa, _error)
+		}
+	} else {
+		_node.text = strings.ToLower (_token.value)
+	}
+	
+	return _node, nil
+}
+
+
+func queryParsePeek (_parser *queryParser) (*queryToken) {
+	if _parser.offset < len (_parser.tokens) {
+		return _parser.tokens[_parser.offset]
+	}
+	return nil
+}
+
+
+
+
+func queryNodeMatches (_node *queryNode, _context *queryDocument) (bool) {
+	
+	switch _node.operator {
+		
+		case "and" :
+			for _, _child := range _node.children {
+				if ! queryNodeMatches (_child, _context) {
+					return false
+				}
+			}
+			return true
+		
+		case "or" :
+			for _, _child := range _node.children {
+				if queryNodeMatches (_child, _context) {
+					return true
+				}
+			}
+			return false
+		
+		case "not" :
+			return ! queryNodeMatches (_node.children[0], _context)
+		
+		case "match" :
+			// NOP
+		
+		default :
+			panic (abortUnreachable (0xee5b5f37))
+	}
+	
+	_document := _context.document
+	
+	switch _node.field {
+		
+		case "" :
+			return queryNodeMatchesTitle (_node, _document) || queryNodeMatchesBody (_node, _context)
+		
+		case "title" :
+			return queryNodeMatchesTitle (_node, _document)
+		
+		case "body" :
+			return queryNodeMatchesBody (_node, _context)
+		
+		case "path" :
+			return queryNodeMatchesText (_node, _document.Path, strings.ToLower (_document.Path))
+		
+		case "library" :
+			return queryNodeMatchesExact (_node, _document.Library)
+		
+		case "format" :
+			return queryNodeMatchesExact (_node, _document.Format)
+		
+		case "identifier" :
+			if queryNodeMatchesExact (_node, _document.Identifier) {
+				return true
+			}
+			for _, _alias := range _document.Aliases {
+				if queryNodeMatchesExact (_node, _alias) {
+					return true
+				}
+			}
+			return false
+		
+		case "tag" :
+			for _, _tag := range _document.Tags {
+				if queryNodeMatchesExact (_node, _tag) {
+					return true
+				}
+			}
+			return false
+		
+		case "created" :
+			return queryNodeMatchesTimestamp (_node, _document.Created)
+		
+		case "modified" :
+			_timestamp := _document.Updated
+			if _timestamp.IsZero () {
+				_timestamp = _document.Timestamp
+			}
+			return queryNodeMatchesTimestamp (_node, _timestamp)
+		
+		default :
+			panic (abortUnreachable (0x2aec36d6))
+	}
+}
+
+
+func queryNodeMatchesTitle (_node *queryNode, _document *Document) (bool) {
+	if queryNodeMatchesText (_node, _document.Title, strings.ToLower (_document.Title)) {
+		return true
+	}
+	for _, _title := range _document.TitleAlternatives {
+		if queryNodeMatchesText (_node, _title, strings.ToLower (_title)) {
+			return true
+		}
+	}
+	return false
+}
+
+
+// NOTE:  The body is joined (and folded) only once per document, and only if actually needed.
+func queryNodeMatchesBody (_node *queryNode, _context *queryDocument) (bool) {
+	if ! _context.bodyReady {
+		_context.body = strings.Join (_context.document.BodyLines, "\n")
+		_context.bodyFolded = strings.ToLower (_context.body)
+		_context.bodyReady = true
+	}
+	return queryNodeMatchesText (_node, _context.body, _context.bodyFolded)
+}
+
+
+func queryNodeMatchesText (_node *queryNode, _text string, _textFolded string) (bool) {
+	if _node.regex != nil {
+		return _node.regex.MatchString (_text)
+	}
+	return strings.Contains (_textFolded, _node.text)
+}
+
+
+func queryNodeMatchesExact (_node *queryNode, _text string) (bool) {
+	if _node.regex != nil {
+		return _node.regex.MatchString (_text)
+	}
+	return strings.ToLower (_text) == _node.text
+}
+
+
+// NOTE:  A date without time stands for the whole day, thus `>2025-01-01` means starting with the next day.
+func queryNodeMatchesTimestamp (_node *queryNode, _timestamp time.Time) (bool) {
+	if _timestamp.IsZero () {
+		return false
+	}
+	_start := _node.timestamp
+	_end := _node.timestamp
+	if _node.timestampDay {
+		_end = _start.AddDate (0, 0, 1)
+	} else {
+		_end = _start.Add (time.Second)
+	}
+	switch _node.comparison {
+		case "=" :
+			return !_timestamp.Before (_start) && _timestamp.Before (_end)
+		case ">" :
+			return !_timestamp.Before (_end)
+		case ">=" :
+			return !_timestamp.Before (_start)
+		case "<" :
+			return _timestamp.Before (_start)
+		case "<=" :
+			return _timestamp.Before (_end)
+		default :
+			panic (abortUnreachable (0xf3f72cf1))
+	}
+}
@@ -0,0 +1,96 @@
+package zscratchpad
+
+
+import "strings"
+
+
+
+
+// NOTE:  Returns the body lines of the document, translated into the given format.
+func DocumentConvert (_document *Document, _format string) ([]string, *Error) {
+	
+	_formatSource := _document.Format
+	if _formatSource == "" {
+		_formatSource = "text"
+	}
+	
+	if _formatSource == _format {
+		return append ([]string (nil), _document.BodyLines ...), nil
+	}
+	
+	switch _format {
+		
+		case "commonmark" :
+			switch _formatSource {
+				case "gemini" :
+					return parseAndConvertGeminiToCommonmark (_document.BodyLines)
+				case "snippets" :
+					return parseAndConvertSnippetsToCommonmark (_document.BodyLines)
+				case "text" :
+					return convertPreformattedToCommonmark (_document.BodyLines, ""), nil
+			}
+		
+		case "gemini" :
+			switch _formatSource {
+				case "commonmark" :
+					return parseAndConvertCommonmarkToGemini (_document.BodyLines)
+				case "snippets" :
+					return parseAndConvertSnippetsToGemini (_document.BodyLines)
+				case "text" :
+					return convertPreformattedToGemini (_document.BodyLines, ""), nil
+			}
+		
+		default :
+			return nil, errorf (0xc79e427c, "format invalid `%s`", _format)
+	}
+	
+	return nil, errorf (0x02c9dbe4, "conversion unsupported from `%s` to `%s`", _formatSource, _format)
+}
+
+
+
+
+func convertPreformattedToCommonmark (_lines []string, _language string) ([]string) {
+	_fence := "```"
+	for _, _line := range _lines {
+		for strings.HasPrefix (strings.TrimLeft (_line, " "), _fence) {
+			_fence += "`"
+		}
+	}
+	_output := make ([]string, 0, len (_lines) + 2)
+	_output = append (_output, _fence + _language)
+	_output = append (_output, _lines ...)
+	_output = append (_output, _fence)
+	return _output
+}
+
+
+func convertPreformattedToGemini (_lines []string, _alternative string) ([]string) {
+	_output := make ([]string, 0, len (_lines) + 2)
+	_output = append (_output, "```" + _alternative)
+	for _, _line := range _lines {
+		if strings.HasPrefix (_line, "```") {
+			// NOTE:  Gemini has no escaping, thus the line is indented to not end the block.
+			_line = " " + _line
+		}
+		_output = append (_output, _line)
+	}
+	_output = append (_output, "```")
+	return _output
+}
+
+
+func convertBlocksJoin (_blocks [][]string) ([]string) {
+	_output := make ([]string, 0, 1024)
+	for _, _block := range _blocks {
+		if len (_block) == 0 {
+			continue
+		}
+		if len (_output) > 0 {
+			_output = append (_output, "")
+		}
+		_output = append (_output, _block ...)
+	}
+	return _output
+}
+
@@ -0,0 +1,109 @@
+package zscratchpad
+
+
+import "bytes"
+import "html"
+import "strings"
+
+
+import "github.com/alecthomas/chroma"
+import chroma_lexers "github.com/alecthomas/chroma/lexers"
+
+
+
+
+const HighlightClassPrefix = "hl-"
+
+
+
+
+// NOTE:  Highlighting is best-effort;  unknown languages (or lexer failures) fall back to plain escaped text.
+func highlightRenderHtmlInto (_buffer *bytes.Buffer, _lines []string, _language string, _class string) () {
+	
+	_language = strings.ToLower (stringTrimSpaces (_language))
+	
+	_buffer.WriteString ("<pre")
+	if _class != "" {
+		_buffer.WriteString (" class=\"" + html.EscapeString (_class) + "\"")
+	}
+	_buffer.WriteString ("><code")
+	if _language != "" {
+		_buffer.WriteString (" class=\"language-" + html.EscapeString (_language) + "\"")
+	}
+	_buffer.WriteString (">")
+	
+	if ! highlightTokensRenderHtmlInto (_buffer, _lines, _language) {
+		for _, _line := range _lines {
+			_buffer.WriteString (html.EscapeString (_line))
+			_buffer.WriteString ("\n")
+		}
+	}
+	
+	_buffer.WriteString ("</code></pre>\n")
+}
+
+
+func highlightTokensRenderHtmlInto (_buffer *bytes.Buffer, _lines []string, _language string) (bool) {
+	
+	if _language == "" {
+		return false
+	}
+	_lexer := chroma_lexers.Get (_language)
+	if _lexer == nil {
+		return false
+	}
+	_lexer = chroma.Coalesce (_lexer)
+	
+	_code := strings.Join (_lines, "\n") + "\n"
+	
+	_iterator, _error := _lexer.Tokenise (nil, _code)
+	if _error != nil {
+		return false
+	}
+	
+	for _, _token := range _iterator.Tokens () {
+		_value := html.EscapeString (_token.Value)
+		_class := highlightTokenClass (_token.Type)
+		if (_class == "") || (_class == "w") {
+			_buffer.WriteString (_value)
+			continue
+		}
+		// NOTE:  Spans don't cross lines, thus the output can still be processed line-by-line.
+		for _index, _valueLine := range strings.Split (_value, "\n") {
+			if _index > 0 {
+				_buffer.WriteString ("\n")
+			}
+			if _valueLine == "" {
+				continue
+			}
+			_buffer.WriteString ("<span class=\"" + HighlightClassPrefix + _class + "\">")
+			_buffer.WriteString (_valueLine)
+			_buffer.WriteString ("</span>")
+		}
+	}
+	
+	return true
+}
+
+
+func highlightTokenClass (_type chroma.TokenType) (string) {
+	for _, _type := range []chroma.TokenType { _type, _type.SubCategory (), _type.Category () } {
+		if _class, _exists := chroma.StandardTypes[_type]; _exists {
+			return _class
+		}
+	}
+	return ""
+}
+
+
+
+
+// NOTE:  Used only where there is no explicit language (i.e. snippets), and it mostly relies on shebangs.
+func highlightLanguageGuess (_lines []string) (string) {
+	_lexer := chroma_lexers.Analyse (strings.Join (_lines, "\n"))
+	if _lexer == nil {
+		return ""
+	}
+	return strings.ToLower (_lexer.Config () .Name)
+}
+
@@ -12,11 +12,15 @@ import html_template "html/template"
 
 
 
-func DocumentRenderToHtmlDocument (_document *Document, _export bool, _theme string, _templates *Templates, _buffer *bytes.Buffer) (*Error) {
-	_documentHtml, _error := DocumentRenderToHtml (_document, _export)
+func DocumentRenderToHtmlDocument (_document *Document, _export bool, _theme string, _outline bool, _index *Index, _templates *Templates, _buffer *bytes.Buffer) (*Error) {
+	_documentHtml, _error := DocumentRenderToHtml (_document, _export, _index)
 	if _error != nil {
 		return _error
 	}
+	_documentOutline := ""
+	if _outline {
+		_documentOutline = DocumentOutlineRenderHtml (_document.HtmlOutline)
+	}
 	_themeCssAsset := ""
 	if _theme == "default" {
 		_theme = "github-auto"
@@ -44,10 +48,12 @@ func DocumentRenderToHtmlDocument (_document *Document, _export bool, _theme str
 	_context := struct {
 			Document *Document
 			DocumentHtml html_template.HTML
+			DocumentOutline html_template.HTML
 			ThemeCss html_template.CSS
 		} {
 			_document,
 			html_template.HTML (stringTrimSpaces (_documentHtml)),
+			html_template.HTML (_documentOutline),
 			html_template.CSS (_themeCssData),
 		}
 	if _error := _templates.documentExportHtmlDocument.Execute (_buffer, _context); _error != nil {
@@ -59,7 +65,16 @@ func DocumentRenderToHtmlDocument (_document *Document, _export bool, _theme str
 
 
 
-func DocumentRenderToHtml (_document *Document, _export bool) (string, *Error) {
+// NOTE:  Without an index, includes are not expanded.
+func DocumentRenderToHtml (_document *Document, _export bool, _index *Index) (string, *Error) {
+	
+	if (_index != nil) && !documentIncludesFresh (_document, _index) {
+		_document.RenderHtml = ""
+		_document.RenderHtmlExport = ""
+		_document.RenderIncludes = nil
+		_document.HtmlLinks = nil
+		_document.HtmlOutline = nil
+	}
 	
 	if _export {
 		if _document.RenderHtmlExport != "" {
@@ -71,6 +86,36 @@ func DocumentRenderToHtml (_document *Document, _export bool) (string, *Error) {
 		}
 	}
 	
+	_includes := make (map[string]string, 16)
+	_render, _error := documentRenderToHtmlUnsafe (_document, _index, []string { _document.Identifier }, _includes)
+	if _error != nil {
+		return "", _error
+	}
+	
+	_render, _outcome, _error := DocumentSanitizeHtml (_document, _render, !_export)
+	if _error != nil {
+		return "", _error
+	}
+	
+	documentOutlineResolveLines (_document, _outcome.Outline)
+	
+	if _export {
+		_document.RenderHtmlExport = _render
+	} else {
+		_document.RenderHtml = _render
+		_document.HtmlLinks = _outcome.UrlsLabel
+	}
+	_document.HtmlOutline = _outcome.Outline
+	if len (_includes) > 0 {
+		_document.RenderIncludes = _includes
+	}
+	
+	return _render, nil
+}
+
+
+func documentRenderToHtmlUnsafe (_document *Document, _index *Index, _stack []string, _includes map[string]string) (string, *Error) {
+	
 	_format := _document.Format
 	if _format == "" {
 		_format = "text"
@@ -94,6 +139,9 @@ func DocumentRenderToHtml (_document *Document, _export bool) (string, *Error) {
 		case "gemini" :
 			_render, _error = documentRenderGeminiToHtml (_document.BodyLines)
 		
+		case "org" :
+			_render, _error = documentRenderOrgToHtml (_document.BodyLines)
+		
 		default :
 			return "", errorf (0xaf60ea6d, "format invalid `%s`", _document.Format)
 	}
@@ -102,19 +150,14 @@ func DocumentRenderToHtml (_document *Document, _export bool) (string, *Error) {
 		return "", _error
 	}
 	
-	_render, _outcome, _error := DocumentSanitizeHtml (_document, _render, !_export)
-	if _error != nil {
-		return "", _error
-	}
-	
-	if _export {
-		_document.RenderHtmlExport = _render
-	} else {
-		_document.RenderHtml = _render
-		_document.HtmlLinks = _outcome.UrlsLabel
+	if _index != nil {
+		switch _format {
+			case "commonmark", "gemini", "org" :
+				_render, _error = documentIncludesExpand (_render, _index, _stack, _includes)
+		}
 	}
 	
-	return _render, nil
+	return _render, _error
 }
 
 
@@ -132,6 +175,10 @@ func documentRenderSnippetsToHtml (_source []string) (string, *Error) {
 	return parseAndRenderSnippetsToHtml (_source)
 }
 
+func documentRenderOrgToHtml (_source []string) (string, *Error) {
+	return parseAndRenderOrgToHtml (_source)
+}
+
 
 
 
@@ -0,0 +1,220 @@
+package zscratchpad
+
+
+import "html"
+import "regexp"
+import "strings"
+
+import html_parser "golang.org/x/net/html"
+import html_atom "golang.org/x/net/html/atom"
+
+
+
+
+// NOTE:  An include is a paragraph made only of `{{include sd:identifier}}` or `{{include sd:identifier#anchor}}`.
+const DocumentIncludeRegexToken = `<p>\s*\{\{[ \t]*include[ \t]+sd:([^\s{}#<>]+)(?:#([^\s{}<>]*))?[ \t]*\}\}\s*</p>`
+var DocumentIncludeRegex = regexp.MustCompile (DocumentIncludeRegexToken)
+
+const DocumentIncludeDepthMaximum = 8
+
+
+
+
+// NOTE:  Includes are expanded on the unsafe (i.e. not yet sanitized) HTML, thus the whole result is sanitized only once.
+// NOTE:  A failed include doesn't fail the whole document, instead it is replaced with an error marker, and a warning is logged.
+func documentIncludesExpand (_render string, _index *Index, _stack []string, _includes map[string]string) (string, *Error) {
+	
+	_matches := DocumentIncludeRegex.FindAllStringSubmatchIndex (_render, -1)
+	if len (_matches) == 0 {
+		return _render, nil
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	_offset := 0
+	for _, _match := range _matches {
+		
+		_buffer.WriteString (_render[_offset : _match[0]])
+		_offset = _match[1]
+		
+		_identifier := html.UnescapeString (_render[_match[2] : _match[3]])
+		_anchor := ""
+		if _match[4] >= 0 {
+			_anchor = html.UnescapeString (_render[_match[4] : _match[5]])
+		}
+		
+		_target := "sd:" + _identifier
+		if _anchor != "" {
+			_target += "#" + _anchor
+		}
+		
+		_included, _error := documentIncludeRender (_identifier, _anchor, _index, _stack, _includes)
+		if _error != nil {
+			logErrorf ('w', 0x465c88ac, _error, "[include]  failed including `%s` into `%s`;", _target, _stack[0])
+			_buffer.WriteString ("<div class=\"document-include document-include-error\">\n")
+			_buffer.WriteString ("<p>include of <code>" + html.EscapeString (_target) + "</code> failed: " + html.EscapeString (_error.Message) + "</p>")
+			_buffer.WriteString ("\n</div>")
+			continue
+		}
+		
+		_buffer.WriteString ("<div class=\"document-include\">\n")
+		_buffer.WriteString (strings.TrimRight (_included, "\n"))
+		_buffer.WriteString ("\n</div>")
+	}
+	_buffer.WriteString (_render[_offset :])
+	
+	return string (_buffer.Bytes ()), nil
+}
+
+
+func documentIncludeRender (_identifier string, _anchor string, _index *Index, _stack []string, _includes map[string]string) (string, *Error) {
+	
+	if len (_stack) > DocumentIncludeDepthMaximum {
+		return "", errorf (0x4ddad250, "include depth exceeded for `%s`", _stack[0])
+	}
+	
+	_document, _error := IndexDocumentResolve (_index, _identifier)
+	if _error != nil {
+		return "", _error
+	}
+	if _document == nil {
+		// NOTE:  Recorded so that the including document is rendered again once the included one exists.
+		_includes[_identifier] = ""
+		return "", errorf (0xc2b237a0, "include document `%s` not found", _identifier)
+	}
+	
+	for _, _identifier_0 := range _stack {
+		if _identifier_0 == _document.Identifier {
+			return "", errorf (0x926fe0b6, "include cycle detected for `%s`", _document.Identifier)
+		}
+	}
+	
+	_includes[_document.Identifier] = _document.BodyFingerprint
+	
+	_stack_0 := append (_stack[: len (_stack) : len (_stack)], _document.Identifier)
+	_included, _error := documentRenderToHtmlUnsafe (_document, _index, _stack_0, _includes)
+	if _error != nil {
+		return "", _error
+	}
+	
+	if _anchor != "" {
+		if _included_0, _error := documentIncludeSection (_included, _anchor); _error == nil {
+			_included = _included_0
+		} else {
+			return "", _error
+		}
+	}
+	
+	// NOTE:  The included headings (and the links to them) get prefixed identifiers, so that they don't clash with those of the including document.
+	return documentIncludeAnchorsPrefix (_included, orgAnchorSlug (_document.Identifier) + "--")
+}
+
+
+
+
+// NOTE:  The section starts at the matching heading and ends before the next heading of the same or higher level.
+func documentIncludeSection (_render string, _anchor string) (string, *Error) {
+	
+	_context := & html_parser.Node {
+			Type : html_parser.ElementNode,
+			Data : "body",
+			DataAtom : html_atom.Body,
+		}
+	_nodes, _error := html_parser.ParseFragment (strings.NewReader (_render), _context)
+	if _error != nil {
+		return "", errorw (0x801965dd, _error)
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	_level := uint (0)
+	for _, _node := range _nodes {
+		_nodeLevel := extractHeadingLevel (_node)
+		if _level == 0 {
+			if (_nodeLevel == 0) || !documentIncludeSectionMatches (_node, _anchor) {
+				continue
+			}
+			_level = _nodeLevel
+		} else if (_nodeLevel != 0) && (_nodeLevel <= _level) {
+			break
+		}
+		if _error := html_parser.Render (_buffer, _node); _error != nil {
+			return "", errorw (0xbd81d229, _error)
+		}
+	}
+	
+	if _level == 0 {
+		return "", errorf (0xfdb39804, "include section `%s` not found", _anchor)
+	}
+	
+	return string (_buffer.Bytes ()), nil
+}
+
+
+func documentIncludeAnchorsPrefix (_render string, _prefix string) (string, *Error) {
+	
+	_context := & html_parser.Node {
+			Type : html_parser.ElementNode,
+			Data : "body",
+			DataAtom : html_atom.Body,
+		}
+	_nodes, _error := html_parser.ParseFragment (strings.NewReader (_render), _context)
+	if _error != nil {
+		return "", errorw (0xa706f9f6, _error)
+	}
+	
+	var _walk func (*html_parser.Node) ()
+	_walk = func (_node *html_parser.Node) () {
+		if _node.Type == html_parser.ElementNode {
+			for _index, _attribute := range _node.Attr {
+				if _attribute.Key == "id" {
+					_node.Attr[_index].Val = _prefix + strings.TrimSpace (_attribute.Val)
+				} else if (_attribute.Key == "href") && strings.HasPrefix (_attribute.Val, "#") && (len (_attribute.Val) > 1) {
+					_node.Attr[_index].Val = "#" + _prefix + _attribute.Val[1:]
+				}
+			}
+		}
+		for _child := _node.FirstChild; _child != nil; _child = _child.NextSibling {
+			_walk (_child)
+		}
+	}
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	for _, _node := range _nodes {
+		_walk (_node)
+		if _error := html_parser.Render (_buffer, _node); _error != nil {
+			return "", errorw (0x0a787bd6, _error)
+		}
+	}
+	
+	return string (_buffer.Bytes ()), nil
+}
+
+
+// NOTE:  Not all formats assign identifiers to headings, thus the anchor is also matched against the title slug.
+func documentIncludeSectionMatches (_node *html_parser.Node, _anchor string) (bool) {
+	for _, _attribute := range _node.Attr {
+		if (_attribute.Key == "id") && (strings.TrimSpace (_attribute.Val) == _anchor) {
+			return true
+		}
+	}
+	return orgAnchorSlug (extractHeadingTitle (_node)) == _anchor
+}
+
+
+
+
+func documentIncludesFresh (_document *Document, _index *Index) (bool) {
+	for _identifier, _fingerprint := range _document.RenderIncludes {
+		_included, _error := IndexDocumentResolve (_index, _identifier)
+		if (_error != nil) || (_included == nil) || (_included.BodyFingerprint != _fingerprint) {
+			return false
+		}
+	}
+	return true
+}
+
@@ -0,0 +1,121 @@
+package zscratchpad
+
+
+import "fmt"
+import "html"
+import "strings"
+
+
+
+
+type DocumentOutlineEntry struct {
+	Level uint
+	Title string
+	Anchor string
+	Line uint
+}
+
+
+
+
+// NOTE:  The outline is built while rendering to HTML, thus it is cached together with the render.
+func DocumentOutline (_document *Document, _index *Index) ([]*DocumentOutlineEntry, *Error) {
+	if _, _error := DocumentRenderToHtml (_document, false, _index); _error != nil {
+		return nil, _error
+	}
+	return _document.HtmlOutline, nil
+}
+
+
+func documentOutlineResolveLines (_document *Document, _outline []*DocumentOutlineEntry) () {
+	
+	_lines := []uint (nil)
+	switch _document.Format {
+		case "commonmark" :
+			_lines = commonmarkHeadingLines (_document.BodyLines)
+		case "gemini" :
+			_lines = geminiHeadingLines (_document.BodyLines)
+		case "org" :
+			_lines = orgHeadingLines (_document.BodyLines)
+	}
+	
+	// NOTE:  Headings that don't come from the source (e.g. raw HTML or includes) break the correspondence, thus the lines stay unknown.
+	if len (_lines) != len (_outline) {
+		return
+	}
+	
+	for _index, _entry := range _outline {
+		_entry.Line = uint (_document.BodyOffset) + _lines[_index] + 1
+	}
+}
+
+
+
+
+func DocumentOutlineRenderHtml (_outline []*DocumentOutlineEntry) (string) {
+	
+	if len (_outline) == 0 {
+		return ""
+	}
+	
+	_levelMinimum := _outline[0].Level
+	for _, _entry := range _outline {
+		if _entry.Level < _levelMinimum {
+			_levelMinimum = _entry.Level
+		}
+	}
+	
+	_buffer := BytesBufferNewSize (16 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	_depth := uint (0)
+	for _index, _entry := range _outline {
+		_level := _entry.Level - _levelMinimum + 1
+		if _level > (_depth + 1) {
+			// NOTE:  Skipped levels (e.g. `h1` followed by `h3`) are nested only once.
+			_level = _depth + 1
+		}
+		if _level > _depth {
+			for ; _depth < _level; _depth += 1 {
+				_buffer.WriteString ("<ul>\n")
+			}
+		} else {
+			_buffer.WriteString ("</li>\n")
+			for ; _depth > _level; _depth -= 1 {
+				_buffer.WriteString ("</ul>\n</li>\n")
+			}
+		}
+		fmt.Fprintf (_buffer, "<li><a href=\"#%s\">%s</a>", html.EscapeString (_entry.Anchor), html.EscapeString (_entry.Title))
+		if _index == (len (_outline) - 1) {
+			_buffer.WriteString ("</li>\n")
+			for ; _depth > 1; _depth -= 1 {
+				_buffer.WriteString ("</ul>\n</li>\n")
+			}
+			_buffer.WriteString ("</ul>\n")
+		} else {
+			_buffer.WriteString ("\n")
+		}
+	}
+	
+	return string (_buffer.Bytes ())
+}
+
+
+func DocumentOutlineRenderText (_outline []*DocumentOutlineEntry) ([]string) {
+	
+	_levelMinimum := uint (0)
+	for _index, _entry := range _outline {
+		if (_index == 0) || (_entry.Level < _levelMinimum) {
+			_levelMinimum = _entry.Level
+		}
+	}
+	
+	_lines := make ([]string, 0, len (_outline))
+	for _, _entry := range _outline {
+		_indent := strings.Repeat ("  ", int (_entry.Level - _levelMinimum))
+		_lines = append (_lines, _indent + _entry.Title)
+	}
+	
+	return _lines
+}
+
@@ -4,6 +4,8 @@ package zscratchpad
 
 
 import "fmt"
+import "strings"
+import "time"
 
 
 
@@ -29,9 +31,27 @@ func DocumentRenderToSource (_document *Document) (string, *Error) {
 	if _document.Identifier != "" {
 		fmt.Fprintf (_buffer, "## -- identifier:  %s\n", _document.Identifier)
 	}
+	if len (_document.Aliases) != 0 {
+		fmt.Fprintf (_buffer, "## -- aliases:     %s\n", strings.Join (_document.Aliases, ", "))
+	}
 	if _document.Format != "" {
 		fmt.Fprintf (_buffer, "## -- format:      %s\n", _document.Format)
 	}
+	if len (_document.Tags) != 0 {
+		fmt.Fprintf (_buffer, "## -- tags:        %s\n", strings.Join (_document.Tags, ", "))
+	}
+	for _, _key := range DocumentMetadataKeys (_document) {
+		for _, _value := range _document.Metadata[_key] {
+			fmt.Fprintf (_buffer, "## -- %-12s %s\n", _key + ":", _value)
+		}
+	}
+	// NOTE:  Timestamps that fell back to the file modification timestamp are not written, as they would then be taken as authoritative.
+	if _document.CreatedFromHeader {
+		fmt.Fprintf (_buffer, "## -- created:     %s\n", _document.Created.Format (time.RFC3339))
+	}
+	if _document.UpdatedFromHeader {
+		fmt.Fprintf (_buffer, "## -- updated:     %s\n", _document.Updated.Format (time.RFC3339))
+	}
 	if ! _document.Timestamp.IsZero () {
 		fmt.Fprintf (_buffer, "## -- timestamp:   %s\n", _document.Timestamp.Format ("2006-01-02 15:04:05"))
 	}
@@ -3,6 +3,11 @@
 package zscratchpad
 
 
+import "fmt"
+import "strings"
+import "unicode/utf8"
+
+
 
 
 func DocumentRenderToText (_document *Document) (string, *Error) {
@@ -34,6 +39,9 @@ func DocumentRenderToText (_document *Document) (string, *Error) {
 		case "gemini" :
 			_render, _error = documentRenderGeminiToText (_document.BodyLines)
 		
+		case "org" :
+			_render, _error = documentRenderOrgToText (_document.BodyLines)
+		
 		default :
 			return "", errorf (0x215b1603, "format invalid `%s`", _document.Format)
 	}
@@ -51,22 +59,24 @@ func DocumentRenderToText (_document *Document) (string, *Error) {
 
 
 func documentRenderCommonmarkToText (_source []string) (string, *Error) {
-	// FIXME:  Implement rendering to plain text!
-	return documentRenderAnyToText (_source)
+	return parseAndRenderCommonmarkToText (_source)
 }
 
 func documentRenderGeminiToText (_source []string) (string, *Error) {
-	// FIXME:  Implement rendering to plain text!
-	return documentRenderAnyToText (_source)
+	return parseAndRenderGeminiToText (_source)
+}
+
+func documentRenderOrgToText (_source []string) (string, *Error) {
+	return parseAndRenderOrgToText (_source)
 }
 
 func documentRenderSnippetsToText (_source []string) (string, *Error) {
-	// FIXME:  Implement rendering to plain text!
+	// NOTE:  Snippets are already plain text.
 	return documentRenderAnyToText (_source)
 }
 
 func documentRenderTextToText (_source []string) (string, *Error) {
-	// FIXME:  Implement rendering to plain text!
+	// NOTE:  Text is already plain text.
 	return documentRenderAnyToText (_source)
 }
 
@@ -81,3 +91,229 @@ func documentRenderAnyToText (_source []string) (string, *Error) {
 	return _render, nil
 }
 
+
+
+
+const textRenderWidth = 78
+
+
+type textRenderer struct {
+	blocks []string
+	links []string
+	linksIndex map[string]int
+	footnotes []string
+}
+
+
+func textRendererNew () (*textRenderer) {
+	return & textRenderer {
+			linksIndex : make (map[string]int, 128),
+		}
+}
+
+
+
+
+func textRenderFinalize (_renderer *textRenderer) (string) {
+	
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	
+	for _index, _block := range _renderer.blocks {
+		if _index > 0 {
+			_buffer.WriteByte ('\n')
+		}
+		_buffer.WriteString (_block)
+	}
+	
+	if (len (_renderer.links) > 0) || (len (_renderer.footnotes) > 0) {
+		if len (_renderer.blocks) > 0 {
+			_buffer.WriteByte ('\n')
+		}
+		_buffer.WriteString ("----\n")
+		for _index, _link := range _renderer.links {
+			fmt.Fprintf (_buffer, "[%d] %s\n", _index + 1, _link)
+		}
+		for _, _footnote := range _renderer.footnotes {
+			_buffer.WriteString (_footnote)
+		}
+	}
+	
+	return string (_buffer.Bytes ())
+}
+
+
+func textRenderBlock (_renderer *textRenderer, _lines []string) () {
+	if len (_lines) == 0 {
+		return
+	}
+	_renderer.blocks = append (_renderer.blocks, strings.Join (_lines, "\n") + "\n")
+}
+
+
+// NOTE:  Returns the number of the (deduplicated) link reference, to be used as `[n]` in the text.
+func textRenderLink (_renderer *textRenderer, _url string) (int) {
+	if _index, _exists := _renderer.linksIndex[_url]; _exists {
+		return _index
+	}
+	_renderer.links = append (_renderer.links, _url)
+	_index := len (_renderer.links)
+	_renderer.linksIndex[_url] = _index
+	return _index
+}
+
+
+
+
+func textRenderHeading (_text string, _level int) ([]string) {
+	_text = stringTrimSpaces (_text)
+	_underline := byte ('~')
+	switch _level {
+		case 1 :
+			_underline = '='
+		case 2 :
+			_underline = '-'
+	}
+	_width := 0
+	_lines := textRenderWrap (_text, textRenderWidth, "", "")
+	for _, _line := range _lines {
+		if _lineWidth := utf8.RuneCountInString (_line); _lineWidth > _width {
+			_width = _lineWidth
+		}
+	}
+	return append (_lines, strings.Repeat (string (_underline), _width))
+}
+
+
+func textRenderCode (_lines []string, _indent string) ([]string) {
+	_linesIndented := make ([]string, 0, len (_lines))
+	for _, _line := range _lines {
+		_line = stringTrimSpacesRight (_line)
+		if _line != "" {
+			_line = _indent + _line
+		}
+		_linesIndented = append (_linesIndented, _line)
+	}
+	return _linesIndented
+}
+
+
+// NOTE:  Reflows the text to the given width;  explicit new-lines (i.e. hard-breaks) are kept.
+func textRenderWrap (_text string, _width int, _prefixFirst string, _prefixRest string) ([]string) {
+	
+	_lines := make ([]string, 0, 16)
+	_line := strings.Builder {}
+	_lineWidth := 0
+	_prefix := _prefixFirst
+	
+	_push := func () () {
+		_lines = append (_lines, _prefix + _line.String ())
+		_line.Reset ()
+		_lineWidth = 0
+		_prefix = _prefixRest
+	}
+	
+	_widthAvailable := _width - utf8.RuneCountInString (_prefixRest)
+	if _widthAvailable < 20 {
+		_widthAvailable = 20
+	}
+	
+	for _index, _paragraph := range strings.Split (_text, "\n") {
+		if _index > 0 {
+			_push ()
+		}
+		for _, _word := range strings.Fields (_paragraph) {
+			_wordWidth := utf8.RuneCountInString (_word)
+			if (_lineWidth > 0) && ((_lineWidth + 1 + _wordWidth) > _widthAvailable) {
+				_push ()
+			}
+			if _lineWidth > 0 {
+				_line.WriteByte (' ')
+				_lineWidth += 1
+			}
+			_line.WriteString (_word)
+			_lineWidth += _wordWidth
+		}
+	}
+	if (_lineWidth > 0) || (len (_lines) == 0) {
+		_push ()
+	}
+	
+	return _lines
+}
+
+
+func textRenderPrefix (_lines []string, _prefixFirst string, _prefixRest string) ([]string) {
+	_linesPrefixed := make ([]string, 0, len (_lines))
+	for _index, _line := range _lines {
+		_prefix := _prefixRest
+		if _index == 0 {
+			_prefix = _prefixFirst
+		}
+		if _line == "" {
+			_prefix = stringTrimSpacesRight (_prefix)
+		}
+		_linesPrefixed = append (_linesPrefixed, _prefix + _line)
+	}
+	return _linesPrefixed
+}
+
+
+// NOTE:  The alignments are `l`, `c` or `r`, one per column (missing ones default to `l`).
+func textRenderTable (_rows [][]string, _header bool, _alignments []byte) ([]string) {
+	
+	_widths := make ([]int, 0, 16)
+	for _, _row := range _rows {
+		for _column, _cell := range _row {
+			_cellWidth := utf8.RuneCountInString (_cell)
+			if _column >= len (_widths) {
+				_widths = append (_widths, _cellWidth)
+			} else if _cellWidth > _widths[_column] {
+				_widths[_column] = _cellWidth
+			}
+		}
+	}
+	
+	_lines := make ([]string, 0, len (_rows) + 1)
+	for _index, _row := range _rows {
+		_line := strings.Builder {}
+		for _column, _width := range _widths {
+			_cell := ""
+			if _column < len (_row) {
+				_cell = _row[_column]
+			}
+			_alignment := byte ('l')
+			if _column < len (_alignments) {
+				_alignment = _alignments[_column]
+			}
+			_padding := _width - utf8.RuneCountInString (_cell)
+			_paddingLeft := 0
+			switch _alignment {
+				case 'r' :
+					_paddingLeft = _padding
+				case 'c' :
+					_paddingLeft = _padding / 2
+			}
+			if _column > 0 {
+				_line.WriteString (" | ")
+			}
+			_line.WriteString (strings.Repeat (" ", _paddingLeft))
+			_line.WriteString (_cell)
+			_line.WriteString (strings.Repeat (" ", _padding - _paddingLeft))
+		}
+		_lines = append (_lines, stringTrimSpacesRight (_line.String ()))
+		if (_index == 0) && _header {
+			_separator := strings.Builder {}
+			for _column, _width := range _widths {
+				if _column > 0 {
+					_separator.WriteString ("-+-")
+				}
+				_separator.WriteString (strings.Repeat ("-", _width))
+			}
+			_lines = append (_lines, _separator.String ())
+		}
+	}
+	
+	return _lines
+}
+
@@ -5,6 +5,7 @@ package zscratchpad
 
 import "bytes"
 import "encoding/base64"
+import "fmt"
 import "net/url"
 import "strings"
 import "sort"
@@ -19,6 +20,7 @@ import "github.com/microcosm-cc/bluemonday"
 type DocumentSanitizeHtmlOutcome struct {
 	Urls map[string]*url.URL
 	UrlsLabel map[string][]string
+	Outline []*DocumentOutlineEntry
 }
 
 
@@ -63,6 +65,13 @@ func DocumentSanitizeHtml (_document *Document, _unsafe string, _mangle bool) (s
 		return "", nil, _error
 	}
 	
+	_outline := []*DocumentOutlineEntry (nil)
+	if _outline_0, _error := extractOutline (_node); _error == nil {
+		_outline = _outline_0
+	} else {
+		return "", nil, _error
+	}
+	
 	if _error := verifyAnchors (_node); _error != nil {
 		return "", nil, _error
 	}
@@ -70,6 +79,7 @@ func DocumentSanitizeHtml (_document *Document, _unsafe string, _mangle bool) (s
 	_outcome := & DocumentSanitizeHtmlOutcome {
 			Urls : _extractLinksContext.urlsParsed,
 			UrlsLabel : _extractLinksContext.urlsLabel,
+			Outline : _outline,
 		}
 	
 	_mangledBuffer := BytesBufferNewSize (128 * 1024)
@@ -114,8 +124,13 @@ func DocumentSanitizeUrl (_url *url.URL) (*Error) {
 		if _url.Opaque == "" {
 			return errorw (0x5e327a52, nil)
 		}
+		// NOTE:  The title is escaped in the opaque part, but `Path` must hold it unescaped.
+		_title, _error := url.PathUnescape (_url.Opaque)
+		if _error != nil {
+			return errorw (0xf43741ad, _error)
+		}
 		*_url = url.URL {
-				Path : "/w/" + _url.Opaque,
+				Path : "/w/" + _title,
 			}
 	}
 	
@@ -154,13 +169,11 @@ func DocumentSanitizeUrl (_url *url.URL) (*Error) {
 		return nil
 	}
 	if strings.HasPrefix (_path, "/d/") {
-		// _identifier := _path[4:]
-		// FIXME: ...
+		// NOTE:  The target is validated against the index by `DocumentLinksCheck`.
 		return nil
 	}
 	if strings.HasPrefix (_path, "/l/") {
-		// _identifier := _path[4:]
-		// FIXME: ...
+		// NOTE:  The target is validated against the index by `DocumentLinksCheck`.
 		return nil
 	}
 	if strings.HasPrefix (_path, "/i/") {
@@ -392,6 +405,112 @@ type extractLinksContext struct {
 
 
 
+// NOTE:  Headings without an identifier (e.g. from Gemini) get one, so that the outline can link to them.
+func extractOutline (_node *html.Node) ([]*DocumentOutlineEntry, *Error) {
+	
+	_anchors := make (map[string]bool, 1024)
+	if _error := collectAnchors (_node, _anchors); _error != nil {
+		return nil, _error
+	}
+	
+	_outline := make ([]*DocumentOutlineEntry, 0, 16)
+	
+	var _walk func (*html.Node) ()
+	_walk = func (_node *html.Node) () {
+		
+		_level := extractHeadingLevel (_node)
+		if _level == 0 {
+			for _child := _node.FirstChild; _child != nil; _child = _child.NextSibling {
+				_walk (_child)
+			}
+			return
+		}
+		
+		_title := extractHeadingTitle (_node)
+		if _title == "" {
+			return
+		}
+		
+		_anchor := ""
+		for _, _attribute := range _node.Attr {
+			if _attribute.Key == "id" {
+				_anchor = strings.TrimSpace (_attribute.Val)
+			}
+		}
+		if _anchor == "" {
+			_anchor = orgAnchorSlug (_title)
+			if _anchor == "" {
+				_anchor = "heading"
+			}
+			_anchorBase := _anchor
+			for _counter := 1; _anchors[_anchor]; _counter += 1 {
+				_anchor = fmt.Sprintf ("%s-%d", _anchorBase, _counter)
+			}
+			_anchors[_anchor] = true
+			_node.Attr = append (_node.Attr, html.Attribute { "", "id", _anchor })
+		}
+		
+		_entry := & DocumentOutlineEntry {
+				Level : _level,
+				Title : _title,
+				Anchor : _anchor,
+			}
+		_outline = append (_outline, _entry)
+	}
+	
+	_walk (_node)
+	
+	return _outline, nil
+}
+
+
+func extractHeadingLevel (_node *html.Node) (uint) {
+	if _node.Type != html.ElementNode {
+		return 0
+	}
+	switch _node.DataAtom {
+		case atom.H1 : return 1
+		case atom.H2 : return 2
+		case atom.H3 : return 3
+		case atom.H4 : return 4
+		case atom.H5 : return 5
+		case atom.H6 : return 6
+	}
+	return 0
+}
+
+
+func extractHeadingTitle (_node *html.Node) (string) {
+	return strings.Join (strings.Fields (extractText (_node)), " ")
+}
+
+
+func extractText (_node *html.Node) (string) {
+	_buffer := strings.Builder {}
+	var _walk func (*html.Node) ()
+	_walk = func (_node *html.Node) () {
+		switch _node.Type {
+			case html.TextNode :
+				_buffer.WriteString (_node.Data)
+			case html.ElementNode :
+				for _, _attribute := range _node.Attr {
+					// NOTE:  Org-mode heading tags are not part of the title.
+					if (_attribute.Key == "class") && strings.Contains (_attribute.Val, "org-tags") {
+						return
+					}
+				}
+		}
+		for _child := _node.FirstChild; _child != nil; _child = _child.NextSibling {
+			_walk (_child)
+		}
+	}
+	_walk (_node)
+	return _buffer.String ()
+}
+
+
+
+
 func verifyAnchors (_node *html.Node) (*Error) {
 	
 	_anchors := make (map[string]bool, 1024)
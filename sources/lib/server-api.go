@@ -0,0 +1,526 @@
+package zscratchpad
+
+
+import "fmt"
+import "net/http"
+import "net/url"
+import "strconv"
+import "strings"
+import "time"
+
+
+
+
+type serverApiLibrary struct {
+	Identifier string `json:"identifier"`
+	Name string `json:"name,omitempty"`
+	Paths []string `json:"paths,omitempty"`
+	Disabled bool `json:"disabled"`
+	EditEnabled bool `json:"edit_enabled"`
+	CreateEnabled bool `json:"create_enabled"`
+	Documents uint `json:"documents"`
+}
+
+type serverApiDocument struct {
+	Identifier string `json:"identifier"`
+	Library string `json:"library"`
+	Aliases []string `json:"aliases,omitempty"`
+	Title string `json:"title,omitempty"`
+	Titles []string `json:"titles,omitempty"`
+	Format string `json:"format"`
+	Path string `json:"path,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Created string `json:"created,omitempty"`
+	Updated string `json:"updated,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	EditEnabled bool `json:"edit_enabled"`
+}
+
+type serverApiDocumentLinks struct {
+	Documents map[string][]string `json:"documents"`
+	Libraries map[string][]string `json:"libraries"`
+	Backlinks []string `json:"backlinks"`
+}
+
+type serverApiError struct {
+	Code string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NOTE:  Tracks if the response was started, because afterwards an error can't be reported anymore.
+type serverApiResponse struct {
+	http.ResponseWriter
+	written bool
+}
+
+
+const ServerApiPrefix = "/api/v1/"
+
+
+
+
+// NOTE:  All responses (including errors) are JSON;  errors are reported as `{"error": {"code": ..., "message": ...}}`.
+func ServerHandleApi (_server *Server, _path string, _query url.Values, _response http.ResponseWriter) (*Error) {
+	
+	_path = strings.Trim (_path, "/")
+	
+	_tracker := & serverApiResponse { ResponseWriter : _response }
+	_response = _tracker
+	
+	var _error *Error
+	switch {
+		
+		case _path == "status" :
+			_error = ServerHandleApiStatus (_server, _response)
+		
+		case _path == "libraries" :
+			_error = ServerHandleApiLibraries (_server, _response)
+		case strings.HasPrefix (_path, "libraries/") :
+			_error = ServerHandleApiLibrary (_server, _path[10:], _response)
+		
+		case _path == "documents" :
+			_error = ServerHandleApiDocuments (_server, _query, _response)
+		case strings.HasPrefix (_path, "documents/") :
+			_identifier := _path[10:]
+			_render := ""
+			if _slash := strings.IndexByte (_identifier, '/'); _slash != -1 {
+				_identifier, _render = _identifier[:_slash], _identifier[_slash + 1:]
+			}
+			if _render == "" {
+				_error = ServerHandleApiDocument (_server, _identifier, _response)
+			} else {
+				_error = ServerHandleApiDocumentRender (_server, _identifier, _render, _response)
+			}
+		
+		case _path == "search" :
+			_error = ServerHandleApiSearch (_server, _query, _response)
+		case _path == "grep" :
+			_error = ServerHandleApiGrep (_server, _query, _response)
+		
+		default :
+			_error = serverApiRespondWithError (_response, http.StatusNotFound, errorw (0x81968653, nil))
+	}
+	
+	// NOTE:  Handlers respond themselves to the errors caused by the request, thus the rest are internal errors.
+	if _error != nil {
+		if _tracker.written {
+			logError ('w', _error)
+		} else if _error := serverApiRespondWithError (_response, http.StatusInternalServerError, _error); _error != nil {
+			logError ('w', _error)
+		}
+	}
+	
+	// NOTE:  Errors are never returned, otherwise the caller would respond a second time.
+	return nil
+}
+
+
+func (_response *serverApiResponse) WriteHeader (_status int) () {
+	_response.written = true
+	_response.ResponseWriter.WriteHeader (_status)
+}
+
+func (_response *serverApiResponse) Write (_data []byte) (int, error) {
+	_response.written = true
+	return _response.ResponseWriter.Write (_data)
+}
+
+
+
+
+func ServerHandleApiStatus (_server *Server, _response http.ResponseWriter) (*Error) {
+	_status, _error := IndexStatusSelect (_server.index)
+	if _error != nil {
+		return _error
+	}
+	_output := struct {
+			RefreshTimestamp string `json:"refresh_timestamp,omitempty"`
+			Libraries uint `json:"libraries"`
+			Documents uint `json:"documents"`
+			Tags uint `json:"tags"`
+			SearchDocuments uint `json:"search_documents"`
+			SearchTerms uint `json:"search_terms"`
+			Diagnostics uint `json:"diagnostics"`
+		} {
+			Libraries : _status.Libraries,
+			Documents : _status.Documents,
+			Tags : _status.Tags,
+			SearchDocuments : _status.SearchDocuments,
+			SearchTerms : _status.SearchTerms,
+			Diagnostics : _status.Diagnostics,
+		}
+	if ! _status.RefreshTimestamp.IsZero () {
+		_output.RefreshTimestamp = _status.RefreshTimestamp.Format (time.RFC3339)
+	}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+
+
+func ServerHandleApiLibraries (_server *Server, _response http.ResponseWriter) (*Error) {
+	_libraries, _error := IndexLibrariesSelectAll (_server.index)
+	if _error != nil {
+		return _error
+	}
+	_records := make ([]*serverApiLibrary, 0, len (_libraries))
+	for _, _library := range _libraries {
+		_record, _error := serverApiLibraryNew (_server, _library)
+		if _error != nil {
+			return _error
+		}
+		_records = append (_records, _record)
+	}
+	_output := struct {
+			Libraries []*serverApiLibrary `json:"libraries"`
+		} {
+			_records,
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+func ServerHandleApiLibrary (_server *Server, _identifierUnsafe string, _response http.ResponseWriter) (*Error) {
+	_library, _error := serverLibraryResolve (_server, _identifierUnsafe)
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusNotFound, _error)
+	}
+	_record, _error := serverApiLibraryNew (_server, _library)
+	if _error != nil {
+		return _error
+	}
+	_documents, _error := IndexDocumentsSelectInLibrary (_server.index, _library.Identifier)
+	if _error != nil {
+		return _error
+	}
+	_output := struct {
+			Library *serverApiLibrary `json:"library"`
+			Documents []*serverApiDocument `json:"documents"`
+		} {
+			_record,
+			serverApiDocumentsNew (_documents),
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+
+
+// NOTE:  The documents can be filtered by `library`, by `tag` (repeated, all must match), and by `q` (i.e. the query language).
+func ServerHandleApiDocuments (_server *Server, _query url.Values, _response http.ResponseWriter) (*Error) {
+	
+	_documents, _error := IndexDocumentsSelectAll (_server.index)
+	if _error != nil {
+		return _error
+	}
+	
+	_library := ""
+	if _libraryUnsafe := _query.Get ("library"); _libraryUnsafe != "" {
+		if _library_0, _error := serverLibraryResolve (_server, _libraryUnsafe); _error == nil {
+			_library = _library_0.Identifier
+		} else {
+			return serverApiRespondWithError (_response, http.StatusNotFound, _error)
+		}
+	}
+	
+	_tags := make ([]string, 0, len (_query["tag"]))
+	for _, _tagUnsafe := range _query["tag"] {
+		if _tag, _error := DocumentParseTag (_tagUnsafe); _error == nil {
+			_tags = append (_tags, _tag)
+		} else {
+			return serverApiRespondWithError (_response, http.StatusBadRequest, _error)
+		}
+	}
+	
+	var _queryParsed *Query
+	if _queryText := _query.Get ("q"); stringTrimSpaces (_queryText) != "" {
+		if _query_0, _error := QueryParse (_queryText); _error == nil {
+			_queryParsed = _query_0
+		} else {
+			return serverApiRespondWithError (_response, http.StatusBadRequest, _error)
+		}
+	}
+	
+	_documentsMatching := make ([]*Document, 0, len (_documents))
+	for _, _document := range _documents {
+		if (_library != "") && (_document.Library != _library) {
+			continue
+		}
+		if ! DocumentHasTags (_document, _tags) {
+			continue
+		}
+		if ! QueryMatches (_queryParsed, _document) {
+			continue
+		}
+		_documentsMatching = append (_documentsMatching, _document)
+	}
+	
+	_output := struct {
+			Documents []*serverApiDocument `json:"documents"`
+		} {
+			serverApiDocumentsNew (_documentsMatching),
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+func ServerHandleApiDocument (_server *Server, _identifierUnsafe string, _response http.ResponseWriter) (*Error) {
+	_document, _error := serverDocumentResolve (_server, _identifierUnsafe)
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusNotFound, _error)
+	}
+	_links, _error := IndexDocumentLinksSelect (_server.index, _document.Identifier)
+	if _error != nil {
+		return _error
+	}
+	_backlinks, _error := IndexDocumentBacklinksSelect (_server.index, _document.Identifier)
+	if _error != nil {
+		return _error
+	}
+	_output := struct {
+			Document *serverApiDocument `json:"document"`
+			Links *serverApiDocumentLinks `json:"links"`
+		} {
+			serverApiDocumentNew (_document),
+			& serverApiDocumentLinks {
+					Documents : _links.Documents,
+					Libraries : _links.Libraries,
+					Backlinks : _backlinks,
+				},
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+func ServerHandleApiDocumentRender (_server *Server, _identifierUnsafe string, _render string, _response http.ResponseWriter) (*Error) {
+	_document, _error := serverDocumentResolve (_server, _identifierUnsafe)
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusNotFound, _error)
+	}
+	var _content string
+	switch _render {
+		case "source" :
+			_content, _error = DocumentRenderToSource (_document)
+		case "text" :
+			_content, _error = DocumentRenderToText (_document)
+		case "html" :
+			_content, _error = DocumentRenderToHtml (_document, false, _server.index)
+		default :
+			return serverApiRespondWithError (_response, http.StatusNotFound, errorw (0x1d8dce05, nil))
+	}
+	if _error != nil {
+		return _error
+	}
+	_output := struct {
+			Identifier string `json:"identifier"`
+			Format string `json:"format"`
+			Render string `json:"render"`
+			Content string `json:"content"`
+		} {
+			_document.Identifier,
+			_document.Format,
+			_render,
+			_content,
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+
+
+// NOTE:  The ranked search, as `/sr` does, with `stem` and `any` taken as booleans.
+func ServerHandleApiSearch (_server *Server, _query url.Values, _response http.ResponseWriter) (*Error) {
+	
+	_queryText := _query.Get ("q")
+	if stringTrimSpaces (_queryText) == "" {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, errorw (0xa124a1e9, nil))
+	}
+	
+	_stemming, _error := serverApiQueryBool (_query, "stem")
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, _error)
+	}
+	_matchAny, _error := serverApiQueryBool (_query, "any")
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, _error)
+	}
+	
+	// NOTE:  A query without searchable terms (e.g. only punctuation) is the only way the search fails because of the request.
+	if len (IndexSearchQueryParse (_queryText)) == 0 {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, errorf (0x5cec1f1a, "search query has no searchable terms"))
+	}
+	
+	_results, _error := IndexSearchSelect (_server.index, _queryText, _stemming, _matchAny)
+	if _error != nil {
+		return _error
+	}
+	
+	type result struct {
+		Document *serverApiDocument `json:"document"`
+		Score float64 `json:"score"`
+	}
+	_records := make ([]*result, 0, len (_results))
+	for _, _result := range _results {
+		_records = append (_records, & result {
+				Document : serverApiDocumentNew (_result.Document),
+				Score : _result.Score,
+			})
+	}
+	
+	_output := struct {
+			Query string `json:"query"`
+			Results []*result `json:"results"`
+		} {
+			_queryText,
+			_records,
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+// NOTE:  Uses the query language (as `/s` does), and reports the body lines that match, with 1-based lines (of the source file) and byte columns.
+func ServerHandleApiGrep (_server *Server, _query url.Values, _response http.ResponseWriter) (*Error) {
+	
+	_queryText := _query.Get ("q")
+	if stringTrimSpaces (_queryText) == "" {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, errorw (0x216babea, nil))
+	}
+	_queryParsed, _error := QueryParse (_queryText)
+	if _error != nil {
+		return serverApiRespondWithError (_response, http.StatusBadRequest, _error)
+	}
+	
+	_documents, _error := IndexDocumentsSelectAll (_server.index)
+	if _error != nil {
+		return _error
+	}
+	_patterns := QueryHighlightPatterns (_queryParsed)
+	
+	type line struct {
+		Line uint `json:"line"`
+		Column uint `json:"column"`
+		Text string `json:"text"`
+	}
+	type result struct {
+		Document *serverApiDocument `json:"document"`
+		Lines []*line `json:"lines"`
+	}
+	_records := make ([]*result, 0, 128)
+	for _, _document := range _documents {
+		if ! QueryMatches (_queryParsed, _document) {
+			continue
+		}
+		_lines := make ([]*line, 0, 16)
+		for _index, _text := range _document.BodyLines {
+			_ranges := queryHighlightRanges (_text, _patterns)
+			if len (_ranges) == 0 {
+				continue
+			}
+			_lines = append (_lines, & line {
+					Line : uint (_document.BodyOffset) + uint (_index) + 1,
+					Column : uint (_ranges[0][0]) + 1,
+					Text : _text,
+				})
+		}
+		_records = append (_records, & result {
+				Document : serverApiDocumentNew (_document),
+				Lines : _lines,
+			})
+	}
+	
+	_output := struct {
+			Query string `json:"query"`
+			Results []*result `json:"results"`
+		} {
+			_queryText,
+			_records,
+		}
+	return respondWithJson (_response, http.StatusOK, _output)
+}
+
+
+
+
+func serverApiLibraryNew (_server *Server, _library *Library) (*serverApiLibrary, *Error) {
+	_documents, _error := IndexDocumentsSelectInLibrary (_server.index, _library.Identifier)
+	if _error != nil {
+		return nil, _error
+	}
+	_record := & serverApiLibrary {
+			Identifier : _library.Identifier,
+			Name : _library.Name,
+			Paths : _library.Paths,
+			Disabled : _library.Disabled,
+			EditEnabled : _library.EditEnabled,
+			CreateEnabled : _library.CreateEnabled,
+			Documents : uint (len (_documents)),
+		}
+	return _record, nil
+}
+
+
+func serverApiDocumentsNew (_documents []*Document) ([]*serverApiDocument) {
+	_records := make ([]*serverApiDocument, 0, len (_documents))
+	for _, _document := range _documents {
+		_records = append (_records, serverApiDocumentNew (_document))
+	}
+	return _records
+}
+
+func serverApiDocumentNew (_document *Document) (*serverApiDocument) {
+	_record := & serverApiDocument {
+			Identifier : _document.Identifier,
+			Library : _document.Library,
+			Aliases : _document.Aliases,
+			Title : _document.Title,
+			Titles : _document.TitleAlternatives,
+			Format : _document.Format,
+			// NOTE:  Only the path relative to the library is exposed, as the absolute one leaks details about the host.
+			Path : _document.PathInLibrary,
+			Tags : _document.Tags,
+			Metadata : _document.Metadata,
+			Fingerprint : _document.SourceFingerprint,
+			EditEnabled : _document.EditEnabled,
+		}
+	if ! _document.Timestamp.IsZero () {
+		_record.Timestamp = _document.Timestamp.Format (time.RFC3339)
+	}
+	if ! _document.Created.IsZero () {
+		_record.Created = _document.Created.Format (time.RFC3339)
+	}
+	if ! _document.Updated.IsZero () {
+		_record.Updated = _document.Updated.Format (time.RFC3339)
+	}
+	return _record
+}
+
+
+
+
+// NOTE:  A missing (or empty) parameter is false.
+func serverApiQueryBool (_query url.Values, _name string) (bool, *Error) {
+	_value := _query.Get (_name)
+	if _value == "" {
+		return false, nil
+	}
+	if _value_0, _error := strconv.ParseBool (_value); _error == nil {
+		return _value_0, nil
+	} else {
+		return false, errorw (0x7dce9672, _error)
+	}
+}
+
+
+func serverApiRespondWithError (_response http.ResponseWriter, _status int, _error *Error) (*Error) {
+	_output := struct {
+			Error *serverApiError `json:"error"`
+		} {
+			& serverApiError {
+					Code : fmt.Sprintf ("%08x", _error.Code),
+					Message : _error.ToError () .Error (),
+				},
+		}
+	return respondWithJson (_response, _status, _output)
+}
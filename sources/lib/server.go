@@ -5,11 +5,15 @@ package zscratchpad
 
 import "bytes"
 import "encoding/base64"
+import "encoding/json"
 import "encoding/xml"
+import "fmt"
 import "io"
 import "net"
 import "net/http"
 import "net/url"
+import "sort"
+import "strconv"
 import "strings"
 
 import html_template "html/template"
@@ -54,6 +58,10 @@ type serverHandler struct {
 }
 
 
+const ServerGraphDepthMaximum = 3
+const ServerSearchExcerptsMaximum = 3
+
+
 
 
 func ServerNew (_globals *Globals, _index *Index, _editor *Editor, _browser *Browser, _listener net.Listener) (*Server, *Error) {
@@ -141,6 +149,14 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 		return respondWithTextString (_response, "OK\n")
 	}
 	
+	// NOTE:  API clients expect JSON (and a proper status), not the usual plain-text error.
+	_authenticationFailed := func (_status int, _error *Error) (*Error) {
+			if strings.HasPrefix (_path, ServerApiPrefix) {
+				return serverApiRespondWithError (_response, _status, _error)
+			}
+			return _error
+		}
+	
 	_setAuthenticationCookie := func (_server *Server, _response http.ResponseWriter) (*Error) {
 			_mac, _error := generateHmac (_server.AuthenticationCookieSecret, "/__/authenticate/{cookie}")
 			if _error != nil {
@@ -192,24 +208,27 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 	
 	if _tokens, _ := _request.URL.Query () ["authenticate"]; _tokens != nil {
 		if len (_tokens) != 1 {
-			return errorw (0xc54dcef5, nil)
+			return _authenticationFailed (http.StatusBadRequest, errorw (0xc54dcef5, nil))
 		}
 		if _error := verifyHmac (_server.AuthenticationCookieSecret, "/__/authenticate/{query}", _tokens[0], 6 * 1000); _error != nil {
-			return _error
+			return _authenticationFailed (http.StatusForbidden, _error)
 		}
 		if _error := _setAuthenticationCookie (_server, _response); _error != nil {
 			return _error
 		}
-		return respondWithRedirect (_response, _path)
+		// NOTE:  API clients can't be expected to follow redirects, thus the request is served directly (besides setting the cookie).
+		if ! strings.HasPrefix (_path, ServerApiPrefix) {
+			return respondWithRedirect (_response, _path)
+		}
 	} else if _cookie, _error := _request.Cookie (_server.AuthenticationCookieName); _error == nil {
 		if _error := verifyHmac (_server.AuthenticationCookieSecret, "/__/authenticate/{cookie}", _cookie.Value, _server.AuthenticationCookieTimeout * 1000); _error != nil {
-			return _error
+			return _authenticationFailed (http.StatusForbidden, _error)
 		}
 		if _error := _setAuthenticationCookie (_server, _response); _error != nil {
 			return _error
 		}
 	} else {
-		return errorw (0xcf851b50, _error)
+		return _authenticationFailed (http.StatusUnauthorized, errorw (0xcf851b50, _error))
 	}
 	
 	if _path == "/__/reload" {
@@ -224,19 +243,29 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 	}
 	
 	if (_path == "/d") || (_path == "/d/") || (_path == "/documents") || (_path == "/documents/") {
-		return ServerHandleDocumentsIndex (_server, _response)
+		_query := _request.URL.Query ().Get ("q")
+		return ServerHandleDocumentsIndex (_server, _query, _response)
 	}
 	if (_path == "/l") || (_path == "/l/") || (_path == "/libraries") || (_path == "/libraries/") {
 		return ServerHandleLibrariesIndex (_server, _response)
 	}
 	
+	if (_path == "/t") || (_path == "/t/") || (_path == "/tags") || (_path == "/tags/") {
+		return ServerHandleTagsIndex (_server, _response)
+	}
+	
 	if strings.HasPrefix (_path, "/l/") {
 		_identifier := _path[3:]
 		return ServerHandleLibraryView (_server, _identifier, _response)
 	}
+	if strings.HasPrefix (_path, "/t/") {
+		_tag := _path[3:]
+		return ServerHandleTagView (_server, _tag, _response)
+	}
 	if strings.HasPrefix (_path, "/d/") {
 		_identifier := _path[3:]
-		return ServerHandleDocumentView (_server, _identifier, _response)
+		_query := _request.URL.Query ().Get ("q")
+		return ServerHandleDocumentView (_server, _identifier, _query, _response)
 	}
 	if strings.HasPrefix (_path, "/df/") {
 		_identifier := _path[4:]
@@ -259,6 +288,29 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 		return ServerHandleDocumentExportSource (_server, _identifier, _response)
 	}
 	
+	if (_path == "/s") || (_path == "/s/") {
+		_query := _request.URL.Query ().Get ("q")
+		return ServerHandleSearch (_server, _query, _response)
+	}
+	if (_path == "/sr") || (_path == "/sr/") {
+		_query := _request.URL.Query ()
+		_stemming := _query.Get ("stem") != ""
+		return ServerHandleSearchRanked (_server, _query.Get ("q"), _stemming, _response)
+	}
+	
+	if strings.HasPrefix (_path, "/g/") {
+		_identifier := _path[3:]
+		_depth := uint (1)
+		if _values, _ := _request.URL.Query () ["depth"]; _values != nil {
+			if _depth_0, _error := strconv.ParseUint (_values[0], 10, 8); _error == nil {
+				_depth = uint (_depth_0)
+			} else {
+				return errorw (0x9bc98652, _error)
+			}
+		}
+		return ServerHandleGraphView (_server, _identifier, _depth, _response)
+	}
+	
 	if strings.HasPrefix (_path, "/de/") {
 		_identifier := _path[4:]
 		return ServerHandleDocumentEdit (_server, _identifier, _response)
@@ -273,6 +325,16 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 		return ServerHandleDocumentCreate (_server, _identifier, _response)
 	}
 	
+	if strings.HasPrefix (_path, "/w/") {
+		_title := _path[3:]
+		return ServerHandleWikiView (_server, _title, _response)
+	}
+	if strings.HasPrefix (_path, "/wc/") {
+		// NOTE:  The title is escaped by the link, and it could contain `/`, thus the raw path is used.
+		_title := strings.TrimPrefix (_request.URL.EscapedPath (), "/wc/")
+		return ServerHandleWikiCreate (_server, _title, _response)
+	}
+	
 	if strings.HasPrefix (_path, "/ul/") {
 		_url := _path[4:]
 		return ServerHandleUrlLaunch (_server, _url, _response)
@@ -300,6 +362,11 @@ func ServerHandle (_server *Server, _request *http.Request, _response http.Respo
 		return ServerHandleClipboardStore (_server, _data, _response)
 	}
 	
+	if strings.HasPrefix (_path, ServerApiPrefix) {
+		_apiPath := _path[len (ServerApiPrefix):]
+		return ServerHandleApi (_server, _apiPath, _request.URL.Query (), _response)
+	}
+	
 	if _path == "/__/version" {
 		return ServerHandleVersion (_server, _response)
 	}
@@ -386,17 +453,32 @@ func ServerHandleLibrariesIndex (_server *Server, _response http.ResponseWriter)
 }
 
 
-func ServerHandleDocumentsIndex (_server *Server, _response http.ResponseWriter) (*Error) {
+func ServerHandleDocumentsIndex (_server *Server, _queryText string, _response http.ResponseWriter) (*Error) {
 	_documents, _error := IndexDocumentsSelectAll (_server.index)
 	if _error != nil {
 		return _error
 	}
+	if stringTrimSpaces (_queryText) != "" {
+		_query, _error := QueryParse (_queryText)
+		if _error != nil {
+			return _error
+		}
+		_documentsMatching := make ([]*Document, 0, len (_documents))
+		for _, _document := range _documents {
+			if QueryMatches (_query, _document) {
+				_documentsMatching = append (_documentsMatching, _document)
+			}
+		}
+		_documents = _documentsMatching
+	}
 	_context := struct {
 			Server *Server
 			Documents []*Document
+			Query string
 		} {
 			_server,
 			_documents,
+			_queryText,
 		}
 	return respondWithHtmlTemplate (_response, _server.templates.documentsIndexHtml, _context, true)
 }
@@ -427,31 +509,247 @@ func ServerHandleLibraryView (_server *Server, _identifierUnsafe string, _respon
 
 
 
+func ServerHandleTagsIndex (_server *Server, _response http.ResponseWriter) (*Error) {
+	_tags, _error := IndexTagsSelectAll (_server.index)
+	if _error != nil {
+		return _error
+	}
+	_context := struct {
+			Server *Server
+			Tags []string
+		} {
+			_server,
+			_tags,
+		}
+	return respondWithHtmlTemplate (_response, _server.templates.tagsIndexHtml, _context, true)
+}
+
+
+func ServerHandleTagView (_server *Server, _tagUnsafe string, _response http.ResponseWriter) (*Error) {
+	_tag, _error := DocumentParseTag (_tagUnsafe)
+	if _error != nil {
+		return _error
+	}
+	_documents, _error := IndexDocumentsSelectWithTags (_server.index, []string { _tag })
+	if _error != nil {
+		return _error
+	}
+	_context := struct {
+			Server *Server
+			Tag string
+			Documents []*Document
+		} {
+			_server,
+			_tag,
+			_documents,
+		}
+	return respondWithHtmlTemplate (_response, _server.templates.tagViewHtml, _context, true)
+}
+
 
-func ServerHandleDocumentView (_server *Server, _identifierUnsafe string, _response http.ResponseWriter) (*Error) {
+
+
+func ServerHandleDocumentView (_server *Server, _identifierUnsafe string, _queryText string, _response http.ResponseWriter) (*Error) {
 	_document, _library, _error := serverDocumentAndLibraryResolve (_server, _identifierUnsafe)
 	if _error != nil {
 		return _error
 	}
-	_documentHtml, _error := DocumentRenderToHtml (_document, false)
+	if _document.Identifier != _identifierUnsafe {
+		if _queryText != "" {
+			return respondWithRedirect (_response, "/d/" + _document.Identifier + "?q=" + url.QueryEscape (_queryText))
+		}
+		return respondWithRedirect (_response, "/d/" + _document.Identifier)
+	}
+	_documentHtml, _error := DocumentRenderToHtml (_document, false, _server.index)
 	if _error != nil {
 		return _error
 	}
+	// NOTE:  The terms of the query (if coming from the search page) are highlighted;  an invalid query is just ignored.
+	if stringTrimSpaces (_queryText) != "" {
+		if _query, _error := QueryParse (_queryText); _error == nil {
+			if _documentHtml_0, _error := QueryHighlightHtml (_documentHtml, QueryHighlightPatterns (_query)); _error == nil {
+				_documentHtml = _documentHtml_0
+			} else {
+				return _error
+			}
+		} else {
+			_queryText = ""
+		}
+	}
+	_documentOutline := DocumentOutlineRenderHtml (_document.HtmlOutline)
+	_documentBacklinks := make ([]*Document, 0, 16)
+	if _identifiers, _error := IndexDocumentBacklinksSelect (_server.index, _document.Identifier); _error == nil {
+		for _, _identifier := range _identifiers {
+			if _backlink, _error := IndexDocumentResolve (_server.index, _identifier); _error == nil {
+				if _backlink != nil {
+					_documentBacklinks = append (_documentBacklinks, _backlink)
+				}
+			} else {
+				return _error
+			}
+		}
+	} else {
+		return _error
+	}
 	_context := struct {
 			Server *Server
 			Library *Library
 			Document *Document
 			DocumentHtml html_template.HTML
+			DocumentOutline html_template.HTML
+			DocumentBacklinks []*Document
+			Query string
 		} {
 			_server,
 			_library,
 			_document,
 			html_template.HTML (_documentHtml),
+			html_template.HTML (_documentOutline),
+			_documentBacklinks,
+			_queryText,
 		}
 	return respondWithHtmlTemplate (_response, _server.templates.documentViewHtml, _context, true)
 }
 
 
+// NOTE:  The same query language as `grep --query` is used, with the documents matching in the title listed first.
+// NOTE:  If the query is made only of plain terms, the results are ordered by their BM25 score (as `grep --ranked` does), and only then by the title matching.
+func ServerHandleSearch (_server *Server, _queryText string, _response http.ResponseWriter) (*Error) {
+	type result struct {
+		Document *Document
+		Title []*QueryHighlightFragment
+		TitleMatched bool
+		Excerpts []*QueryExcerpt
+		Score float64
+	}
+	_results := make ([]*result, 0, 128)
+	_queryError := ""
+	if stringTrimSpaces (_queryText) != "" {
+		if _query, _error := QueryParse (_queryText); _error == nil {
+			_documents, _error := IndexDocumentsSelectAll (_server.index)
+			if _error != nil {
+				return _error
+			}
+			_patterns := QueryHighlightPatterns (_query)
+			_scores := map[string]float64 (nil)
+			if _terms, _plain := QueryTermsPlain (_query); _plain {
+				// NOTE:  The filtering is still done by the query, which also matches parts of words, thus the scoring must consider any of the terms.
+				_searchQuery := make ([]string, 0, len (_terms))
+				for _, _term := range _terms {
+					_searchQuery = append (_searchQuery, "\"" + strings.ReplaceAll (_term, "\"", " ") + "\"")
+				}
+				// NOTE:  The search fails only if the terms have no searchable tokens (e.g. just punctuation), in which case the results are left unranked.
+				if _searchResults, _error := IndexSearchSelect (_server.index, strings.Join (_searchQuery, " "), false, true); _error == nil {
+					_scores = make (map[string]float64, len (_searchResults))
+					for _, _searchResult := range _searchResults {
+						_scores[_searchResult.Document.Identifier] = _searchResult.Score
+					}
+				}
+			}
+			for _, _document := range _documents {
+				if ! QueryMatches (_query, _document) {
+					continue
+				}
+				_title := _document.Title
+				if _title == "" {
+					_title = "[" + _document.Identifier + "]"
+				}
+				_result := & result {
+						Document : _document,
+						Title : QueryHighlightFragments (_title, _patterns),
+						Excerpts : QueryExcerpts (_document, _patterns, ServerSearchExcerptsMaximum),
+						Score : _scores[_document.Identifier],
+					}
+				for _, _fragment := range _result.Title {
+					_result.TitleMatched = _result.TitleMatched || _fragment.Match
+				}
+				_results = append (_results, _result)
+			}
+			sort.SliceStable (_results, func (_left int, _right int) (bool) {
+					if _results[_left].Score != _results[_right].Score {
+						return _results[_left].Score > _results[_right].Score
+					}
+					return _results[_left].TitleMatched && !_results[_right].TitleMatched
+				})
+		} else {
+			_queryError = _error.Message
+		}
+	}
+	_context := struct {
+			Server *Server
+			Query string
+			QueryError string
+			Results []*result
+		} {
+			_server,
+			_queryText,
+			_queryError,
+			_results,
+		}
+	return respondWithHtmlTemplate (_response, _server.templates.searchHtml, _context, true)
+}
+
+
+// NOTE:  Meant for scripts, thus it outputs one result per line, as `{identifier}\t{score}\t{title}`.
+func ServerHandleSearchRanked (_server *Server, _query string, _stemming bool, _response http.ResponseWriter) (*Error) {
+	_results, _error := IndexSearchSelect (_server.index, _query, _stemming, false)
+	if _error != nil {
+		return _error
+	}
+	_buffer := BytesBufferNewSize (128 * 1024)
+	defer BytesBufferRelease (_buffer)
+	for _, _result := range _results {
+		fmt.Fprintf (_buffer, "%s\t%.4f\t%s\n", _result.Document.Identifier, _result.Score, _result.Document.Title)
+	}
+	return respondWithTextBuffer (_response, _buffer)
+}
+
+
+func ServerHandleGraphView (_server *Server, _identifierUnsafe string, _depth uint, _response http.ResponseWriter) (*Error) {
+	if _depth > ServerGraphDepthMaximum {
+		return errorw (0xf9d4c507, nil)
+	}
+	_document, _library, _error := serverDocumentAndLibraryResolve (_server, _identifierUnsafe)
+	if _error != nil {
+		return _error
+	}
+	if _document.Identifier != _identifierUnsafe {
+		return respondWithRedirect (_response, "/g/" + _document.Identifier)
+	}
+	_documents, _error := IndexDocumentsSelectAll (_server.index)
+	if _error != nil {
+		return _error
+	}
+	_graph, _error := DocumentGraphBuild (_server.index, _documents, _document, _depth)
+	if _error != nil {
+		return _error
+	}
+	_graphSvg := DocumentGraphRenderSvg (_graph)
+	_depths := make ([]uint, 0, ServerGraphDepthMaximum)
+	for _depth_0 := uint (1); _depth_0 <= ServerGraphDepthMaximum; _depth_0 += 1 {
+		_depths = append (_depths, _depth_0)
+	}
+	_context := struct {
+			Server *Server
+			Library *Library
+			Document *Document
+			Graph *DocumentGraph
+			GraphSvg html_template.HTML
+			Depth uint
+			Depths []uint
+		} {
+			_server,
+			_library,
+			_document,
+			_graph,
+			html_template.HTML (_graphSvg),
+			_depth,
+			_depths,
+		}
+	return respondWithHtmlTemplate (_response, _server.templates.graphViewHtml, _context, true)
+}
+
+
 func ServerHandleDocumentFingerprint (_server *Server, _identifierUnsafe string, _response http.ResponseWriter) (*Error) {
 	_document, _error := serverDocumentResolve (_server, _identifierUnsafe)
 	if _error != nil {
@@ -466,7 +764,7 @@ func ServerHandleDocumentExportHtml (_server *Server, _identifierUnsafe string,
 	if _error != nil {
 		return _error
 	}
-	_documentHtml, _error := DocumentRenderToHtml (_document, true)
+	_documentHtml, _error := DocumentRenderToHtml (_document, true, _server.index)
 	if _error != nil {
 		return _error
 	}
@@ -490,7 +788,7 @@ func ServerHandleDocumentExportHtmlDocument (_server *Server, _identifierUnsafe
 	}
 	_buffer := BytesBufferNewSize (128 * 1024)
 	defer BytesBufferRelease (_buffer)
-	if _error := DocumentRenderToHtmlDocument (_document, true, _theme, _server.templates, _buffer); _error != nil {
+	if _error := DocumentRenderToHtmlDocument (_document, true, _theme, false, _server.index, _server.templates, _buffer); _error != nil {
 		return _error
 	}
 	return respondWithHtmlBuffer (_response, _buffer)
@@ -575,6 +873,58 @@ func ServerHandleDocumentEdit (_server *Server, _identifierUnsafe string, _respo
 
 
 
+func ServerHandleWikiView (_server *Server, _titleUnsafe string, _response http.ResponseWriter) (*Error) {
+	_title := strings.Join (strings.Fields (_titleUnsafe), " ")
+	if _title == "" {
+		return errorw (0x3ee119d2, nil)
+	}
+	_documents, _error := IndexDocumentsSelectWithTitle (_server.index, _title)
+	if _error != nil {
+		return _error
+	}
+	if len (_documents) == 1 {
+		return respondWithRedirect (_response, "/d/" + _documents[0].Identifier)
+	}
+	_context := struct {
+			Server *Server
+			Title string
+			Documents []*Document
+			CreateEnabled bool
+		} {
+			_server,
+			_title,
+			_documents,
+			_server.CreateEnabled && (_server.editor != nil),
+		}
+	return respondWithHtmlTemplate (_response, _server.templates.wikiViewHtml, _context, true)
+}
+
+
+func ServerHandleWikiCreate (_server *Server, _titleEscaped string, _response http.ResponseWriter) (*Error) {
+	if !_server.CreateEnabled {
+		return errorw (0x10c24d01, nil)
+	}
+	if _server.editor == nil {
+		return errorw (0x71594ff2, nil)
+	}
+	_titleUnsafe, _error := url.PathUnescape (_titleEscaped)
+	if _error != nil {
+		return errorw (0xce92e7ae, _error)
+	}
+	_title := strings.Join (strings.Fields (_titleUnsafe), " ")
+	if _title == "" {
+		return errorw (0x78b28f7c, nil)
+	}
+	if _error := WorkflowDocumentCreateWithTitle ("", _title, _server.index, _server.editor, false); _error != nil {
+		return _error
+	}
+	http.Error (_response, "", http.StatusNoContent)
+	return nil
+}
+
+
+
+
 func ServerHandleUrlLaunch (_server *Server, _urlEncoded string, _response http.ResponseWriter) (*Error) {
 	// FIXME:  We should add some type of signature so that we aren't injected malicious URL's!
 	// FIXME:  We should make sure this is via a `POST` request!
@@ -849,6 +1199,24 @@ func respondWithTextTemplate (_response http.ResponseWriter, _template *text_tem
 
 
 
+func respondWithJson (_response http.ResponseWriter, _status int, _value interface{}) (*Error) {
+	_buffer := bytes.NewBuffer (nil)
+	_encoder := json.NewEncoder (_buffer)
+	_encoder.SetIndent ("", "  ")
+	if _error := _encoder.Encode (_value); _error != nil {
+		return errorw (0xdb003f90, _error)
+	}
+	_response.Header () .Add ("Content-Type", "application/json; charset=utf-8")
+	_response.WriteHeader (_status)
+	if _, _error := _buffer.WriteTo (_response); _error != nil {
+		return errorw (0x0ba7a13f, _error)
+	}
+	return nil
+}
+
+
+
+
 func respondWithHtmlString (_response http.ResponseWriter, _body string) (*Error) {
 	_buffer := bytes.NewBufferString (_body)
 	return respondWithHtmlBuffer (_response, _buffer)
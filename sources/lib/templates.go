@@ -4,6 +4,7 @@ package zscratchpad
 
 
 import "io/fs"
+import "net/url"
 import "path"
 
 import html_template "html/template"
@@ -32,6 +33,9 @@ type Templates struct {
 	libraryViewHtml *html_template.Template
 	libraryViewText *text_template.Template
 	
+	tagsIndexHtml *html_template.Template
+	tagViewHtml *html_template.Template
+	
 	documentViewHtml *html_template.Template
 	documentViewText *text_template.Template
 	
@@ -43,6 +47,11 @@ type Templates struct {
 	urlOpenHtml *html_template.Template
 	urlErrorHtml *html_template.Template
 	
+	wikiViewHtml *html_template.Template
+	
+	graphViewHtml *html_template.Template
+	searchHtml *html_template.Template
+	
 	versionHtml *html_template.Template
 	
 	assets fs.FS
@@ -123,6 +132,19 @@ func TemplatesNew () (*Templates, *Error) {
 	}
 	
 	
+	if _template, _error := html_template.New ("") .Parse (embedded.TagsIndexHtml); _error == nil {
+		_templates.tagsIndexHtml = _template
+	} else {
+		return nil, errorw (0xd43f5b91, _error)
+	}
+	
+	if _template, _error := html_template.New ("") .Parse (embedded.TagViewHtml); _error == nil {
+		_templates.tagViewHtml = _template
+	} else {
+		return nil, errorw (0x64b9ca5b, _error)
+	}
+	
+	
 	if _template, _error := html_template.New ("") .Parse (embedded.DocumentViewHtml); _error == nil {
 		_templates.documentViewHtml = _template
 	} else {
@@ -174,6 +196,27 @@ func TemplatesNew () (*Templates, *Error) {
 	}
 	
 	
+	if _template, _error := html_template.New ("") .Funcs (templatesHtmlFunctions) .Parse (embedded.WikiViewHtml); _error == nil {
+		_templates.wikiViewHtml = _template
+	} else {
+		return nil, errorw (0x4d637b9f, _error)
+	}
+	
+	
+	if _template, _error := html_template.New ("") .Parse (embedded.GraphViewHtml); _error == nil {
+		_templates.graphViewHtml = _template
+	} else {
+		return nil, errorw (0xa938eeb7, _error)
+	}
+	
+	
+	if _template, _error := html_template.New ("") .Parse (embedded.SearchHtml); _error == nil {
+		_templates.searchHtml = _template
+	} else {
+		return nil, errorw (0x7df75611, _error)
+	}
+	
+	
 	if _template, _error := html_template.New ("") .Parse (embedded.VersionHtml); _error == nil {
 		_templates.versionHtml = _template
 	} else {
@@ -187,11 +230,16 @@ func TemplatesNew () (*Templates, *Error) {
 			_templates.librariesIndexHtml,
 			_templates.documentsIndexHtml,
 			_templates.libraryViewHtml,
+			_templates.tagsIndexHtml,
+			_templates.tagViewHtml,
 			_templates.documentViewHtml,
 			_templates.documentExportHtml,
 			_templates.documentExportHtmlDocument,
 			_templates.urlOpenHtml,
 			_templates.urlErrorHtml,
+			_templates.wikiViewHtml,
+			_templates.graphViewHtml,
+			_templates.searchHtml,
 			_templates.versionHtml,
 	} {
 		if _, _error := _topTemplate.New ("global-partials") .Parse (embedded.GlobalPartialsHtml); _error != nil {
@@ -240,3 +288,10 @@ func TemplatesAssetResolve (_templates *Templates, _path string) (string, []byte
 	return _contentType, _data, nil
 }
 
+
+
+
+// NOTE:  Values placed in URL paths must be escaped explicitly, as the template engine only escapes what would break the attribute.
+var templatesHtmlFunctions = html_template.FuncMap {
+		"pathEscape" : url.PathEscape,
+	}
@@ -0,0 +1,410 @@
+package zscratchpad
+
+
+import "bytes"
+import "fmt"
+import "os"
+import "sort"
+import "strings"
+import "unicode"
+import "unicode/utf8"
+
+import "golang.org/x/sys/unix"
+
+
+
+
+type terminalSelector struct {
+	options []string
+	prompt string
+	input bool
+	preview func (string) (string)
+	previewCache map[string][]string
+	query []rune
+	matches []int
+	marked map[int]bool
+	cursor int
+	offset int
+	width int
+	height int
+}
+
+
+
+
+// NOTE:  Used when no external select tool is available;  it is a minimal `fzf` replacement, working directly on the terminal.
+// NOTE:  The keys are:  `Up`, `Down`, `PageUp`, `PageDown` (and `Ctrl+P`, `Ctrl+N`) to move;  `Tab` to mark (for multiple selection);  `Enter` to accept;  `Esc` (or `Ctrl+C`, `Ctrl+G`) to cancel.
+func terminalSelect (_globals *Globals, _options []string, _prompt string, _default string, _input bool, _preview func (string) (string)) ([]string, *Error) {
+	
+	_tty := _globals.TerminalTty
+	if _tty == nil {
+		return nil, errorw (0x6fca354c, nil)
+	}
+	_descriptor := int (_tty.Fd ())
+	
+	_termios, _error := unix.IoctlGetTermios (_descriptor, terminalIoctlGetTermios)
+	if _error != nil {
+		return nil, errorw (0x76225ab8, _error)
+	}
+	_termiosRaw := *_termios
+	_termiosRaw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	_termiosRaw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	_termiosRaw.Cc[unix.VMIN] = 1
+	_termiosRaw.Cc[unix.VTIME] = 0
+	if _error := unix.IoctlSetTermios (_descriptor, terminalIoctlSetTermios, & _termiosRaw); _error != nil {
+		return nil, errorw (0xc1a7476d, _error)
+	}
+	defer unix.IoctlSetTermios (_descriptor, terminalIoctlSetTermios, _termios)
+	
+	// NOTE:  The alternate screen is used, thus the previous terminal contents are restored on exit.
+	if _, _error := _tty.WriteString ("\x1b[?1049h\x1b[H\x1b[2J"); _error != nil {
+		return nil, errorw (0xcc764415, _error)
+	}
+	defer _tty.WriteString ("\x1b[?1049l")
+	
+	_selector := & terminalSelector {
+			options : _options,
+			prompt : _prompt,
+			input : _input,
+			preview : _preview,
+			previewCache : make (map[string][]string, 16),
+			query : []rune (_default),
+			marked : make (map[int]bool, 16),
+		}
+	terminalSelectFilter (_selector)
+	
+	_buffer := make ([]byte, 256)
+	for {
+		
+		if _error := terminalSelectRender (_selector, _descriptor, _tty); _error != nil {
+			return nil, _error
+		}
+		
+		_count, _error := _tty.Read (_buffer)
+		if _error != nil {
+			return nil, errorw (0x029890c5, _error)
+		}
+		
+		switch terminalSelectHandle (_selector, _buffer[:_count]) {
+			case "accept" :
+				return terminalSelectSelection (_selector), nil
+			case "cancel" :
+				return nil, nil
+		}
+	}
+}
+
+
+
+
+func terminalSelectHandle (_selector *terminalSelector, _input []byte) (string) {
+	
+	_visible := _selector.height - 2
+	if _visible < 1 {
+		_visible = 1
+	}
+	
+	if (len (_input) > 1) && (_input[0] == 0x1b) {
+		switch string (_input) {
+			case "\x1b[A", "\x1bOA" :
+				terminalSelectMove (_selector, -1)
+			case "\x1b[B", "\x1bOB" :
+				terminalSelectMove (_selector, 1)
+			case "\x1b[5~" :
+				terminalSelectMove (_selector, - _visible)
+			case "\x1b[6~" :
+				terminalSelectMove (_selector, _visible)
+			case "\x1b[H", "\x1bOH", "\x1b[1~" :
+				terminalSelectMove (_selector, - len (_selector.matches))
+			case "\x1b[F", "\x1bOF", "\x1b[4~" :
+				terminalSelectMove (_selector, len (_selector.matches))
+		}
+		return ""
+	}
+	
+	_queryChanged := false
+	for len (_input) > 0 {
+		_rune, _size := utf8.DecodeRune (_input)
+		_input = _input[_size:]
+		switch _rune {
+			case 0x1b, 0x03, 0x07 :
+				return "cancel"
+			case 0x04 :
+				if len (_selector.query) == 0 {
+					return "cancel"
+				}
+			case '\r', '\n' :
+				return "accept"
+			case '\t' :
+				if !_selector.input && (len (_selector.matches) > 0) {
+					_option := _selector.matches[_selector.cursor]
+					if _selector.marked[_option] {
+						delete (_selector.marked, _option)
+					} else {
+						_selector.marked[_option] = true
+					}
+					terminalSelectMove (_selector, 1)
+				}
+			case 0x10, 0x0b :
+				terminalSelectMove (_selector, -1)
+			case 0x0e :
+				terminalSelectMove (_selector, 1)
+			case 0x7f, 0x08 :
+				if len (_selector.query) > 0 {
+					_selector.query = _selector.query[: len (_selector.query) - 1]
+					_queryChanged = true
+				}
+			case 0x15 :
+				_selector.query = _selector.query[:0]
+				_queryChanged = true
+			case 0x17 :
+				_query := strings.TrimRightFunc (string (_selector.query), unicode.IsSpace)
+				if _index := strings.LastIndexFunc (_query, unicode.IsSpace); _index != -1 {
+					_query = _query[: _index + 1]
+				} else {
+					_query = ""
+				}
+				_selector.query = []rune (_query)
+				_queryChanged = true
+			default :
+				if (_rune != utf8.RuneError) && unicode.IsPrint (_rune) {
+					_selector.query = append (_selector.query, _rune)
+					_queryChanged = true
+				}
+		}
+	}
+	
+	if _queryChanged {
+		terminalSelectFilter (_selector)
+	}
+	
+	return ""
+}
+
+
+func terminalSelectMove (_selector *terminalSelector, _delta int) () {
+	_selector.cursor += _delta
+	if _selector.cursor >= len (_selector.matches) {
+		_selector.cursor = len (_selector.matches) - 1
+	}
+	if _selector.cursor < 0 {
+		_selector.cursor = 0
+	}
+}
+
+
+// NOTE:  Like `fzf --print-query`, in input mode the query is returned, regardless of the matching options.
+func terminalSelectSelection (_selector *terminalSelector) ([]string) {
+	if _selector.input {
+		return []string { string (_selector.query) }
+	}
+	if len (_selector.marked) > 0 {
+		_selection := make ([]string, 0, len (_selector.marked))
+		for _index, _option := range _selector.options {
+			if _selector.marked[_index] {
+				_selection = append (_selection, _option)
+			}
+		}
+		return _selection
+	}
+	if len (_selector.matches) > 0 {
+		return []string { _selector.options[_selector.matches[_selector.cursor]] }
+	}
+	return nil
+}
+
+
+
+
+// NOTE:  Each space separated term must match (case-insensitive) as a sub-sequence;  tighter and earlier matches are ranked first, otherwise the original order is kept.
+func terminalSelectFilter (_selector *terminalSelector) () {
+	
+	_terms := make ([][]rune, 0, 4)
+	for _, _term := range strings.Fields (strings.ToLower (string (_selector.query))) {
+		_terms = append (_terms, []rune (_term))
+	}
+	
+	_matches := make ([]int, 0, len (_selector.options))
+	_penalties := make (map[int]int, len (_selector.options))
+	for _index, _option := range _selector.options {
+		if len (_terms) == 0 {
+			_matches = append (_matches, _index)
+			continue
+		}
+		if _penalty, _matched := terminalSelectScore ([]rune (strings.ToLower (_option)), _terms); _matched {
+			_matches = append (_matches, _index)
+			_penalties[_index] = _penalty
+		}
+	}
+	
+	sort.SliceStable (_matches, func (_left int, _right int) (bool) {
+			return _penalties[_matches[_left]] < _penalties[_matches[_right]]
+		})
+	
+	_selector.matches = _matches
+	_selector.cursor = 0
+	_selector.offset = 0
+}
+
+
+func terminalSelectScore (_option []rune, _terms [][]rune) (int, bool) {
+	_penalty := 0
+	for _, _term := range _terms {
+		_best := 0
+		_found := false
+		for _start := range _option {
+			if _option[_start] != _term[0] {
+				continue
+			}
+			_termIndex := 1
+			_end := _start
+			for _position := _start + 1; (_position < len (_option)) && (_termIndex < len (_term)); _position += 1 {
+				if _option[_position] == _term[_termIndex] {
+					_termIndex += 1
+					_end = _position
+				}
+			}
+			if _termIndex < len (_term) {
+				// NOTE:  If it doesn't match starting here, it won't match starting later either.
+				break
+			}
+			_termPenalty := (_end - _start + 1 - len (_term)) * 4 + (_start / 4)
+			if (_start == 0) || !(unicode.IsLetter (_option[_start - 1]) || unicode.IsDigit (_option[_start - 1])) {
+				_termPenalty -= 2
+			}
+			if !_found || (_termPenalty < _best) {
+				_best = _termPenalty
+				_found = true
+			}
+		}
+		if !_found {
+			return 0, false
+		}
+		_penalty += _best
+	}
+	return _penalty, true
+}
+
+
+
+
+func terminalSelectRender (_selector *terminalSelector, _descriptor int, _tty *os.File) (*Error) {
+	
+	_selector.width = 80
+	_selector.height = 24
+	if _size, _error := unix.IoctlGetWinsize (_descriptor, unix.TIOCGWINSZ); (_error == nil) && (_size.Col > 0) && (_size.Row > 0) {
+		_selector.width = int (_size.Col)
+		_selector.height = int (_size.Row)
+	}
+	
+	_visible := _selector.height - 2
+	if _visible < 1 {
+		_visible = 1
+	}
+	if _selector.cursor < _selector.offset {
+		_selector.offset = _selector.cursor
+	}
+	if _selector.cursor >= (_selector.offset + _visible) {
+		_selector.offset = _selector.cursor - _visible + 1
+	}
+	
+	// NOTE:  The preview pane is shown only if there is enough room for both panes.
+	_listWidth := _selector.width
+	_previewLines := []string (nil)
+	if (_selector.preview != nil) && (_selector.width >= 60) && (len (_selector.matches) > 0) {
+		_listWidth = (_selector.width - 3) / 2
+		_option := _selector.options[_selector.matches[_selector.cursor]]
+		if _lines, _exists := _selector.previewCache[_option]; _exists {
+			_previewLines = _lines
+		} else {
+			_previewLines = strings.Split (_selector.preview (_option), "\n")
+			_selector.previewCache[_option] = _previewLines
+		}
+	}
+	_previewWidth := _selector.width - _listWidth - 3
+	
+	_buffer := bytes.NewBuffer (nil)
+	_buffer.WriteString ("\x1b[?25l\x1b[H")
+	
+	_promptPrefix := "> "
+	if _selector.prompt != "" {
+		_promptPrefix = _selector.prompt + " > "
+	}
+	_buffer.WriteString (terminalSelectTruncate (_promptPrefix + string (_selector.query), _selector.width))
+	_buffer.WriteString ("\x1b[K\r\n")
+	
+	_status := fmt.Sprintf ("  %d/%d", len (_selector.matches), len (_selector.options))
+	if len (_selector.marked) > 0 {
+		_status += fmt.Sprintf ("  (%d marked)", len (_selector.marked))
+	}
+	_buffer.WriteString (terminalSelectTruncate (_status, _selector.width))
+	_buffer.WriteString ("\x1b[K")
+	
+	for _row := 0; _row < _visible; _row += 1 {
+		_buffer.WriteString ("\r\n")
+		_index := _selector.offset + _row
+		_line := ""
+		if _index < len (_selector.matches) {
+			_option := _selector.matches[_index]
+			_marker := "  "
+			if _selector.marked[_option] {
+				_marker = " *"
+			}
+			_line = _marker + " " + _selector.options[_option]
+		}
+		_line = terminalSelectTruncate (_line, _listWidth)
+		if (_index == _selector.cursor) && (_index < len (_selector.matches)) {
+			_buffer.WriteString ("\x1b[7m")
+			_buffer.WriteString (_line)
+			_buffer.WriteString (strings.Repeat (" ", _listWidth - utf8.RuneCountInString (_line)))
+			_buffer.WriteString ("\x1b[0m")
+		} else {
+			_buffer.WriteString (_line)
+			_buffer.WriteString (strings.Repeat (" ", _listWidth - utf8.RuneCountInString (_line)))
+		}
+		if _listWidth < _selector.width {
+			_buffer.WriteString (" | ")
+			if _row < len (_previewLines) {
+				_buffer.WriteString (terminalSelectTruncate (_previewLines[_row], _previewWidth))
+			}
+		}
+		_buffer.WriteString ("\x1b[K")
+	}
+	
+	_cursorColumn := utf8.RuneCountInString (_promptPrefix) + len (_selector.query) + 1
+	if _cursorColumn > _selector.width {
+		_cursorColumn = _selector.width
+	}
+	fmt.Fprintf (_buffer, "\x1b[1;%dH\x1b[?25h", _cursorColumn)
+	
+	if _, _error := _tty.Write (_buffer.Bytes ()); _error != nil {
+		return errorw (0x58c80056, _error)
+	}
+	
+	return nil
+}
+
+
+// NOTE:  Tabs are expanded and other control characters are dropped, thus the width is the number of runes (wide characters are not accounted for).
+func terminalSelectTruncate (_text string, _width int) (string) {
+	_buffer := strings.Builder {}
+	_count := 0
+	for _, _rune := range _text {
+		if _count >= _width {
+			break
+		}
+		if _rune == '\t' {
+			for _spaces := 4 - (_count % 4); (_spaces > 0) && (_count < _width); _spaces -= 1 {
+				_buffer.WriteByte (' ')
+				_count += 1
+			}
+			continue
+		}
+		if !unicode.IsPrint (_rune) {
+			continue
+		}
+		_buffer.WriteRune (_rune)
+		_count += 1
+	}
+	return _buffer.String ()
+}
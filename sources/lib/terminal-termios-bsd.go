@@ -0,0 +1,14 @@
+//go:build darwin || freebsd || openbsd || netbsd || dragonfly
+// +build darwin freebsd openbsd netbsd dragonfly
+
+
+package zscratchpad
+
+
+import "golang.org/x/sys/unix"
+
+
+
+
+const terminalIoctlGetTermios = unix.TIOCGETA
+const terminalIoctlSetTermios = unix.TIOCSETA
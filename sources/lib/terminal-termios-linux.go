@@ -0,0 +1,14 @@
+//go:build linux
+// +build linux
+
+
+package zscratchpad
+
+
+import "golang.org/x/sys/unix"
+
+
+
+
+const terminalIoctlGetTermios = unix.TCGETS
+const terminalIoctlSetTermios = unix.TCSETS
@@ -3,13 +3,20 @@
 package zscratchpad
 
 
+import "fmt"
 import "os"
+import "strings"
 import "time"
 
 
 
 
 func WorkflowDocumentCreate (_identifierUnsafe string, _index *Index, _editor *Editor, _synchronous bool) (*Error) {
+	return WorkflowDocumentCreateWithTitle (_identifierUnsafe, "", _index, _editor, _synchronous)
+}
+
+
+func WorkflowDocumentCreateWithTitle (_identifierUnsafe string, _title string, _index *Index, _editor *Editor, _synchronous bool) (*Error) {
 	
 	_timestamp := time.Now ()
 	
@@ -97,8 +104,12 @@ func WorkflowDocumentCreate (_identifierUnsafe string, _index *Index, _editor *E
 		return errorw (0x538cfbae, nil)
 	}
 	
+	_source := ""
+	if _title = strings.Join (strings.Fields (_title), " "); _title != "" {
+		_source = "## " + _title + "\n\n"
+	}
 	
-	return EditorDocumentCreate (_editor, _library, _documentName, _synchronous)
+	return EditorDocumentCreateWithSource (_editor, _library, _documentName, _source, _synchronous)
 }
 
 
@@ -115,6 +126,17 @@ func WorkflowDocumentEdit (_identifierUnsafe string, _index *Index, _editor *Edi
 }
 
 
+func WorkflowDocumentEditAt (_identifierUnsafe string, _line uint, _index *Index, _editor *Editor, _synchronous bool) (*Error) {
+	
+	_document, _library, _error := WorkflowDocumentAndLibraryResolve (_identifierUnsafe, _index)
+	if _error != nil {
+		return _error
+	}
+	
+	return EditorDocumentEditAt (_editor, _library, _document, _line, _synchronous)
+}
+
+
 
 
 func WorkflowDocumentBrowse (_identifierUnsafe string, _index *Index, _browser *Browser, _synchronous bool) (*Error) {
@@ -141,6 +163,148 @@ func WorkflowLibraryBrowse (_identifierUnsafe string, _index *Index, _browser *B
 
 
 
+func WorkflowDocumentHeaderUpdate (_identifierUnsafe string, _key string, _values []string, _index *Index) (*Error) {
+	
+	switch strings.ToLower (stringTrimSpaces (_key)) {
+		case "identifier", "library" :
+			return errorf (0x4a2da467, "header key read-only `%s`", _key)
+	}
+	
+	return workflowDocumentRewrite (_identifierUnsafe, _index, func (_source string, _document *Document) (string, *Error) {
+			return DocumentHeaderUpdate (_source, _key, _values, _document.Format)
+		})
+}
+
+
+
+
+func WorkflowDocumentConvert (_identifierUnsafe string, _format string, _index *Index) (*Error) {
+	
+	return workflowDocumentRewrite (_identifierUnsafe, _index, func (_source string, _document *Document) (string, *Error) {
+			if _document.Format == _format {
+				return _source, nil
+			}
+			// NOTE:  The body is taken from the file, as the indexed one might be stale (or missing if encrypted).
+			_documentSource, _error := DocumentLoadFromBuffer (_source, _document.Format)
+			if _error != nil {
+				return "", _error
+			}
+			if _documentSource == nil {
+				return "", errorw (0x3499dc91, nil)
+			}
+			_documentSource.Format = _document.Format
+			_bodyLines, _error := DocumentConvert (_documentSource, _format)
+			if _error != nil {
+				return "", _error
+			}
+			// NOTE:  The header is updated first, so that the new body can't be mistaken for a header.
+			_source, _error = DocumentHeaderUpdate (_source, "format", []string { _format }, _document.Format)
+			if _error != nil {
+				return "", _error
+			}
+			return DocumentBodyReplace (_source, _bodyLines, _document.Format)
+		})
+}
+
+
+
+
+// NOTE:  Rewrites the document file (decrypting and encrypting it if needed), and then reloads the document.
+func workflowDocumentRewrite (_identifierUnsafe string, _index *Index, _rewrite func (string, *Document) (string, *Error)) (*Error) {
+	
+	_document, _library, _error := WorkflowDocumentAndLibraryResolve (_identifierUnsafe, _index)
+	if _error != nil {
+		return _error
+	}
+	
+	if (_library != nil) && !_library.EditEnabled {
+		return errorw (0x5f4ae636, nil)
+	}
+	if !_document.EditEnabled {
+		return errorw (0xe7c420b2, nil)
+	}
+	
+	_path := _document.Path
+	if _path == "" {
+		return errorw (0x476f98e3, nil)
+	}
+	
+	_mode := os.FileMode (0o640)
+	if _stat, _error := os.Stat (_path); _error == nil {
+		_mode = _stat.Mode () .Perm ()
+	} else {
+		return errorw (0x5417414e, _error)
+	}
+	
+	_dataOld := []byte (nil)
+	if _data, _error := os.ReadFile (_path); _error == nil {
+		_dataOld = _data
+	} else {
+		return errorw (0x4c6ef1be, _error)
+	}
+	
+	_sourceOld := ""
+	if _document.Encrypted {
+		if _data, _error := EncryptionDecrypt (_dataOld, _library); _error == nil {
+			_sourceOld = string (_data)
+		} else {
+			return _error
+		}
+	} else {
+		_sourceOld = string (_dataOld)
+	}
+	
+	_sourceNew, _error := _rewrite (_sourceOld, _document)
+	if _error != nil {
+		return _error
+	}
+	if _sourceNew == _sourceOld {
+		return nil
+	}
+	
+	_dataNew := []byte (_sourceNew)
+	if _document.Encrypted {
+		if _data, _error := EncryptionEncrypt (_dataNew, EncryptionArmored (_dataOld), _library); _error == nil {
+			_dataNew = _data
+		} else {
+			return _error
+		}
+	}
+	
+	_pathTemp := fmt.Sprintf ("%s.%d.tmp", _path, time.Now () .UnixMilli ())
+	
+	_file := (*os.File) (nil)
+	if _file_0, _error := os.OpenFile (_pathTemp, os.O_WRONLY | os.O_CREATE | os.O_EXCL, _mode); _error == nil {
+		_file = _file_0
+	} else {
+		return errorw (0x821dd073, _error)
+	}
+	defer _file.Close ()
+	
+	if _, _error := _file.Write (_dataNew); _error != nil {
+		os.Remove (_pathTemp)
+		return errorw (0x1f6c55b6, _error)
+	}
+	if _error := _file.Close (); _error != nil {
+		os.Remove (_pathTemp)
+		return errorw (0x130d158d, _error)
+	}
+	
+	if _error := os.Rename (_pathTemp, _path); _error != nil {
+		os.Remove (_pathTemp)
+		return errorw (0x65f4cc27, _error)
+	}
+	
+	if _, _error := WorkflowDocumentReload (_document, _index); _error != nil {
+		return _error
+	}
+	
+	return nil
+}
+
+
+
+
 func WorkflowLibraryResolve (_identifierUnsafe string, _index *Index) (*Library, *Error) {
 	if _identifierUnsafe == "" {
 		return nil, errorw (0xbef72625, nil)
@@ -205,9 +369,23 @@ func WorkflowDocumentRefresh (_document *Document, _index *Index) (*Document, *E
 	}
 	
 	if _stat, _error := os.Stat (_path); _error == nil {
-		if _stat.ModTime () == _document.Timestamp {
+		if _document.Encrypted && !_document.BodyEmpty && (len (_document.BodyLines) == 0) {
+			// NOTE:  The encrypted bodies are (usually) not stored in the database, thus they must be decrypted again.
+			_dirtyEnabled := _index.dirtyEnabled
+			_index.dirtyEnabled = false
+			defer func () () {
+					_index.dirtyEnabled = _dirtyEnabled
+				} ()
+		} else if _stat.ModTime () == _document.Timestamp {
 			return _document, nil
 		}
+	} else if os.IsNotExist (_error) {
+		// NOTE:  The file was (re)moved outside the tool, thus the libraries must be walked again.
+		if _error := IndexDocumentExclude (_index, _document); _error != nil {
+			return nil, _error
+		}
+		IndexRefreshInvalidate (_index)
+		return nil, nil
 	} else {
 		return nil, errorw (0x4eee8052, _error)
 	}
@@ -223,8 +401,13 @@ func WorkflowDocumentReload (_documentOld *Document, _index *Index) (*Document,
 		return nil, errorw (0xffd44d56, nil)
 	}
 	
+	_library, _error := IndexLibraryResolve (_index, _documentOld.Library)
+	if _error != nil {
+		return nil, _error
+	}
+	
 	_documentNew := (*Document) (nil)
-	if _document_0, _error := DocumentLoadFromPath (_path); _error == nil {
+	if _document_0, _error := DocumentLoadFromPath (_path, _library); _error == nil {
 		_documentNew = _document_0
 	} else {
 		return nil, _error
@@ -241,11 +424,6 @@ func WorkflowDocumentReload (_documentOld *Document, _index *Index) (*Document,
 		_documentNew.Format = _documentOld.Format
 	}
 	
-	_library, _error := IndexLibraryResolve (_index, _documentNew.Library)
-	if _error != nil {
-		return nil, _error
-	}
-	
 	if _error := DocumentInitializeIdentifier (_documentNew, _library); _error != nil {
 		return nil, _error
 	}
@@ -267,6 +445,10 @@ func WorkflowDocumentReload (_documentOld *Document, _index *Index) (*Document,
 		return nil, errorw (0xd67618cf, nil)
 	}
 	
+	if _error := IndexDocumentLinksUpdate (_index, _documentNew); _error != nil {
+		return nil, _error
+	}
+	
 	return _documentNew, nil
 }
 